@@ -32,6 +32,7 @@ type Chain struct {
 	lastHeight    uint64
 	confirmed     uint64
 	poolDeleteMsg func(message types.IMessage)
+	poolRecover   func(messages []types.IMessage)
 }
 
 func NewChain(status status.IStatus, dPos dpos.IDPos) (*Chain, error) {
@@ -96,6 +97,12 @@ func (c *Chain) NextBlock(msgs []types.IMessage, blockTime uint64) (types.IBlock
 	defer c.mutex.RUnlock()
 
 	height := c.lastHeight + 1
+	if height >= config.Param.ValidUntilHeight {
+		msgs = dropExpired(msgs, height, blockTime)
+	}
+	if height < config.Param.RepeatSenderHeight {
+		msgs = firstOfSenders(msgs)
+	}
 
 	var coinBaseAddr = config.Param.CoinBaseAddressList.CurrentAddress(height)
 	var feeAddr = config.Param.IPrivate.Address()
@@ -299,6 +306,38 @@ func (c *Chain) Insert(block types.IBlock) error {
 	return nil
 }
 
+// Remove the expired messages, the following messages of the same
+// address are removed too because their nonce values are no longer consecutive
+func dropExpired(msgs []types.IMessage, height, blockTime uint64) []types.IMessage {
+	dropped := make(map[string]bool)
+	valid := make([]types.IMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		from := msg.From().String()
+		if dropped[from] || chaintypes.IsExpired(msg.ValidUntil(), height, blockTime) {
+			dropped[from] = true
+			continue
+		}
+		valid = append(valid, msg)
+	}
+	return valid
+}
+
+// Keep the first message of each address, before the RepeatSenderHeight
+// a block can only contain one message of an address
+func firstOfSenders(msgs []types.IMessage) []types.IMessage {
+	senders := make(map[string]bool)
+	first := make([]types.IMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		from := msg.From().String()
+		if senders[from] {
+			continue
+		}
+		senders[from] = true
+		first = append(first, msg)
+	}
+	return first
+}
+
 func (c *Chain) saveBlock(block types.IBlock) {
 	bk := block.(*chaintypes.Block)
 	rlpBlock := bk.ToRlpBlock().(*chaintypes.RlpBlock)
@@ -392,7 +431,7 @@ func (c *Chain) checkBlock(block types.IBlock) error {
 	if !block.CheckMsgRoot() {
 		log.Warn("the message root hash verification failed", "module", module,
 			"height", block.GetHeight(), "msgroot", block.GetMsgRoot().String())
-		return errors.New("the message root hash verification failed")
+		return invalidBlock(errors.New("the message root hash verification failed"))
 	}
 	if !block.GetActRoot().IsEqual(c.actRoot) {
 		log.Warn("the account status root hash verification failed", "module", module,
@@ -420,33 +459,58 @@ func (c *Chain) checkBlock(block types.IBlock) error {
 	if err := c.dPos.CheckSeal(block.BlockHeader(), preHeader, c); err != nil {
 		return err
 	}
-	if err := c.checkMsgs(block.BlockBody().MsgList(), block.GetHeight()); err != nil {
-		return err
+	// The status roots are equal, so the messages are checked against the same status
+	if err := c.checkMsgs(block.BlockBody().MsgList(), block.GetHeight(), block.GetTime()); err != nil {
+		return invalidBlock(err)
 	}
 	return nil
 }
 
-func (c *Chain) checkMsgs(msgs []types.IMessage, height uint64) error {
+// The block is invalid no matter which chain it is on, the peer sending it is penalized
+func invalidBlock(err error) error {
+	return fmt.Errorf("%w, %s", servicesync.Err_InvalidBlock, err.Error())
+}
+
+func (c *Chain) checkMsgs(msgs []types.IMessage, height, blockTime uint64) error {
 	address := make(map[string]int)
 	for i, msg := range msgs {
+		from := msg.From().String()
+		lastIndex, repeat := address[from]
 		if msg.IsCoinBase() {
+			if repeat {
+				return errors.New("only one coinbase message is allowed in a block")
+			}
 			if err := c.checkCoinBase(msg, chaintypes.CalculateFee(msgs), height); err != nil {
 				return err
 			}
-		} else {
-			if err := c.checkMsg(msg); err != nil {
+		} else if !repeat {
+			if err := c.checkMsg(msg, true, height, blockTime); err != nil {
 				return err
 			}
-		}
-		from := msg.From().String()
-		if lastIndex, ok := address[from]; !ok {
-			address[from] = i
-		} else {
+		} else if height < config.Param.RepeatSenderHeight {
 			log.Warn("Repeat address block", "module", module,
 				"preMsg", msgs[lastIndex],
 				"curMsg", msg)
 			return errors.New("one address in a block can only send one transaction")
+		} else {
+			// Messages of the same address must have consecutive nonce values.
+			// They are checked with strict set to false against the state before
+			// the block, so the nonce only has to be greater than the account
+			// nonce and each balance check only sees its own spend. The combined
+			// spend is verified when the block is applied, changeMain and the
+			// other account changes check the balance again after each message
+			// and the whole block is rejected if one of them fails.
+			if msgs[lastIndex].Nonce()+1 != msg.Nonce() {
+				log.Warn("Repeat address block", "module", module,
+					"preMsg", msgs[lastIndex],
+					"curMsg", msg)
+				return errors.New("messages of one address in a block must have consecutive nonce values")
+			}
+			if err := c.checkMsg(msg, false, height, blockTime); err != nil {
+				return err
+			}
 		}
+		address[from] = i
 	}
 	return nil
 }
@@ -473,17 +537,29 @@ func (c *Chain) checkCoinBase(coinBase types.IMessage, fee, height uint64) error
 	return nil
 }
 
-func (c *Chain) checkMsg(msg types.IMessage) error {
+func (c *Chain) checkMsg(msg types.IMessage, strict bool, height, blockTime uint64) error {
 	msg, ok := msg.(*chaintypes.Message)
 	if !ok {
 		return errors.New("wrong message type")
 	}
 
+	if err := chaintypes.CheckExpired(msg, height, blockTime); err != nil {
+		return err
+	}
+
+	if err := chaintypes.CheckActivation(msg, height); err != nil {
+		return err
+	}
+
+	if err := chaintypes.CheckCancel(msg, height); err != nil {
+		return err
+	}
+
 	if err := msg.Check(); err != nil {
 		return err
 	}
 
-	if err := c.status.CheckMsg(msg, true); err != nil {
+	if err := c.status.CheckMsg(msg, strict); err != nil {
 		return err
 	}
 	return nil
@@ -506,6 +582,34 @@ func (c *Chain) Roll() error {
 }
 
 func (c *Chain) RollbackTo(height uint64) error {
+	msgs := c.discardedMsgs(height)
+	if err := c.rollbackTo(height); err != nil {
+		return err
+	}
+	if c.poolRecover != nil {
+		c.poolRecover(msgs)
+	}
+	return nil
+}
+
+// The messages of the blocks above the height, except coinbase
+func (c *Chain) discardedMsgs(height uint64) []types.IMessage {
+	msgs := make([]types.IMessage, 0)
+	for h := height + 1; h <= c.LastHeight(); h++ {
+		block, err := c.GetBlockHeight(h)
+		if err != nil {
+			break
+		}
+		for _, msg := range block.BlockBody().MsgList() {
+			if !msg.IsCoinBase() {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	return msgs
+}
+
+func (c *Chain) rollbackTo(height uint64) error {
 	confirmedHeight := c.confirmed
 	if height > confirmedHeight && height != 0 {
 		err := fmt.Sprintf("the height of the roolback must be less than or equal to %d and greater than %d", confirmedHeight, 0)
@@ -582,6 +686,10 @@ func (c *Chain) RegisterMsgPoolDeleteFunc(fun func(message types.IMessage)) {
 	c.poolDeleteMsg = fun
 }
 
+func (c *Chain) RegisterMsgPoolRecoverFunc(fun func(messages []types.IMessage)) {
+	c.poolRecover = fun
+}
+
 func (c *Chain) getAllWorks(cycle uint64) uint64 {
 	var allWorks uint64
 	rewords := c.status.CycleReword(cycle)
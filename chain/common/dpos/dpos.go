@@ -142,8 +142,17 @@ func (d *DPos) CheckCreator(header types.IHeader, parent types.IHeader, chain bl
 	return nil
 }
 
+// The p2p ids of the supers of the current cycle
 func (d *DPos) SuperIds() []string {
-	return nil
+	supers, err := d.cycle.DPosStatus.CycleSupers(uint64(utils.NowUnix()) / param.CycleInterval)
+	if err != nil {
+		return nil
+	}
+	ids := make([]string, 0, supers.Len())
+	for _, super := range supers.List() {
+		ids = append(ids, super.GetPeerId())
+	}
+	return ids
 }
 
 func (d *DPos) Confirmed() uint64 {
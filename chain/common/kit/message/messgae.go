@@ -2,6 +2,7 @@ package message
 
 import (
 	"github.com/aiot-network/aiotchain/chain/types"
+	"github.com/aiot-network/aiotchain/common/param"
 	"github.com/aiot-network/aiotchain/tools/arry"
 	"github.com/aiot-network/aiotchain/tools/crypto/ecc/secp256k1"
 	"time"
@@ -37,6 +38,16 @@ func NewTransaction(from, token string, to []map[string]uint64, fee, nonce, t ui
 	return tx
 }
 
+// A zero-value transfer to the sender itself, it replaces the pending
+// message of the same nonce if its fee is high enough
+func NewCancelPending(net, from string, fee, nonce, t uint64) *types.Message {
+	mainToken := param.MainNetParam.MainToken
+	if net == param.TestNet {
+		mainToken = param.TestNetParam.MainToken
+	}
+	return NewTransaction(from, mainToken.String(), []map[string]uint64{{from: 0}}, fee, nonce, t)
+}
+
 func NewCandidate(from string, peerStr string, fee, nonce, t uint64) *types.Message {
 	if t == 0 {
 		t = uint64(time.Now().Unix())
@@ -126,7 +137,7 @@ func NewToken(from, to, tokenAddr string, amount, fee, nonce, t uint64, name, sh
 	return token
 }
 
-func NewTokenV2(from, to, tokenAddr string, amount, fee, nonce, t uint64, pledgeRate types.PledgeRate, name, shorthand string) *types.Message {
+func NewTokenV2(from, to, tokenAddr string, amount, fee, nonce, t uint64, pledgeRate types.PledgeRate, name, shorthand string, decimals uint8, uri, description string) *types.Message {
 	if t == 0 {
 		t = uint64(time.Now().Unix())
 	}
@@ -147,6 +158,7 @@ func NewTokenV2(from, to, tokenAddr string, amount, fee, nonce, t uint64, pledge
 			Shorthand:    shorthand,
 			Amount:       amount,
 			PledgeRate:   pledgeRate,
+			Meta:         &types.TokenMeta{Decimals: decimals, URI: uri, Description: description},
 		},
 	}
 	token.SetHash()
@@ -177,6 +189,61 @@ func NewRedemption(from, tokenAddr string, amount, fee, nonce, t uint64, pledgeR
 	return redemption
 }
 
+func NewTokenAdmin(from, tokenAddr string, action types.TokenAdminAction, newOwner string, maxSupply uint64, uri, description string, fee, nonce, t uint64) *types.Message {
+	if t == 0 {
+		t = uint64(time.Now().Unix())
+	}
+	admin := &types.Message{
+		Header: &types.MsgHeader{
+			Type:      types.TokenAdmin,
+			Hash:      arry.Hash{},
+			From:      arry.StringToAddress(from),
+			Nonce:     nonce,
+			Fee:       fee,
+			Time:      t,
+			Signature: &types.Signature{},
+		},
+		Body: &types.TokenAdminBody{
+			TokenAddress: arry.StringToAddress(tokenAddr),
+			Action:       action,
+			NewOwner:     arry.StringToAddress(newOwner),
+			MaxSupply:    maxSupply,
+			URI:          uri,
+			Description:  description,
+		},
+	}
+	admin.SetHash()
+	return admin
+}
+
+func NewTokenPolicy(from, tokenAddr string, action types.TokenPolicyAction, addresses []string, fee, nonce, t uint64) *types.Message {
+	if t == 0 {
+		t = uint64(time.Now().Unix())
+	}
+	addrs := []arry.Address{}
+	for _, addr := range addresses {
+		addrs = append(addrs, arry.StringToAddress(addr))
+	}
+	policy := &types.Message{
+		Header: &types.MsgHeader{
+			Type:      types.TokenPolicy,
+			Hash:      arry.Hash{},
+			From:      arry.StringToAddress(from),
+			Nonce:     nonce,
+			Fee:       fee,
+			Time:      t,
+			Signature: &types.Signature{},
+		},
+		Body: &types.TokenPolicyBody{
+			TokenAddress: arry.StringToAddress(tokenAddr),
+			Action:       action,
+			Addresses:    addrs,
+		},
+	}
+	policy.SetHash()
+	return policy
+}
+
 func NewWork(from string, nonce uint64, start uint64, end uint64, t uint64, works map[string]uint64) *types.Message {
 	if t == 0 {
 		t = uint64(time.Now().Unix())
@@ -220,3 +287,31 @@ func Sign(keyStr string, hash string) (*types.Signature, error) {
 	}
 	return types.Sign(key, h)
 }
+
+// Wrap the bodies of the messages into a batch, the header of each message is ignored
+func NewBatch(from string, msgs []*types.Message, fee, nonce, t uint64) *types.Message {
+	if t == 0 {
+		t = uint64(time.Now().Unix())
+	}
+	items := make([]*types.BatchItem, len(msgs))
+	for i, msg := range msgs {
+		items[i] = &types.BatchItem{
+			Type: msg.Header.Type,
+			Body: msg.Body,
+		}
+	}
+	batch := &types.Message{
+		Header: &types.MsgHeader{
+			Type:      types.Batch,
+			Hash:      arry.Hash{},
+			From:      arry.StringToAddress(from),
+			Nonce:     nonce,
+			Fee:       fee,
+			Time:      t,
+			Signature: &types.Signature{},
+		},
+		Body: &types.BatchBody{Items: items},
+	}
+	batch.SetHash()
+	return batch
+}
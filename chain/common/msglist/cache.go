@@ -1,7 +1,7 @@
 package msglist
 
 import (
-	"fmt"
+	chaintypes "github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/types"
 	"strconv"
 )
@@ -9,6 +9,7 @@ import (
 type Cache struct {
 	msgs     map[string]types.IMessage
 	nonceTxs map[string]string
+	addrTxs  map[string]int
 	db       ITxListDB
 }
 
@@ -16,24 +17,28 @@ func NewCache(db ITxListDB) *Cache {
 	return &Cache{
 		msgs:     make(map[string]types.IMessage),
 		nonceTxs: make(map[string]string),
+		addrTxs:  make(map[string]int),
 		db:       db,
 	}
 }
 
 func (c *Cache) Put(msg types.IMessage) error {
 	if c.Exist(msg.Hash().String()) {
-		return fmt.Errorf("transation hash %s exsit", msg.Hash())
+		return chaintypes.NewMsgError(chaintypes.ErrMsgExists, chaintypes.ErrDetails{"hash": msg.Hash().String()},
+			"transation hash %s exsit", msg.Hash())
 	}
-	nonceKey := nonceKey(msg)
+	nonceKey := nonceKey(msg.From().String(), msg.Nonce())
 	if oldTxHash := c.getHash(nonceKey); oldTxHash != "" {
 		oldTx := c.msgs[oldTxHash]
 		if oldTx.Fee() > msg.Fee() {
-			return fmt.Errorf("transation nonce %d exist, the fees must biger than before %d", msg.Nonce(), oldTx.Fee())
+			return chaintypes.NewMsgError(chaintypes.ErrFeeTooLow, chaintypes.ErrDetails{"fee": msg.Fee(), "minfee": oldTx.Fee()},
+				"transation nonce %d exist, the fees must biger than before %d", msg.Nonce(), oldTx.Fee())
 		}
 		c.Remove(oldTx)
 	}
 	c.msgs[msg.Hash().String()] = msg
 	c.nonceTxs[nonceKey] = msg.Hash().String()
+	c.addrTxs[msg.From().String()]++
 	c.db.Save(msg)
 	return nil
 }
@@ -43,9 +48,22 @@ func (c *Cache) Get(msgHash string) (types.IMessage, bool) {
 	return msg, ok
 }
 
+func (c *Cache) GetByNonce(addr string, nonce uint64) (types.IMessage, bool) {
+	msg, ok := c.msgs[c.getHash(nonceKey(addr, nonce))]
+	return msg, ok
+}
+
 func (c *Cache) Remove(msg types.IMessage) {
+	if c.Exist(msg.Hash().String()) {
+		from := msg.From().String()
+		if c.addrTxs[from] <= 1 {
+			delete(c.addrTxs, from)
+		} else {
+			c.addrTxs[from]--
+		}
+	}
 	delete(c.msgs, msg.Hash().String())
-	delete(c.nonceTxs, nonceKey(msg))
+	delete(c.nonceTxs, nonceKey(msg.From().String(), msg.Nonce()))
 	c.db.Delete(msg)
 }
 
@@ -54,10 +72,28 @@ func (c *Cache) Exist(msgHash string) bool {
 	return ok
 }
 
+// The number of cached messages of the address
+func (c *Cache) CountByAddress(addr string) int {
+	return c.addrTxs[addr]
+}
+
 func (c *Cache) Len() int {
 	return len(c.msgs)
 }
 
+func (c *Cache) GetByAddress(addr string) []types.IMessage {
+	msgs := make([]types.IMessage, 0, c.addrTxs[addr])
+	if c.addrTxs[addr] == 0 {
+		return msgs
+	}
+	for _, msg := range c.msgs {
+		if msg.From().String() == addr {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
 func (c *Cache) All() []types.IMessage {
 	var all = make([]types.IMessage, 0)
 	for _, msg := range c.msgs {
@@ -70,6 +106,6 @@ func (c *Cache) getHash(nonceKey string) string {
 	return c.nonceTxs[nonceKey]
 }
 
-func nonceKey(msg types.IMessage) string {
-	return msg.From().String() + "_" + strconv.FormatUint(msg.Nonce(), 10)
+func nonceKey(addr string, nonce uint64) string {
+	return addr + "_" + strconv.FormatUint(nonce, 10)
 }
@@ -0,0 +1,33 @@
+package msglist
+
+import "testing"
+
+func TestCache_CountByAddress(t *testing.T) {
+	cache := NewCache(&testDB{})
+	first := newTestMsg("A", 2, 100000, 1)
+	if err := cache.Put(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put(newTestMsg("A", 3, 100000, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if count := cache.CountByAddress("A"); count != 2 {
+		t.Fatalf("address has %d cached messages, expect 2", count)
+	}
+
+	// Replacing the message of the same nonce does not change the count
+	replace := newTestMsg("A", 2, 200000, 1)
+	replace.Header.Hash[0]++
+	if err := cache.Put(replace); err != nil {
+		t.Fatal(err)
+	}
+	if count := cache.CountByAddress("A"); count != 2 {
+		t.Fatalf("address has %d cached messages after replacing, expect 2", count)
+	}
+
+	cache.Remove(replace)
+	cache.Remove(replace)
+	if count := cache.CountByAddress("A"); count != 1 {
+		t.Fatalf("address has %d cached messages after removing, expect 1", count)
+	}
+}
@@ -1,11 +1,14 @@
 package msglist
 
 import (
-	"fmt"
 	"github.com/aiot-network/aiotchain/chain/db/msglist"
+	chaintypes "github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/common/config"
 	"github.com/aiot-network/aiotchain/common/validator"
+	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/tools/utils"
 	"github.com/aiot-network/aiotchain/types"
+	"sort"
 	"sync"
 )
 
@@ -19,32 +22,67 @@ type MsgManagement struct {
 	actStatus types.IActStatus
 	mutex     sync.RWMutex
 	msgDB     ITxListDB
+	// The height of the last block, used to check the ValidUntil of messages
+	lastHeight func() uint64
+	// The maximum number of messages in the pool
+	capacity int
 }
 
-func NewMsgManagement(validator validator.IValidator, actStatus types.IActStatus) (*MsgManagement, error) {
+func NewMsgManagement(validator validator.IValidator, actStatus types.IActStatus, lastHeight func() uint64) (*MsgManagement, error) {
 	msgDB, err := msglist.Open(config.Param.Data + "/" + msgList_db)
 	if err != nil {
 		return nil, err
 	}
 	return &MsgManagement{
-		cache:     NewCache(msgDB),
-		ready:     NewSorted(msgDB),
-		validator: validator,
-		actStatus: actStatus,
-		msgDB:     msgDB,
+		cache:      NewCache(msgDB),
+		ready:      NewSorted(msgDB, config.Param.PoolParam),
+		validator:  validator,
+		actStatus:  actStatus,
+		msgDB:      msgDB,
+		lastHeight: lastHeight,
+		capacity:   maxPoolTx,
 	}, nil
 }
 
+// Read the persisted messages, they are verified again against the current status
 func (t *MsgManagement) Read() error {
-	msgs := t.msgDB.Read()
-	if msgs != nil {
-		for _, msg := range msgs {
-			if err := t.Put(msg); err != nil {
-				t.msgDB.Delete(msg)
-			}
+	t.recover(t.msgDB.Read())
+	return nil
+}
+
+// Verify all messages of the pool again after the status is rolled back,
+// the messages of the discarded blocks are put back into the pool
+func (t *MsgManagement) Recover(msgs []types.IMessage) {
+	ready, cache := t.GetAll()
+	msgs = append(msgs, ready...)
+	msgs = append(msgs, cache...)
+
+	t.mutex.Lock()
+	t.ready = NewSorted(t.msgDB, config.Param.PoolParam)
+	t.cache = NewCache(t.msgDB)
+	t.mutex.Unlock()
+
+	t.recover(msgs)
+}
+
+// Put the messages in the order of nonce, expired and invalid messages are deleted from the db
+func (t *MsgManagement) recover(msgs []types.IMessage) {
+	sort.Slice(msgs, func(i, j int) bool {
+		if !msgs[i].From().IsEqual(msgs[j].From()) {
+			return msgs[i].From().String() < msgs[j].From().String()
+		}
+		return msgs[i].Nonce() < msgs[j].Nonce()
+	})
+	threshold := utils.NowUnix() - config.Param.MsgExpiredTime
+	for _, msg := range msgs {
+		if msg.Time() <= uint64(threshold) {
+			t.msgDB.Delete(msg)
+			continue
+		}
+		if err := t.Put(msg); err != nil {
+			t.msgDB.Delete(msg)
 		}
 	}
-	return nil
 }
 
 func (t *MsgManagement) Close() error {
@@ -58,16 +96,34 @@ func (t *MsgManagement) Count() int {
 	return t.cache.Len() + t.ready.Len()
 }
 
+func (t *MsgManagement) Capacity() int {
+	return t.capacity
+}
+
 func (t *MsgManagement) Put(msg types.IMessage) error {
 	if t.Exist(msg) {
-		return fmt.Errorf("the message %s already exists", msg.Hash().String())
+		return chaintypes.NewMsgError(chaintypes.ErrMsgExists, chaintypes.ErrDetails{"hash": msg.Hash().String()},
+			"the message %s already exists", msg.Hash().String())
 	}
 	if err := t.validator.CheckMsg(msg, false); err != nil {
 		return err
 	}
+	if err := chaintypes.CheckExpired(msg, t.lastHeight()+1, uint64(utils.NowUnix())); err != nil {
+		return err
+	}
+	if err := chaintypes.CheckActivation(msg, t.lastHeight()+1); err != nil {
+		return err
+	}
+	if err := chaintypes.CheckCancel(msg, t.lastHeight()+1); err != nil {
+		return err
+	}
 
-	if t.cache.Len() >= maxPoolTx {
+	if t.Count() >= t.capacity {
 		t.DeleteEnd(msg)
+		if t.Count() >= t.capacity {
+			return chaintypes.NewMsgError(chaintypes.ErrPoolFull, chaintypes.ErrDetails{"capacity": t.capacity},
+				"the message pool is full, increase the fee")
+		}
 	}
 	return t.put(msg)
 }
@@ -78,29 +134,110 @@ func (t *MsgManagement) put(msg types.IMessage) error {
 
 	from := msg.From().String()
 	nonce := t.actStatus.Nonce(msg.From())
-	if nonce == msg.Nonce()-1 {
-		oldTx := t.ready.GetByAddress(from)
-		if oldTx != nil {
-			if oldTx.Nonce() == msg.Nonce() && oldTx.Fee() < msg.Fee() {
-				t.ready.Remove(oldTx)
-			} else if oldTx.Nonce() < msg.Nonce() {
-				t.ready.Remove(oldTx)
-			} else if oldTx.Nonce() == msg.Nonce() {
-				return fmt.Errorf("the same nonce %d message already exists, so if you want to replace the nonce message, add a fee", msg.Nonce())
-			} else {
-				return fmt.Errorf("the nonce value %d is repeated, increase the nonce value", msg.Nonce())
-			}
+	if nonce >= msg.Nonce() {
+		return chaintypes.NewMsgError(chaintypes.ErrNonceTooLow, chaintypes.ErrDetails{"nonce": msg.Nonce(), "account_nonce": nonce},
+			"the nonce value %d is repeated, increase the nonce value", msg.Nonce())
+	}
+	readyTx := t.ready.GetByNonce(from, msg.Nonce())
+	oldTx, cached := t.cache.GetByNonce(from, msg.Nonce())
+	if readyTx != nil {
+		oldTx = readyTx
+	}
+	if readyTx != nil || cached {
+		if minFee := chaintypes.ReplaceFee(oldTx.Fee()); msg.Fee() < minFee {
+			return chaintypes.NewMsgError(chaintypes.ErrFeeTooLow,
+				chaintypes.ErrDetails{"fee": msg.Fee(), "minfee": minFee, "bump": minFee - oldTx.Fee()},
+				"the message of nonce %d already exists, the fee must be at least %d to replace it", msg.Nonce(), minFee)
+		}
+	} else if pending := t.pending(from); uint64(pending) >= config.Param.MaxAddressMsg {
+		return chaintypes.NewMsgError(chaintypes.ErrAddressLimit,
+			chaintypes.ErrDetails{"pending": pending, "limit": config.Param.MaxAddressMsg},
+			"the address already has %d pending messages, wait for them to be packaged", pending)
+	}
+	// The replaced message is removed from the pool and the db
+	// before the new message is put
+	if readyTx != nil {
+		if err := t.checkBalance(msg, readyTx); err != nil {
+			return err
 		}
+		t.ready.Remove(readyTx)
 		t.ready.Put(msg)
-	} else if nonce >= msg.Nonce() {
-		return fmt.Errorf("the nonce value %d is repeated, increase the nonce value", msg.Nonce())
+	} else if t.nextNonce(from, nonce) == msg.Nonce() {
+		if err := t.checkBalance(msg, nil); err != nil {
+			return err
+		}
+		if cached {
+			t.cache.Remove(oldTx)
+		}
+		t.ready.Put(msg)
+		t.promote(from, nonce)
 	} else {
-		t.cache.Put(msg)
+		if cached {
+			t.cache.Remove(oldTx)
+		}
+		if err := t.cache.Put(msg); err != nil {
+			return err
+		}
 	}
 	t.msgDB.Save(msg)
 	return nil
 }
 
+// The number of messages of the address in the pool
+func (t *MsgManagement) pending(from string) int {
+	return len(t.ready.GetByAddress(from)) + t.cache.CountByAddress(from)
+}
+
+// The nonce value of the next message of the address that can be packaged
+func (t *MsgManagement) nextNonce(from string, nonce uint64) uint64 {
+	if last, ok := t.ready.LastNonce(from); ok && last > nonce {
+		return last + 1
+	}
+	return nonce + 1
+}
+
+// Move the cached messages with consecutive nonce values to the ready list
+func (t *MsgManagement) promote(from string, nonce uint64) {
+	for {
+		msg, ok := t.cache.GetByNonce(from, t.nextNonce(from, nonce))
+		if !ok {
+			return
+		}
+		if err := t.checkBalance(msg, nil); err != nil {
+			return
+		}
+		t.cache.Remove(msg)
+		t.ready.Put(msg)
+	}
+}
+
+// The messages of one address in the ready list may be packaged
+// into the same block, so their total amount cannot exceed the balance.
+// The message replaced by the new message is not counted.
+func (t *MsgManagement) checkBalance(msg, replaced types.IMessage) error {
+	amounts := make(map[arry.Address]uint64)
+	add := func(msg types.IMessage) {
+		amounts[config.Param.MainToken] += msg.Fee()
+		amounts[msg.MsgBody().MsgToken()] += msg.MsgBody().MsgAmount()
+	}
+	for _, readyMsg := range t.ready.GetByAddress(msg.From().String()) {
+		if replaced == nil || !readyMsg.Hash().IsEqual(replaced.Hash()) {
+			add(readyMsg)
+		}
+	}
+	add(msg)
+
+	account := t.actStatus.Account(msg.From())
+	for token, amount := range amounts {
+		if balance := account.GetBalance(token); balance < amount {
+			return chaintypes.NewMsgError(chaintypes.ErrInsufficientBalance,
+				chaintypes.ErrDetails{"token": token.String(), "balance": balance, "required": amount},
+				"%s balance %d is not enough to pay the pending messages %d", token.String(), balance, amount)
+		}
+	}
+	return nil
+}
+
 func (t *MsgManagement) Delete(msg types.IMessage) {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
@@ -109,11 +246,37 @@ func (t *MsgManagement) Delete(msg types.IMessage) {
 	t.update()
 }
 
+// If the message pool is full, delete the message with the lowest fee per
+// byte if the new message pays more. Only the message with the largest
+// nonce of an address is deleted, so the remaining messages of the address
+// can still be packaged in order.
 func (t *MsgManagement) DeleteEnd(newTx types.IMessage) {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 
-	t.ready.PopMin(newTx.Fee())
+	tails := make(map[string]types.IMessage)
+	for _, msg := range append(t.ready.Tails(), t.cache.All()...) {
+		from := msg.From().String()
+		if tail, ok := tails[from]; !ok || tail.Nonce() < msg.Nonce() {
+			tails[from] = msg
+		}
+	}
+	delete(tails, newTx.From().String())
+
+	var worst types.IMessage
+	for _, msg := range tails {
+		if worst == nil || packedBefore(worst, msg) {
+			worst = msg
+		}
+	}
+	if worst == nil || !packedBefore(newTx, worst) {
+		return
+	}
+	if t.ready.Exist(worst.Hash().String()) {
+		t.ready.Remove(worst)
+	} else {
+		t.cache.Remove(worst)
+	}
 }
 
 func (t *MsgManagement) NeedPackaged(maxSize uint32) []types.IMessage {
@@ -139,6 +302,19 @@ func (t *MsgManagement) GetAll() ([]types.IMessage, []types.IMessage) {
 	return readyTxs, cacheTxs
 }
 
+// The ready and cached messages of the address, sorted by nonce
+func (t *MsgManagement) GetByAddress(addr string) ([]types.IMessage, []types.IMessage) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	ready := append([]types.IMessage{}, t.ready.GetByAddress(addr)...)
+	cache := t.cache.GetByAddress(addr)
+	sort.Slice(cache, func(i, j int) bool {
+		return cache[i].Nonce() < cache[j].Nonce()
+	})
+	return ready, cache
+}
+
 func (t *MsgManagement) Get(msgHash string) (types.IMessage, bool) {
 	t.mutex.RLock()
 	defer t.mutex.RUnlock()
@@ -156,33 +332,37 @@ func (t *MsgManagement) Exist(msg types.IMessage) bool {
 	defer t.mutex.RUnlock()
 
 	if !t.ready.Exist(msg.Hash().String()) {
-		return t.cache.Exist(msg.From().String())
+		return t.cache.Exist(msg.Hash().String())
 	}
 	return true
 }
 
 func (t *MsgManagement) Update() {
-	t.mutex.RLock()
-	defer t.mutex.RUnlock()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
 
 	t.update()
 }
 
 func (t *MsgManagement) update() {
 	t.ready.RemoveExecuted(t.validator)
+	addrs := make(map[string]arry.Address)
 	for _, msg := range t.cache.msgs {
-		nonce := t.actStatus.Nonce(msg.From())
-		if nonce < msg.Nonce()-1 {
+		if t.actStatus.Nonce(msg.From()) >= msg.Nonce() {
+			t.cache.Remove(msg)
 			continue
 		}
-		if nonce == msg.Nonce()-1 {
-			t.ready.Put(msg)
-		}
-		t.cache.Remove(msg)
+		addrs[msg.From().String()] = msg.From()
+	}
+	for from, addr := range addrs {
+		t.promote(from, t.actStatus.Nonce(addr))
 	}
 }
 
 func (t *MsgManagement) DeleteExpired(timeThreshold int64) {
+	// Read the height before locking, the chain may call back into the pool while holding its lock
+	height, now := t.lastHeight()+1, uint64(utils.NowUnix())
+
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 
@@ -193,6 +373,18 @@ func (t *MsgManagement) DeleteExpired(timeThreshold int64) {
 			t.cache.Remove(msg)
 		}
 	}
+
+	// Messages that can no longer be packaged into the next block
+	for _, msg := range t.ready.All() {
+		if chaintypes.IsExpired(msg.ValidUntil(), height, now) {
+			t.ready.Remove(msg)
+		}
+	}
+	for _, msg := range t.cache.msgs {
+		if chaintypes.IsExpired(msg.ValidUntil(), height, now) {
+			t.cache.Remove(msg)
+		}
+	}
 }
 
 func (t *MsgManagement) remove(msg types.IMessage) {
@@ -0,0 +1,263 @@
+package msglist
+
+import (
+	chaintypes "github.com/aiot-network/aiotchain/chain/types"
+	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/common/param"
+	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/tools/utils"
+	"github.com/aiot-network/aiotchain/types"
+	"testing"
+)
+
+type memDB struct {
+	msgs map[string]types.IMessage
+}
+
+func newMemDB() *memDB {
+	return &memDB{msgs: make(map[string]types.IMessage)}
+}
+
+func (d *memDB) Read() []types.IMessage {
+	msgs := make([]types.IMessage, 0, len(d.msgs))
+	for _, msg := range d.msgs {
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+func (d *memDB) Save(msg types.IMessage)   { d.msgs[msg.Hash().String()] = msg }
+func (d *memDB) Delete(msg types.IMessage) { delete(d.msgs, msg.Hash().String()) }
+func (d *memDB) Clear()                    { d.msgs = make(map[string]types.IMessage) }
+func (d *memDB) Close() error              { return nil }
+
+type testValidator struct{}
+
+func (v *testValidator) CheckMsg(types.IMessage, bool) error { return nil }
+
+type testAccount struct {
+	types.IAccount
+	balance uint64
+}
+
+func (a *testAccount) GetBalance(arry.Address) uint64 { return a.balance }
+
+type testActStatus struct {
+	types.IActStatus
+	nonces  map[string]uint64
+	balance uint64
+}
+
+func (s *testActStatus) Nonce(addr arry.Address) uint64 { return s.nonces[addr.String()] }
+
+func (s *testActStatus) Account(arry.Address) types.IAccount { return &testAccount{balance: s.balance} }
+
+func newTestMsgManagement(db ITxListDB, nonces map[string]uint64) *MsgManagement {
+	config.Param = param.TestNetParam
+	return &MsgManagement{
+		cache:      NewCache(db),
+		ready:      NewSorted(db, config.Param.PoolParam),
+		validator:  &testValidator{},
+		actStatus:  &testActStatus{nonces: nonces, balance: 1 << 50},
+		msgDB:      db,
+		lastHeight: func() uint64 { return 10 },
+		capacity:   maxPoolTx,
+	}
+}
+
+func newRecentMsg(from string, nonce uint64) types.IMessage {
+	msg := newTestMsg(from, nonce, 100000, 1)
+	msg.Header.Time = uint64(utils.NowUnix())
+	return msg
+}
+
+func TestMsgManagement_Recover(t *testing.T) {
+	nonces := map[string]uint64{arry.StringToAddress("A").String(): 2}
+	mgt := newTestMsgManagement(newMemDB(), nonces)
+	for nonce := uint64(3); nonce <= 4; nonce++ {
+		if err := mgt.Put(newRecentMsg("A", nonce)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The blocks with nonce 1 and 2 are rolled back
+	nonces[arry.StringToAddress("A").String()] = 0
+	mgt.Recover([]types.IMessage{newRecentMsg("A", 2), newRecentMsg("A", 1)})
+
+	ready, cache := mgt.GetByAddress(arry.StringToAddress("A").String())
+	if len(ready) != 4 || len(cache) != 0 {
+		t.Fatalf("%d ready and %d cached messages, expect 4 ready messages", len(ready), len(cache))
+	}
+	for i, msg := range ready {
+		if msg.Nonce() != uint64(i+1) {
+			t.Fatalf("message %d has nonce %d, expect %d", i, msg.Nonce(), i+1)
+		}
+	}
+}
+
+func TestMsgManagement_ReadPurges(t *testing.T) {
+	db := newMemDB()
+	valid := newRecentMsg("A", 2)
+	packaged := newRecentMsg("A", 1)
+	expired := newTestMsg("B", 1, 100000, 1)
+	for _, msg := range []types.IMessage{valid, packaged, expired} {
+		db.Save(msg)
+	}
+
+	mgt := newTestMsgManagement(db, map[string]uint64{arry.StringToAddress("A").String(): 1})
+	if err := mgt.Read(); err != nil {
+		t.Fatal(err)
+	}
+	if mgt.Count() != 1 {
+		t.Fatalf("%d messages are recovered, expect 1", mgt.Count())
+	}
+	if len(db.msgs) != 1 || db.msgs[valid.Hash().String()] == nil {
+		t.Fatalf("the expired and packaged messages should be deleted from the db")
+	}
+}
+
+func TestMsgManagement_ValidUntil(t *testing.T) {
+	mgt := newTestMsgManagement(newMemDB(), map[string]uint64{})
+	expired := newRecentMsg("A", 1).(*chaintypes.Message)
+	expired.Header.ValidUntil = 10
+	if err := mgt.Put(expired); err == nil {
+		t.Fatalf("the message valid until height 10 should be rejected at height 11")
+	}
+
+	valid := newRecentMsg("A", 1).(*chaintypes.Message)
+	valid.Header.ValidUntil = 11
+	if err := mgt.Put(valid); err != nil {
+		t.Fatal(err)
+	}
+	mgt.lastHeight = func() uint64 { return 11 }
+	mgt.DeleteExpired(0)
+	if mgt.Count() != 0 {
+		t.Fatalf("the message valid until height 11 should be deleted at height 12")
+	}
+}
+
+func newFeeMsg(from string, nonce, fee uint64) types.IMessage {
+	msg := newTestMsg(from, nonce, fee, 1)
+	msg.Header.Time = uint64(utils.NowUnix())
+	return msg
+}
+
+func TestMsgManagement_EvictLowestTail(t *testing.T) {
+	mgt := newTestMsgManagement(newMemDB(), map[string]uint64{})
+	mgt.capacity = 4
+	lowest := newFeeMsg("A", 1, 100000)
+	tail := newFeeMsg("B", 1, 200000)
+	for _, msg := range []types.IMessage{lowest, newFeeMsg("A", 2, 400000), tail, newFeeMsg("C", 2, 300000)} {
+		if err := mgt.Put(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The lowest fee message is followed by another message of the address,
+	// so the lowest fee message with the largest nonce of its address is evicted
+	if err := mgt.Put(newFeeMsg("D", 1, 250000)); err != nil {
+		t.Fatal(err)
+	}
+	if mgt.Exist(tail) || !mgt.Exist(lowest) || mgt.Count() != 4 {
+		t.Fatalf("the message of B should be evicted")
+	}
+
+	err := mgt.Put(newFeeMsg("E", 1, 150000))
+	if msgErr, ok := chaintypes.AsMsgError(err); !ok || msgErr.Code != chaintypes.ErrPoolFull {
+		t.Fatalf("the message paying less than the pool should be rejected, got %v", err)
+	}
+}
+
+func TestMsgManagement_ReplaceByFee(t *testing.T) {
+	db := newMemDB()
+	mgt := newTestMsgManagement(db, map[string]uint64{})
+	old := newFeeMsg("A", 1, 100000)
+	next := newFeeMsg("A", 2, 100000)
+	future := newFeeMsg("A", 4, 100000)
+	for _, msg := range []types.IMessage{old, next, future} {
+		if err := mgt.Put(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replace := func(msg types.IMessage, fee uint64) types.IMessage {
+		replacement := newFeeMsg("A", msg.Nonce(), fee).(*chaintypes.Message)
+		replacement.Header.Hash = arry.BytesToHash([]byte(msg.Hash().String() + "replaced"))
+		return replacement
+	}
+	err := mgt.Put(replace(old, chaintypes.ReplaceFee(old.Fee())-1))
+	if msgErr, ok := chaintypes.AsMsgError(err); !ok || msgErr.Code != chaintypes.ErrFeeTooLow {
+		t.Fatalf("the replacement without the fee bump should be rejected, got %v", err)
+	}
+
+	for _, msg := range []types.IMessage{old, future} {
+		replacement := replace(msg, chaintypes.ReplaceFee(msg.Fee()))
+		if err := mgt.Put(replacement); err != nil {
+			t.Fatal(err)
+		}
+		if mgt.Exist(msg) || db.msgs[msg.Hash().String()] != nil {
+			t.Fatalf("the replaced message of nonce %d should be deleted from the pool and the db", msg.Nonce())
+		}
+		if !mgt.Exist(replacement) || db.msgs[replacement.Hash().String()] == nil {
+			t.Fatalf("the replacement of nonce %d should be saved", msg.Nonce())
+		}
+	}
+	if mgt.Count() != 3 {
+		t.Fatalf("%d messages in the pool, expect 3", mgt.Count())
+	}
+}
+
+func TestMsgManagement_ReplaceBalance(t *testing.T) {
+	mgt := newTestMsgManagement(newMemDB(), map[string]uint64{})
+	mgt.actStatus.(*testActStatus).balance = 500000
+	old := newFeeMsg("A", 1, 200000)
+	if err := mgt.Put(old); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgt.Put(newFeeMsg("A", 2, 200000)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The replaced message is not counted, but the other pending message is
+	replacement := newFeeMsg("A", 1, 400000).(*chaintypes.Message)
+	replacement.Header.Hash = arry.BytesToHash([]byte("replacement"))
+	err := mgt.Put(replacement)
+	if msgErr, ok := chaintypes.AsMsgError(err); !ok || msgErr.Code != chaintypes.ErrInsufficientBalance {
+		t.Fatalf("the replacement exceeding the balance should be rejected, got %v", err)
+	}
+	if !mgt.Exist(old) {
+		t.Fatalf("the rejected replacement should keep the old message")
+	}
+}
+
+func newCancelMsg(from string, nonce, fee uint64) types.IMessage {
+	msg := newFeeMsg(from, nonce, fee).(*chaintypes.Message)
+	msg.Header.Hash = arry.BytesToHash([]byte(msg.Hash().String() + "cancel"))
+	receivers := chaintypes.NewReceivers()
+	receivers.Add(msg.From(), 0)
+	msg.Body = &chaintypes.TransactionBody{TokenAddress: config.Param.MainToken, Receivers: receivers}
+	return msg
+}
+
+func TestMsgManagement_Cancel(t *testing.T) {
+	mgt := newTestMsgManagement(newMemDB(), map[string]uint64{})
+	defer func(height uint64) { config.Param.CancelHeight = height }(config.Param.CancelHeight)
+	old := newFeeMsg("A", 1, 100000)
+	if err := mgt.Put(old); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Param.CancelHeight = 12
+	err := mgt.Put(newCancelMsg("A", 1, chaintypes.ReplaceFee(old.Fee())))
+	if msgErr, ok := chaintypes.AsMsgError(err); !ok || msgErr.Code != chaintypes.ErrBadAmount {
+		t.Fatalf("the cancel message should be rejected before the fork height, got %v", err)
+	}
+
+	config.Param.CancelHeight = 11
+	cancel := newCancelMsg("A", 1, chaintypes.ReplaceFee(old.Fee()))
+	if err := mgt.Put(cancel); err != nil {
+		t.Fatal(err)
+	}
+	if mgt.Exist(old) || !mgt.Exist(cancel) {
+		t.Fatalf("the cancel message should replace the pending message")
+	}
+}
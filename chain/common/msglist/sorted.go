@@ -2,39 +2,57 @@ package msglist
 
 import (
 	"container/heap"
+	"github.com/aiot-network/aiotchain/common/param"
 	"github.com/aiot-network/aiotchain/common/validator"
 	"github.com/aiot-network/aiotchain/tools/utils"
 	"github.com/aiot-network/aiotchain/types"
+	"math/bits"
+	"sort"
 )
 
 const maxStagnantTime uint64 = 60
 
 type Sorted struct {
-	msgs  map[string]types.IMessage
+	// Messages of each address, sorted by nonce
+	msgs  map[string][]types.IMessage
 	cache map[string]types.IMessage
 	index *msgInfos
 	db    ITxListDB
+	// Message types packed first within the reserved block space
+	priorityTypes   map[int]bool
+	priorityReserve uint32
 }
 
-func NewSorted(db ITxListDB) *Sorted {
+func NewSorted(db ITxListDB, poolParam *param.PoolParam) *Sorted {
+	priorityTypes := make(map[int]bool)
+	for _, msgType := range poolParam.PriorityMsgTypes {
+		priorityTypes[int(msgType)] = true
+	}
 	return &Sorted{
-		msgs:  make(map[string]types.IMessage),
-		cache: make(map[string]types.IMessage),
-		index: new(msgInfos),
-		db:    db,
+		msgs:            make(map[string][]types.IMessage),
+		cache:           make(map[string]types.IMessage),
+		index:           new(msgInfos),
+		db:              db,
+		priorityTypes:   priorityTypes,
+		priorityReserve: poolParam.PriorityReserve,
 	}
 }
 
 func (t *Sorted) Put(msg types.IMessage) {
-	t.msgs[msg.From().String()] = msg
-	t.cache[msg.Hash().String()] = msg
-	heap.Push(t.index, &msgInfo{
-		address: msg.From().String(),
-		msgHash: msg.Hash().String(),
-		fees:    msg.Fee(),
-		nonce:   msg.Nonce(),
-		time:    msg.Time(),
+	from := msg.From().String()
+	if old := t.GetByNonce(from, msg.Nonce()); old != nil {
+		t.Remove(old)
+	}
+	queue := t.msgs[from]
+	i := sort.Search(len(queue), func(i int) bool {
+		return queue[i].Nonce() > msg.Nonce()
 	})
+	queue = append(queue, nil)
+	copy(queue[i+1:], queue[i:])
+	queue[i] = msg
+	t.msgs[from] = queue
+	t.cache[msg.Hash().String()] = msg
+	heap.Push(t.index, newMsgInfo(msg))
 	t.db.Save(msg)
 }
 
@@ -51,21 +69,50 @@ func (t *Sorted) All() []types.IMessage {
 	return all
 }
 
+// Messages are packaged in order of fee per byte, but the messages of one
+// address are always packaged in order of nonce. Priority messages are
+// packaged first within the reserved part of the block space.
 func (t *Sorted) NeedPackaged(maxSize uint32) []types.IMessage {
 	msgs := make([]types.IMessage, 0)
-	rIndex := t.index.CopySelf()
-
+	next := make(map[string]int)
 	var sumBytes uint32
-	for rIndex.Len() > 0 && maxSize > sumBytes {
-		ti := heap.Pop(rIndex).(*msgInfo)
-		msg := t.msgs[ti.address]
-		length := uint32(len(msg.ToRlp().Bytes()))
-		sumBytes += length
-		if sumBytes >= maxSize {
-			return msgs
+
+	pack := func(limit uint32, priority bool) {
+		inLane := func(msg types.IMessage) bool {
+			return !priority || t.priorityTypes[msg.Type()]
+		}
+		heads := new(msgInfos)
+		for addr, queue := range t.msgs {
+			i := next[addr]
+			if i >= len(queue) || !inLane(queue[i]) {
+				continue
+			}
+			if i > 0 && queue[i-1].Nonce()+1 != queue[i].Nonce() {
+				continue
+			}
+			*heads = append(*heads, newMsgInfo(queue[i]))
+		}
+		heap.Init(heads)
+		for heads.Len() > 0 {
+			ti := heap.Pop(heads).(*msgInfo)
+			if sumBytes+ti.size >= limit {
+				continue
+			}
+			msg := t.cache[ti.msgHash]
+			msgs = append(msgs, msg)
+			sumBytes += ti.size
+
+			next[ti.address]++
+			queue := t.msgs[ti.address]
+			if i := next[ti.address]; i < len(queue) && queue[i].Nonce() == msg.Nonce()+1 && inLane(queue[i]) {
+				heap.Push(heads, newMsgInfo(queue[i]))
+			}
 		}
-		msgs = append(msgs, msg)
 	}
+	if t.priorityReserve > 0 {
+		pack(uint32(uint64(maxSize)*uint64(t.priorityReserve)/100), true)
+	}
+	pack(maxSize, false)
 	return msgs
 }
 
@@ -76,7 +123,7 @@ func (t *Sorted) StagnantMsgs() []types.IMessage {
 	timeNow := uint64(utils.NowUnix())
 	for rIndex.Len() > 0 {
 		ti := heap.Pop(rIndex).(*msgInfo)
-		msg := t.msgs[ti.address]
+		msg := t.cache[ti.msgHash]
 		if msg != nil && msg.Time() < timeNow && msg.Time()+maxStagnantTime > timeNow {
 			msgs = append(msgs, msg)
 		}
@@ -85,26 +132,38 @@ func (t *Sorted) StagnantMsgs() []types.IMessage {
 	return msgs
 }
 
-func (t *Sorted) GetByAddress(addr string) types.IMessage {
+func (t *Sorted) GetByAddress(addr string) []types.IMessage {
 	return t.msgs[addr]
 }
 
-// If the message pool is full, delete the message with a small fee
-func (t *Sorted) PopMin(fees uint64) types.IMessage {
-	if t.Len() > 0 {
-		if (*t.index)[0].fees <= fees {
-			ti := heap.Remove(t.index, 0).(*msgInfo)
-			msg := t.msgs[ti.address]
-			delete(t.msgs, ti.address)
-			delete(t.cache, ti.msgHash)
-			t.db.Delete(msg)
+func (t *Sorted) GetByNonce(addr string, nonce uint64) types.IMessage {
+	for _, msg := range t.msgs[addr] {
+		if msg.Nonce() == nonce {
 			return msg
 		}
 	}
 	return nil
 }
 
-func (t *Sorted) Len() int { return len(t.msgs) }
+// The largest nonce of the address in the ready list
+func (t *Sorted) LastNonce(addr string) (uint64, bool) {
+	queue := t.msgs[addr]
+	if len(queue) == 0 {
+		return 0, false
+	}
+	return queue[len(queue)-1].Nonce(), true
+}
+
+// The message with the largest nonce of each address
+func (t *Sorted) Tails() []types.IMessage {
+	tails := make([]types.IMessage, 0, len(t.msgs))
+	for _, queue := range t.msgs {
+		tails = append(tails, queue[len(queue)-1])
+	}
+	return tails
+}
+
+func (t *Sorted) Len() int { return len(t.cache) }
 
 func (t *Sorted) Exist(msgHash string) bool {
 	_, ok := t.cache[msgHash]
@@ -115,7 +174,7 @@ func (t *Sorted) Remove(msg types.IMessage) {
 	for i, ti := range *(t.index) {
 		if ti.msgHash == msg.Hash().String() {
 			heap.Remove(t.index, i)
-			delete(t.msgs, msg.From().String())
+			t.removeFromQueue(msg)
 			delete(t.cache, msg.Hash().String())
 			t.db.Delete(msg)
 			return
@@ -123,6 +182,22 @@ func (t *Sorted) Remove(msg types.IMessage) {
 	}
 }
 
+func (t *Sorted) removeFromQueue(msg types.IMessage) {
+	from := msg.From().String()
+	queue := t.msgs[from]
+	for i, m := range queue {
+		if m.Hash().IsEqual(msg.Hash()) {
+			queue = append(queue[0:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) == 0 {
+		delete(t.msgs, from)
+	} else {
+		t.msgs[from] = queue
+	}
+}
+
 // Delete already packed messages
 func (t *Sorted) RemoveExecuted(v validator.IValidator) {
 	for _, msg := range t.cache {
@@ -149,11 +224,47 @@ type msgInfo struct {
 	fees    uint64
 	nonce   uint64
 	time    uint64
+	size    uint32
 }
 
-func (t msgInfos) Len() int           { return len(t) }
-func (t msgInfos) Less(i, j int) bool { return t[i].fees > t[j].fees }
-func (t msgInfos) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func newMsgInfo(msg types.IMessage) *msgInfo {
+	return &msgInfo{
+		address: msg.From().String(),
+		msgHash: msg.Hash().String(),
+		fees:    msg.Fee(),
+		nonce:   msg.Nonce(),
+		time:    msg.Time(),
+		size:    uint32(len(msg.ToRlp().Bytes())),
+	}
+}
+
+func (t msgInfos) Len() int { return len(t) }
+
+// Messages with higher fee per byte come first, ties are broken
+// by fee, time and hash so that the order is deterministic
+func (t msgInfos) Less(i, j int) bool {
+	hi1, lo1 := bits.Mul64(t[i].fees, uint64(t[j].size))
+	hi2, lo2 := bits.Mul64(t[j].fees, uint64(t[i].size))
+	if hi1 != hi2 {
+		return hi1 > hi2
+	}
+	if lo1 != lo2 {
+		return lo1 > lo2
+	}
+	if t[i].fees != t[j].fees {
+		return t[i].fees > t[j].fees
+	}
+	if t[i].time != t[j].time {
+		return t[i].time < t[j].time
+	}
+	return t[i].msgHash < t[j].msgHash
+}
+func (t msgInfos) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+
+// Whether the first message is packaged before the second one
+func packedBefore(msg1, msg2 types.IMessage) bool {
+	return msgInfos{newMsgInfo(msg1), newMsgInfo(msg2)}.Less(0, 1)
+}
 
 func (t *msgInfos) Push(x interface{}) {
 	*t = append(*t, x.(*msgInfo))
@@ -0,0 +1,158 @@
+package msglist
+
+import (
+	"fmt"
+	chaintypes "github.com/aiot-network/aiotchain/chain/types"
+	"github.com/aiot-network/aiotchain/common/param"
+	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/types"
+	"testing"
+)
+
+type testDB struct{}
+
+func (d *testDB) Read() []types.IMessage      { return nil }
+func (d *testDB) Save(message types.IMessage) {}
+func (d *testDB) Delete(msg types.IMessage)   {}
+func (d *testDB) Clear()                      {}
+func (d *testDB) Close() error                { return nil }
+
+var testPoolParam = &param.PoolParam{
+	PriorityMsgTypes: []uint8{uint8(chaintypes.Vote)},
+	PriorityReserve:  10,
+}
+
+func newTestMsg(from string, nonce, fee uint64, receivers int) *chaintypes.Message {
+	to := chaintypes.NewReceivers()
+	for i := 0; i < receivers; i++ {
+		to.Add(arry.StringToAddress(fmt.Sprintf("receiver%d", i)), 1)
+	}
+	return newTestMsgBody(chaintypes.Transaction, from, nonce, fee, &chaintypes.TransactionBody{Receivers: to})
+}
+
+func newTestMsgBody(msgType chaintypes.MessageType, from string, nonce, fee uint64, body types.IMessageBody) *chaintypes.Message {
+	return &chaintypes.Message{
+		Header: &chaintypes.MsgHeader{
+			Type:  msgType,
+			Hash:  arry.BytesToHash([]byte(fmt.Sprintf("%s_%d", from, nonce))),
+			From:  arry.StringToAddress(from),
+			Nonce: nonce,
+			Fee:   fee,
+			Time:  1,
+		},
+		Body: body,
+	}
+}
+
+func msgSize(msg types.IMessage) uint32 {
+	return uint32(len(msg.ToRlp().Bytes()))
+}
+
+func packedHashes(msgs []types.IMessage) []string {
+	hashes := make([]string, len(msgs))
+	for i, msg := range msgs {
+		hashes[i] = msg.Hash().String()
+	}
+	return hashes
+}
+
+func TestSorted_NeedPackagedFeePerByte(t *testing.T) {
+	sorted := NewSorted(&testDB{}, &param.PoolParam{})
+	small := newTestMsg("A", 1, 100000, 1)
+	large := newTestMsg("B", 1, 120000, 10)
+	sorted.Put(large)
+	sorted.Put(small)
+
+	msgs := sorted.NeedPackaged(1 << 20)
+	if len(msgs) != 2 {
+		t.Fatalf("packaged %d messages, expect 2", len(msgs))
+	}
+	if !msgs[0].Hash().IsEqual(small.Hash()) {
+		t.Fatalf("the message with higher fee per byte should be packaged first")
+	}
+}
+
+func TestSorted_NeedPackagedNonceOrder(t *testing.T) {
+	sorted := NewSorted(&testDB{}, &param.PoolParam{})
+	sorted.Put(newTestMsg("A", 3, 300000, 1))
+	sorted.Put(newTestMsg("A", 1, 100000, 1))
+	sorted.Put(newTestMsg("A", 2, 200000, 1))
+	sorted.Put(newTestMsg("A", 5, 500000, 1))
+
+	msgs := sorted.NeedPackaged(1 << 20)
+	if len(msgs) != 3 {
+		t.Fatalf("packaged %d messages, expect 3", len(msgs))
+	}
+	for i, msg := range msgs {
+		if msg.Nonce() != uint64(i+1) {
+			t.Fatalf("message %d has nonce %d, expect %d", i, msg.Nonce(), i+1)
+		}
+	}
+}
+
+func TestSorted_NeedPackagedPriorityReserve(t *testing.T) {
+	sorted := NewSorted(&testDB{}, testPoolParam)
+	vote := newTestMsgBody(chaintypes.Vote, "V", 1, 10000, &chaintypes.VoteBody{To: arry.StringToAddress("S")})
+	sorted.Put(vote)
+	var transfers []*chaintypes.Message
+	for i := 0; i < 20; i++ {
+		msg := newTestMsg(fmt.Sprintf("T%d", i), 1, 1000000, 1)
+		transfers = append(transfers, msg)
+		sorted.Put(msg)
+	}
+
+	// The block can hold the vote and some of the transfers,
+	// the vote fits into the reserved space
+	if msgSize(transfers[0]) < msgSize(vote) {
+		t.Fatalf("the transfer is expected to be larger than the vote")
+	}
+	maxSize := msgSize(vote) * 20
+	msgs := sorted.NeedPackaged(maxSize)
+	if len(msgs) == 0 || !msgs[0].Hash().IsEqual(vote.Hash()) {
+		t.Fatalf("the priority message should be packaged first")
+	}
+	if len(msgs) >= len(transfers)+1 {
+		t.Fatalf("packaged %d messages, the block size limit is ignored", len(msgs))
+	}
+
+	// Without the reserve the vote with the lowest fee is left out
+	sorted = NewSorted(&testDB{}, &param.PoolParam{})
+	sorted.Put(vote)
+	for _, msg := range transfers {
+		sorted.Put(msg)
+	}
+	for _, msg := range sorted.NeedPackaged(maxSize) {
+		if msg.Hash().IsEqual(vote.Hash()) {
+			t.Fatalf("the vote should not be packaged without reserved space")
+		}
+	}
+}
+
+func TestSorted_NeedPackagedDeterministic(t *testing.T) {
+	var msgs []*chaintypes.Message
+	for i := 0; i < 20; i++ {
+		msgs = append(msgs, newTestMsg(fmt.Sprintf("A%d", i%5), uint64(i/5+1), 100000, 1+i%3))
+	}
+	msgs = append(msgs, newTestMsgBody(chaintypes.Vote, "V", 1, 10000, &chaintypes.VoteBody{To: arry.StringToAddress("S")}))
+
+	forward := NewSorted(&testDB{}, testPoolParam)
+	for _, msg := range msgs {
+		forward.Put(msg)
+	}
+	backward := NewSorted(&testDB{}, testPoolParam)
+	for i := len(msgs) - 1; i >= 0; i-- {
+		backward.Put(msgs[i])
+	}
+
+	maxSize := msgSize(msgs[0]) * 12
+	rs1 := packedHashes(forward.NeedPackaged(maxSize))
+	rs2 := packedHashes(backward.NeedPackaged(maxSize))
+	if len(rs1) != len(rs2) {
+		t.Fatalf("packaged %d and %d messages", len(rs1), len(rs2))
+	}
+	for i := range rs1 {
+		if rs1[i] != rs2[i] {
+			t.Fatalf("message %d is different, %s and %s", i, rs1[i], rs2[i])
+		}
+	}
+}
@@ -1,8 +1,6 @@
 package act_status
 
 import (
-	"errors"
-	"fmt"
 	"github.com/aiot-network/aiotchain/chain/db/status/act_db"
 	fmtypes "github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/common/config"
@@ -20,6 +18,9 @@ type ActStatus struct {
 	db        IActDB
 	mutex     sync.RWMutex
 	confirmed uint64
+	// Accounts changed since the last commit, their
+	// holder index is updated when they are committed
+	dirty map[arry.Address]bool
 }
 
 func NewActStatus() (*ActStatus, error) {
@@ -27,12 +28,35 @@ func NewActStatus() (*ActStatus, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ActStatus{db: db}, nil
+	return &ActStatus{db: db, dirty: make(map[arry.Address]bool)}, nil
 }
 
-// Initialize account balance root hash
+// Initialize account balance root hash, the holder index is
+// rebuilt if it was not built for the root, such as after a rollback
 func (a *ActStatus) SetTrieRoot(stateRoot arry.Hash) error {
-	return a.db.SetRoot(stateRoot)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if err := a.db.SetRoot(stateRoot); err != nil {
+		return err
+	}
+	a.dirty = make(map[arry.Address]bool)
+	if !a.db.HolderIndexed(stateRoot) {
+		return a.db.IndexHolders()
+	}
+	return nil
+}
+
+// Copy the account status, changes of the copy are discarded
+func (a *ActStatus) Copy() (types.IActStatus, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	db, err := a.db.Copy()
+	if err != nil {
+		return nil, err
+	}
+	return &ActStatus{db: db, confirmed: a.confirmed, dirty: make(map[arry.Address]bool)}, nil
 }
 
 func (a *ActStatus) CheckMessage(msg types.IMessage, strict bool) error {
@@ -41,7 +65,8 @@ func (a *ActStatus) CheckMessage(msg types.IMessage, strict bool) error {
 
 	now := uint64(utils.NowUnix())
 	if msg.Time() > now+60*10 {
-		return fmt.Errorf("incorrect message time, msg time = %d, now time = %d", msg.Time(), now)
+		return fmtypes.NewMsgError(fmtypes.ErrBadTime, fmtypes.ErrDetails{"time": msg.Time(), "now": now},
+			"incorrect message time, msg time = %d, now time = %d", msg.Time(), now)
 	}
 
 	account := a.Account(msg.From())
@@ -56,23 +81,23 @@ func (a *ActStatus) checkBody(msg types.IMessage) error {
 	case fmtypes.Work:
 		body, ok := msg.MsgBody().(*fmtypes.WorkBody)
 		if !ok {
-			return errors.New("wrong message")
+			return fmtypes.NewMsgError(fmtypes.ErrBadMessage, nil, "wrong message")
 		}
 		cycle := msg.Time() / param.CycleInterval
 		for _, work := range body.List {
 			addrAct := a.db.Account(work.Address)
 			work := addrAct.GetWorks()
 			if cycle < work.GetCycle() {
-				return errors.New("the work is overdue")
+				return fmtypes.NewMsgError(fmtypes.ErrBadTime, nil, "the work is overdue")
 			}
 			if body.StartTime < work.GetEndTime() {
-				return errors.New("work start time overlaps with previous work")
+				return fmtypes.NewMsgError(fmtypes.ErrBadTime, nil, "work start time overlaps with previous work")
 			}
 			if body.EndTime > uint64(time.Now().Unix()) {
-				return errors.New("wong end time")
+				return fmtypes.NewMsgError(fmtypes.ErrBadTime, nil, "wong end time")
 			}
 			if body.EndTime <= body.StartTime {
-				return errors.New("wong end time")
+				return fmtypes.NewMsgError(fmtypes.ErrBadTime, nil, "wong end time")
 			}
 		}
 	}
@@ -106,7 +131,7 @@ func (a *ActStatus) WorkMessage(msg types.IMessage) error {
 
 	body, ok := msg.MsgBody().(*fmtypes.WorkBody)
 	if !ok {
-		return errors.New("wrong message")
+		return fmtypes.NewMsgError(fmtypes.ErrBadMessage, nil, "wrong message")
 	}
 	cycle := msg.Time() / param.CycleInterval
 	for _, work := range body.List {
@@ -211,6 +236,35 @@ func (a *ActStatus) ToMessage(msg types.IMessage, height uint64) error {
 	return nil
 }
 
+// Get the accounts holding the token in address order and the number
+// of holders. The holder index is paged, only the accounts in the page
+// are loaded.
+func (a *ActStatus) TokenHolders(token arry.Address, offset, limit uint64) ([]types.IAccount, uint64) {
+	a.mutex.RLock()
+	addrs := a.db.Holders(token, offset, limit)
+	count := a.db.HolderCount(token)
+	a.mutex.RUnlock()
+
+	return a.accounts(addrs), count
+}
+
+// Get the accounts holding the most of the token
+func (a *ActStatus) RichList(token arry.Address, limit uint64) []types.IAccount {
+	a.mutex.RLock()
+	addrs := a.db.RichList(token, limit)
+	a.mutex.RUnlock()
+
+	return a.accounts(addrs)
+}
+
+func (a *ActStatus) accounts(addrs []arry.Address) []types.IAccount {
+	accounts := make([]types.IAccount, len(addrs))
+	for i, addr := range addrs {
+		accounts[i] = a.Account(addr)
+	}
+	return accounts
+}
+
 func (a *ActStatus) SetConfirmed(height uint64) {
 	a.confirmed = height
 }
@@ -219,15 +273,29 @@ func (a *ActStatus) SetConfirmed(height uint64) {
 func (a *ActStatus) Check(msg types.IMessage, strict bool) error {
 	now := uint64(utils.NowUnix())
 	if msg.Time() > now+60*10 {
-		return fmt.Errorf("incorrect message time, msg time = %d, now time = %d", msg.Time(), now)
+		return fmtypes.NewMsgError(fmtypes.ErrBadTime, fmtypes.ErrDetails{"time": msg.Time(), "now": now},
+			"incorrect message time, msg time = %d, now time = %d", msg.Time(), now)
 	}
 
 	account := a.Account(msg.From())
 	return account.Check(msg, strict)
 }
 
+// Commit the account trie and update the holder index of the changed accounts
 func (a *ActStatus) Commit() (arry.Hash, error) {
-	return a.db.Commit()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	root, err := a.db.Commit()
+	if err != nil {
+		return root, err
+	}
+	for address := range a.dirty {
+		a.db.IndexAccount(a.db.Account(address))
+	}
+	a.db.SetHolderIndexed(root)
+	a.dirty = make(map[arry.Address]bool)
+	return root, nil
 }
 
 func (a *ActStatus) TrieRoot() arry.Hash {
@@ -240,6 +308,7 @@ func (a *ActStatus) Close() error {
 
 func (a *ActStatus) setAccount(account types.IAccount) {
 	a.db.SetAccount(account)
+	a.dirty[account.GetAddress()] = true
 }
 
 // Update the locked balance of an account
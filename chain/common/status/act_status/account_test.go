@@ -0,0 +1,96 @@
+package act_status
+
+import (
+	"github.com/aiot-network/aiotchain/chain/db/status/act_db"
+	chaintypes "github.com/aiot-network/aiotchain/chain/types"
+	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/common/param"
+	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/tools/crypto/hash"
+	"github.com/aiot-network/aiotchain/tools/utils"
+	"strconv"
+	"testing"
+)
+
+func newTestActStatus(t *testing.T) *ActStatus {
+	config.Param = param.TestNetParam
+	db, err := act_db.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.SetRoot(arry.Hash{}); err != nil {
+		t.Fatal(err)
+	}
+	return &ActStatus{db: db, dirty: make(map[arry.Address]bool)}
+}
+
+func newTransfer(from, to arry.Address, amount, nonce uint64) *chaintypes.Message {
+	receivers := chaintypes.NewReceivers()
+	receivers.Add(to, amount)
+	return &chaintypes.Message{
+		Header: &chaintypes.MsgHeader{
+			Type:  chaintypes.Transaction,
+			Hash:  hash.Hash([]byte(from.String() + strconv.FormatUint(nonce, 10))),
+			From:  from,
+			Nonce: nonce,
+			Fee:   chaintypes.MinFee(chaintypes.Transaction, 1),
+			Time:  uint64(utils.NowUnix()),
+		},
+		Body: &chaintypes.TransactionBody{TokenAddress: config.Param.MainToken, Receivers: receivers},
+	}
+}
+
+// Messages of one sender in a block are checked against the state before
+// the block, the combined spend is rejected when the block is applied.
+func TestActStatus_CombinedSpend(t *testing.T) {
+	status := newTestActStatus(t)
+	from := arry.StringToAddress("A")
+	to := arry.StringToAddress("B")
+	account := chaintypes.NewAccount()
+	account.Address = from
+	account.Tokens.Set(&chaintypes.TokenAccount{Address: config.Param.MainToken.String(), Balance: 10e8})
+	status.setAccount(account)
+
+	msgs := []*chaintypes.Message{newTransfer(from, to, 6e8, 1), newTransfer(from, to, 6e8, 2)}
+	if err := status.CheckMessage(msgs[0], true); err != nil {
+		t.Fatal(err)
+	}
+	if err := status.CheckMessage(msgs[1], false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := status.FromMessage(msgs[0], 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := status.FromMessage(msgs[1], 1); err == nil {
+		t.Fatal("the combined spend exceeds the balance and should be rejected")
+	}
+	if balance := status.Account(from).GetBalance(config.Param.MainToken); balance != 10e8-6e8-msgs[0].Fee() {
+		t.Fatalf("wrong balance %d", balance)
+	}
+}
+
+// The copy is made from the last committed root, changes
+// that are not committed yet are not seen by the copy
+func TestActStatus_CopyCommitted(t *testing.T) {
+	status := newTestActStatus(t)
+	from := arry.StringToAddress("A")
+	account := chaintypes.NewAccount()
+	account.Address = from
+	account.Tokens.Set(&chaintypes.TokenAccount{Address: config.Param.MainToken.String(), Balance: 10e8})
+	status.setAccount(account)
+	if _, err := status.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	account.Tokens.Set(&chaintypes.TokenAccount{Address: config.Param.MainToken.String(), Balance: 5e8})
+	status.setAccount(account)
+	copied, err := status.Copy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance := copied.Account(from).GetBalance(config.Param.MainToken); balance != 10e8 {
+		t.Fatalf("the copy should have the committed balance, got %d", balance)
+	}
+}
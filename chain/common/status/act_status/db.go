@@ -1,6 +1,7 @@
 package act_status
 
 import (
+	"github.com/aiot-network/aiotchain/chain/db/status/act_db"
 	"github.com/aiot-network/aiotchain/tools/arry"
 	"github.com/aiot-network/aiotchain/types"
 )
@@ -13,4 +14,12 @@ type IActDB interface {
 	Account(address arry.Address) types.IAccount
 	SetAccount(account types.IAccount)
 	Nonce(address arry.Address) uint64
+	IndexAccount(account types.IAccount)
+	Holders(token arry.Address, offset, limit uint64) []arry.Address
+	RichList(token arry.Address, limit uint64) []arry.Address
+	HolderCount(token arry.Address) uint64
+	HolderIndexed(root arry.Hash) bool
+	SetHolderIndexed(root arry.Hash)
+	IndexHolders() error
+	Copy() (*act_db.ActDB, error)
 }
@@ -1,6 +1,7 @@
 package dpos_status
 
 import (
+	"github.com/aiot-network/aiotchain/chain/db/status/dpos_db"
 	"github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/tools/arry"
 )
@@ -25,4 +26,5 @@ type IDPosDB interface {
 	CoinBaseCount(cycle uint64, signer arry.Address) uint32
 	AddAddressWork(cycle uint64, super arry.Address, works *types.Works)
 	AddressWork(cycle uint64, super arry.Address) (*types.Works, error)
+	Copy() (*dpos_db.DPosDB, error)
 }
@@ -5,15 +5,18 @@ import (
 	"github.com/aiot-network/aiotchain/chain/db/status/dpos_db"
 	chaintypes "github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/common/dpos"
 	"github.com/aiot-network/aiotchain/common/param"
 	"github.com/aiot-network/aiotchain/tools/arry"
 	"github.com/aiot-network/aiotchain/types"
+	"sync"
 )
 
 const dPosDB = "dpos_db"
 
 type DPosStatus struct {
-	db IDPosDB
+	db    IDPosDB
+	mutex sync.Mutex
 }
 
 func NewDPosStatus() (*DPosStatus, error) {
@@ -26,6 +29,9 @@ func NewDPosStatus() (*DPosStatus, error) {
 }
 
 func (d *DPosStatus) SetTrieRoot(hash arry.Hash) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	return d.db.SetRoot(hash)
 }
 
@@ -34,16 +40,41 @@ func (d *DPosStatus) TrieRoot() arry.Hash {
 }
 
 func (d *DPosStatus) Commit() (arry.Hash, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	return d.db.Commit()
 }
 
+// Copy the dpos status, changes of the copy are discarded
+func (d *DPosStatus) Copy() (dpos.IDPosStatus, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	db, err := d.db.Copy()
+	if err != nil {
+		return nil, err
+	}
+	return &DPosStatus{db: db}, nil
+}
+
 // If the current number of candidates is less than or equal to the
 // number of super nodes, it is not allowed to withdraw candidates.
 func (d *DPosStatus) CheckMessage(msg types.IMessage) error {
 	switch chaintypes.MessageType(msg.Type()) {
 	case chaintypes.Cancel:
 		if d.db.CandidatesCount() <= config.Param.SuperSize {
-			return fmt.Errorf("candidate nodes are already in the minimum number. Cannot cancel the candidate status now, please wait")
+			return chaintypes.NewMsgError(chaintypes.ErrMsgRejected, nil, "candidate nodes are already in the minimum number. Cannot cancel the candidate status now, please wait")
+		}
+	case chaintypes.Batch:
+		body, ok := msg.MsgBody().(*chaintypes.BatchBody)
+		if !ok {
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "incrrect message type")
+		}
+		for _, subMsg := range body.Messages(msg) {
+			if err := d.CheckMessage(subMsg); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -52,7 +83,7 @@ func (d *DPosStatus) CheckMessage(msg types.IMessage) error {
 func (d *DPosStatus) UpdateWork(msg types.IMessage) error {
 	body, ok := msg.MsgBody().(*chaintypes.WorkBody)
 	if !ok {
-		return fmt.Errorf("incrrect message type")
+		return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "incrrect message type")
 	}
 	cycle := msg.Time() / param.CycleInterval
 	for _, work := range body.List {
@@ -6,8 +6,10 @@ import (
 	chaintypes "github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/common/config"
 	"github.com/aiot-network/aiotchain/common/dpos"
+	"github.com/aiot-network/aiotchain/common/status"
 	"github.com/aiot-network/aiotchain/tools/arry"
 	"github.com/aiot-network/aiotchain/types"
+	"sync"
 )
 
 const module = "chain"
@@ -17,6 +19,9 @@ type Status struct {
 	dPosStatus  dpos.IDPosStatus
 	tokenStatus types.ITokenStatus
 	x           int
+	// Held while the roots are reset, committed or copied,
+	// so a copy has the roots of the same block
+	mutex sync.Mutex
 }
 
 func NewStatus(actStatus types.IActStatus, dPosStatus dpos.IDPosStatus, tokenStatus types.ITokenStatus) *Status {
@@ -28,6 +33,9 @@ func NewStatus(actStatus types.IActStatus, dPosStatus dpos.IDPosStatus, tokenSta
 }
 
 func (f *Status) InitRoots(actRoot, dPosRoot, tokenRoot arry.Hash) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
 	if err := f.actStatus.SetTrieRoot(actRoot); err != nil {
 		return err
 	}
@@ -99,6 +107,14 @@ func (f *Status) Change(msgs []types.IMessage, block types.IBlock) error {
 			if err := f.tokenStatus.UpdateToken(msg, block.GetHeight()); err != nil {
 				return err
 			}
+		case chaintypes.TokenAdmin:
+			if err := f.tokenStatus.UpdateToken(msg, block.GetHeight()); err != nil {
+				return err
+			}
+		case chaintypes.TokenPolicy:
+			if err := f.tokenStatus.UpdateToken(msg, block.GetHeight()); err != nil {
+				return err
+			}
 		case chaintypes.Vote:
 			if err := f.dPosStatus.Voter(msg); err != nil {
 				return nil
@@ -118,6 +134,10 @@ func (f *Status) Change(msgs []types.IMessage, block types.IBlock) error {
 			if err := f.dPosStatus.UpdateWork(msg); err != nil {
 				return err
 			}
+		case chaintypes.Batch:
+			if err := f.changeBatch(msg, block.GetHeight()); err != nil {
+				return err
+			}
 		default:
 			return errors.New("wrong message type")
 		}
@@ -131,7 +151,62 @@ func (f *Status) Change(msgs []types.IMessage, block types.IBlock) error {
 	return nil
 }
 
+// Apply all operations of the batch in order, the sender account
+// is changed once by the batch itself. As for the messages of these
+// types outside a batch, a failed DPoS change does not reject the block.
+func (f *Status) changeBatch(msg types.IMessage, height uint64) error {
+	body, ok := msg.MsgBody().(*chaintypes.BatchBody)
+	if !ok {
+		return errors.New("incorrect message type and message body")
+	}
+	for _, subMsg := range body.Messages(msg) {
+		switch chaintypes.MessageType(subMsg.Type()) {
+		case chaintypes.Transaction:
+			if err := f.actStatus.ToMessage(subMsg, height); err != nil {
+				return err
+			}
+		case chaintypes.TokenAdmin, chaintypes.TokenPolicy:
+			if err := f.tokenStatus.UpdateToken(subMsg, height); err != nil {
+				return err
+			}
+		case chaintypes.Vote:
+			f.dPosStatus.Voter(subMsg)
+		case chaintypes.Candidate:
+			f.dPosStatus.AddCandidate(subMsg)
+		case chaintypes.Cancel:
+			f.dPosStatus.CancelCandidate(subMsg)
+		default:
+			return errors.New("wrong message type")
+		}
+	}
+	return nil
+}
+
+// Copy the status at the last committed roots, the copy can apply
+// messages without changing the state of the chain
+func (f *Status) Copy() (status.IStatus, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	actStatus, err := f.actStatus.Copy()
+	if err != nil {
+		return nil, err
+	}
+	dPosStatus, err := f.dPosStatus.Copy()
+	if err != nil {
+		return nil, err
+	}
+	tokenStatus, err := f.tokenStatus.Copy()
+	if err != nil {
+		return nil, err
+	}
+	return NewStatus(actStatus, dPosStatus, tokenStatus), nil
+}
+
 func (f *Status) Commit() (arry.Hash, arry.Hash, arry.Hash, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
 	actRoot, err := f.actStatus.Commit()
 	if err != nil {
 		return arry.Hash{}, arry.Hash{}, arry.Hash{}, err
@@ -207,3 +282,15 @@ func (f *Status) CycleWork(cycle uint64, address arry.Address) (types.IWorks, er
 func (f *Status) Token(address arry.Address) (types.IToken, error) {
 	return f.tokenStatus.Token(address)
 }
+
+func (f *Status) Tokens(offset, limit uint64) ([]types.IToken, uint64) {
+	return f.tokenStatus.Tokens(offset, limit)
+}
+
+func (f *Status) TokenHolders(token arry.Address, offset, limit uint64) ([]types.IAccount, uint64) {
+	return f.actStatus.TokenHolders(token, offset, limit)
+}
+
+func (f *Status) RichList(token arry.Address, limit uint64) []types.IAccount {
+	return f.actStatus.RichList(token, limit)
+}
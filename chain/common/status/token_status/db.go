@@ -1,6 +1,7 @@
 package token_status
 
 import (
+	"github.com/aiot-network/aiotchain/chain/db/status/token_db"
 	"github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/tools/arry"
 )
@@ -11,4 +12,9 @@ type ITokenDB interface {
 	Commit() (arry.Hash, error)
 	Token(addr arry.Address) *types.TokenRecord
 	SetToken(token *types.TokenRecord)
+	Tokens(offset, limit uint64) ([]*types.TokenRecord, uint64)
+	InPolicy(token arry.Address, list types.PolicyList, address arry.Address) bool
+	SetPolicy(token arry.Address, list types.PolicyList, addresses []arry.Address, in bool) uint64
+	PolicyAddresses(token arry.Address, list types.PolicyList) []arry.Address
+	Copy() (*token_db.TokenDB, error)
 }
@@ -1,8 +1,6 @@
 package token_status
 
 import (
-	"errors"
-	"fmt"
 	"github.com/aiot-network/aiotchain/chain/db/status/token_db"
 	chaintypes "github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/common/config"
@@ -27,6 +25,9 @@ func NewTokenStatus() (*TokenStatus, error) {
 }
 
 func (t *TokenStatus) SetTrieRoot(hash arry.Hash) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
 	return t.db.SetRoot(hash)
 }
 
@@ -35,18 +36,54 @@ func (t *TokenStatus) TrieRoot() arry.Hash {
 }
 
 func (t *TokenStatus) Commit() (arry.Hash, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
 	return t.db.Commit()
 }
 
+// Copy the token status, changes of the copy are discarded
+func (t *TokenStatus) Copy() (types.ITokenStatus, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	db, err := t.db.Copy()
+	if err != nil {
+		return nil, err
+	}
+	return &TokenStatus{db: db}, nil
+}
+
 func (t *TokenStatus) CheckMessage(msg types.IMessage) error {
 	t.mutex.RLock()
 	defer t.mutex.RUnlock()
 
+	return t.checkMessage(msg)
+}
+
+func (t *TokenStatus) checkMessage(msg types.IMessage) error {
 	switch chaintypes.MessageType(msg.Type()) {
+	case chaintypes.Transaction:
+		if msg.IsCoinBase() {
+			return nil
+		}
+		body, ok := msg.MsgBody().(*chaintypes.TransactionBody)
+		if !ok {
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "incorrect message type and message body")
+		}
+		if body.TokenAddress.IsEqual(config.Param.MainToken) {
+			return nil
+		}
+		// The policy lists are kept in the token trie, so the transfer is
+		// verified here rather than against the account of the sender
+		token := t.db.Token(body.TokenAddress)
+		if token != nil {
+			return token.CheckTransfer(msg, t.policyLookup(token.Address))
+		}
 	case chaintypes.Token:
 		body, ok := msg.MsgBody().(*chaintypes.TokenBody)
 		if !ok {
-			return errors.New("incorrect message type and message body")
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "incorrect message type and message body")
 		}
 		token := t.db.Token(body.TokenAddress)
 		if token != nil {
@@ -55,7 +92,7 @@ func (t *TokenStatus) CheckMessage(msg types.IMessage) error {
 	case chaintypes.TokenV2:
 		body, ok := msg.MsgBody().(*chaintypes.TokenV2Body)
 		if !ok {
-			return errors.New("incorrect message type and message body")
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "incorrect message type and message body")
 		}
 		token := t.db.Token(body.TokenAddress)
 		if token != nil {
@@ -64,12 +101,42 @@ func (t *TokenStatus) CheckMessage(msg types.IMessage) error {
 	case chaintypes.Redemption:
 		body, ok := msg.MsgBody().(*chaintypes.RedemptionBody)
 		if !ok {
-			return errors.New("incorrect message type and message body")
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "incorrect message type and message body")
 		}
 		token := t.db.Token(body.TokenAddress)
 		if token != nil {
 			return token.CheckRedemption(msg)
 		}
+	case chaintypes.TokenAdmin:
+		body, ok := msg.MsgBody().(*chaintypes.TokenAdminBody)
+		if !ok {
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "incorrect message type and message body")
+		}
+		token := t.db.Token(body.TokenAddress)
+		if token == nil {
+			return chaintypes.NewMsgError(chaintypes.ErrTokenNotFound, chaintypes.ErrDetails{"token": body.TokenAddress.String()}, "token %s is not exist", body.TokenAddress.String())
+		}
+		return token.CheckTokenAdmin(msg)
+	case chaintypes.TokenPolicy:
+		body, ok := msg.MsgBody().(*chaintypes.TokenPolicyBody)
+		if !ok {
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "incorrect message type and message body")
+		}
+		token := t.db.Token(body.TokenAddress)
+		if token == nil {
+			return chaintypes.NewMsgError(chaintypes.ErrTokenNotFound, chaintypes.ErrDetails{"token": body.TokenAddress.String()}, "token %s is not exist", body.TokenAddress.String())
+		}
+		return token.CheckTokenPolicy(msg, t.policyLookup(token.Address))
+	case chaintypes.Batch:
+		body, ok := msg.MsgBody().(*chaintypes.BatchBody)
+		if !ok {
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "incorrect message type and message body")
+		}
+		for _, subMsg := range body.Messages(msg) {
+			if err := t.checkMessage(subMsg); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -83,7 +150,7 @@ func (t *TokenStatus) UpdateToken(msg types.IMessage, height uint64) error {
 	case chaintypes.Token:
 		msgBody, ok := msg.MsgBody().(*chaintypes.TokenBody)
 		if !ok {
-			return errors.New("wrong message type")
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "wrong message type")
 		}
 		record := &chaintypes.Record{
 			Height:   height,
@@ -102,8 +169,10 @@ func (t *TokenStatus) UpdateToken(msg types.IMessage, height uint64) error {
 			token = &chaintypes.TokenRecord{
 				Address:        tokenAddr,
 				Sender:         msg.From(),
+				Owner:          msg.From(),
 				Name:           msgBody.Name,
 				Shorthand:      msgBody.Shorthand,
+				Meta:           msgBody.Meta,
 				IncreaseIssues: msgBody.IncreaseIssues,
 				Records: &chaintypes.RecordList{
 					record,
@@ -114,7 +183,7 @@ func (t *TokenStatus) UpdateToken(msg types.IMessage, height uint64) error {
 	case chaintypes.TokenV2:
 		msgBody, ok := msg.MsgBody().(*chaintypes.TokenV2Body)
 		if !ok {
-			return errors.New("wrong message type")
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "wrong message type")
 		}
 		record := &chaintypes.Record{
 			Height:   height,
@@ -133,8 +202,10 @@ func (t *TokenStatus) UpdateToken(msg types.IMessage, height uint64) error {
 			token = &chaintypes.TokenRecord{
 				Address:        tokenAddr,
 				Sender:         msg.From(),
+				Owner:          msg.From(),
 				Name:           msgBody.Name,
 				Shorthand:      msgBody.Shorthand,
+				Meta:           msgBody.Meta,
 				IncreaseIssues: false,
 				PledgeRate:     msgBody.PledgeRate,
 				PledgeAmount:   msgBody.PledgeAmount(),
@@ -147,7 +218,7 @@ func (t *TokenStatus) UpdateToken(msg types.IMessage, height uint64) error {
 	case chaintypes.Redemption:
 		msgBody, ok := msg.MsgBody().(*chaintypes.RedemptionBody)
 		if !ok {
-			return errors.New("wrong message type")
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "wrong message type")
 		}
 		record := &chaintypes.Record{
 			Height:   height,
@@ -160,24 +231,85 @@ func (t *TokenStatus) UpdateToken(msg types.IMessage, height uint64) error {
 		tokenAddr := msgBody.TokenAddress
 		token := t.db.Token(tokenAddr)
 		if token == nil {
-			return fmt.Errorf("token %s is not exist", msgBody.MsgToken().String())
+			return chaintypes.NewMsgError(chaintypes.ErrTokenNotFound, chaintypes.ErrDetails{"token": msgBody.MsgToken().String()}, "token %s is not exist", msgBody.MsgToken().String())
 		}
 		reAmount := msgBody.RedemptionAmount()
 		if token.PledgeAmount < reAmount {
-			return fmt.Errorf("insufficient to redeem")
+			return chaintypes.NewMsgError(chaintypes.ErrInsufficientBalance, chaintypes.ErrDetails{"token": tokenAddr.String(),
+				"balance": token.PledgeAmount, "required": reAmount}, "insufficient to redeem")
 		}
 		token.PledgeAmount -= reAmount
 		token.IncreaseRecord(record)
 		t.db.SetToken(token)
+	case chaintypes.TokenAdmin:
+		msgBody, ok := msg.MsgBody().(*chaintypes.TokenAdminBody)
+		if !ok {
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "wrong message type")
+		}
+		token := t.db.Token(msgBody.TokenAddress)
+		if token == nil {
+			return chaintypes.NewMsgError(chaintypes.ErrTokenNotFound, chaintypes.ErrDetails{"token": msgBody.TokenAddress.String()}, "token %s is not exist", msgBody.TokenAddress.String())
+		}
+		token.UpdateAdmin(msgBody)
+		t.db.SetToken(token)
+	case chaintypes.TokenPolicy:
+		msgBody, ok := msg.MsgBody().(*chaintypes.TokenPolicyBody)
+		if !ok {
+			return chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "wrong message type")
+		}
+		token := t.db.Token(msgBody.TokenAddress)
+		if token == nil {
+			return chaintypes.NewMsgError(chaintypes.ErrTokenNotFound, chaintypes.ErrDetails{"token": msgBody.TokenAddress.String()}, "token %s is not exist", msgBody.TokenAddress.String())
+		}
+		var changed uint64
+		if list, ok := msgBody.List(); ok {
+			in := msgBody.Action == chaintypes.FreezeAddress || msgBody.Action == chaintypes.AllowAddress
+			changed = t.db.SetPolicy(token.Address, list, msgBody.Addresses, in)
+		}
+		token.UpdatePolicy(msgBody, changed)
+		t.db.SetToken(token)
 	}
 
 	return nil
 }
 
+// Get the tokens in the range and the total number of tokens
+func (t *TokenStatus) Tokens(offset, limit uint64) ([]types.IToken, uint64) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	tokens, count := t.db.Tokens(offset, limit)
+	iTokens := make([]types.IToken, len(tokens))
+	for i, token := range tokens {
+		t.fillPolicy(token)
+		iTokens[i] = token
+	}
+	return iTokens, count
+}
+
 func (t *TokenStatus) Token(address arry.Address) (types.IToken, error) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
 	token := t.db.Token(address)
 	if token == nil {
-		return nil, errors.New("not found")
+		return nil, chaintypes.NewMsgError(chaintypes.ErrTokenNotFound, chaintypes.ErrDetails{"token": address.String()}, "not found")
 	}
+	t.fillPolicy(token)
 	return token, nil
 }
+
+func (t *TokenStatus) policyLookup(token arry.Address) chaintypes.PolicyLookup {
+	return func(list chaintypes.PolicyList, address arry.Address) bool {
+		return t.db.InPolicy(token, list, address)
+	}
+}
+
+// Fill the addresses of the policy lists for display
+func (t *TokenStatus) fillPolicy(token *chaintypes.TokenRecord) {
+	if token.Policy == nil {
+		return
+	}
+	token.Policy.FrozenAddrs = t.db.PolicyAddresses(token.Address, chaintypes.FrozenList)
+	token.Policy.AllowedAddrs = t.db.PolicyAddresses(token.Address, chaintypes.AllowList)
+}
@@ -0,0 +1,166 @@
+package token_status
+
+import (
+	"fmt"
+	"github.com/aiot-network/aiotchain/chain/db/status/token_db"
+	chaintypes "github.com/aiot-network/aiotchain/chain/types"
+	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/common/param"
+	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/tools/crypto/hash"
+	"github.com/aiot-network/aiotchain/types"
+	"testing"
+)
+
+var (
+	testToken = arry.StringToAddress("token")
+	testOwner = arry.StringToAddress("owner")
+)
+
+var testNonce uint64
+
+func newTestMsg(msgType chaintypes.MessageType, from arry.Address, body types.IMessageBody) *chaintypes.Message {
+	testNonce++
+	return &chaintypes.Message{
+		Header: &chaintypes.MsgHeader{
+			Type:  msgType,
+			Hash:  hash.Hash([]byte(fmt.Sprintf("%s-%d", from.String(), testNonce))),
+			From:  from,
+			Nonce: testNonce,
+		},
+		Body: body,
+	}
+}
+
+func newTransfer(from, to string) *chaintypes.Message {
+	receivers := chaintypes.NewReceivers()
+	receivers.Add(arry.StringToAddress(to), 1e8)
+	return newTestMsg(chaintypes.Transaction, arry.StringToAddress(from),
+		&chaintypes.TransactionBody{TokenAddress: testToken, Receivers: receivers})
+}
+
+func newPolicy(action chaintypes.TokenPolicyAction, addrs ...string) *chaintypes.Message {
+	body := &chaintypes.TokenPolicyBody{TokenAddress: testToken, Action: action}
+	for _, addr := range addrs {
+		body.Addresses = append(body.Addresses, arry.StringToAddress(addr))
+	}
+	return newTestMsg(chaintypes.TokenPolicy, testOwner, body)
+}
+
+func newTestTokenStatus(t *testing.T) *TokenStatus {
+	config.Param = param.TestNetParam
+	db, err := token_db.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.SetRoot(arry.Hash{}); err != nil {
+		t.Fatal(err)
+	}
+	status := &TokenStatus{db: db}
+	issue := newTestMsg(chaintypes.Token, testOwner, &chaintypes.TokenBody{
+		TokenAddress:   testToken,
+		Receiver:       testOwner,
+		Name:           "test",
+		Shorthand:      "TEST",
+		IncreaseIssues: true,
+		Amount:         1e10,
+	})
+	if err := status.UpdateToken(issue, 1); err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
+
+func applyPolicy(t *testing.T, status *TokenStatus, msg types.IMessage) {
+	if err := status.CheckMessage(msg); err != nil {
+		t.Fatal(err)
+	}
+	if err := status.UpdateToken(msg, 2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkAllowed(t *testing.T, status *TokenStatus, msg types.IMessage) {
+	if err := status.CheckMessage(msg); err != nil {
+		t.Fatalf("transfer should be allowed, %s", err)
+	}
+}
+
+func checkRestricted(t *testing.T, status *TokenStatus, msg types.IMessage) {
+	err := status.CheckMessage(msg)
+	msgErr, ok := chaintypes.AsMsgError(err)
+	if !ok || msgErr.Code != chaintypes.ErrTokenRestricted {
+		t.Fatalf("transfer should be restricted, got %v", err)
+	}
+}
+
+func TestTokenStatus_FrozenTransfer(t *testing.T) {
+	status := newTestTokenStatus(t)
+	applyPolicy(t, status, newPolicy(chaintypes.FreezeAddress, "B"))
+
+	checkAllowed(t, status, newTransfer("A", "C"))
+	checkRestricted(t, status, newTransfer("B", "C"))
+	checkRestricted(t, status, newTransfer("A", "B"))
+
+	applyPolicy(t, status, newPolicy(chaintypes.UnfreezeAddress, "B"))
+	checkAllowed(t, status, newTransfer("A", "B"))
+
+	if _, count := status.Tokens(0, 10); count != 1 {
+		t.Fatalf("the policy lists should not be listed as tokens, got %d tokens", count)
+	}
+}
+
+func TestTokenStatus_AllowlistTransfer(t *testing.T) {
+	status := newTestTokenStatus(t)
+	applyPolicy(t, status, newPolicy(chaintypes.EnableAllowlist))
+
+	checkRestricted(t, status, newTransfer("owner", "B"))
+	applyPolicy(t, status, newPolicy(chaintypes.AllowAddress, "B", "C"))
+	checkAllowed(t, status, newTransfer("owner", "B"))
+	checkAllowed(t, status, newTransfer("B", "C"))
+	checkRestricted(t, status, newTransfer("B", "D"))
+
+	applyPolicy(t, status, newPolicy(chaintypes.DisallowAddress, "C"))
+	checkRestricted(t, status, newTransfer("B", "C"))
+
+	applyPolicy(t, status, newPolicy(chaintypes.DisableAllowlist))
+	checkAllowed(t, status, newTransfer("B", "D"))
+
+	token, _ := status.Token(testToken)
+	policy := token.(*chaintypes.TokenRecord).Policy
+	if policy.Allowed != 1 || len(policy.AllowedAddrs) != 1 || !policy.AllowedAddrs[0].IsEqual(arry.StringToAddress("B")) {
+		t.Fatalf("wrong allowlist %d %v", policy.Allowed, policy.AllowedAddrs)
+	}
+}
+
+func TestTokenStatus_PausedTransfer(t *testing.T) {
+	status := newTestTokenStatus(t)
+	applyPolicy(t, status, newPolicy(chaintypes.PauseToken))
+
+	checkRestricted(t, status, newTransfer("owner", "B"))
+	checkRestricted(t, status, newTransfer("B", "C"))
+
+	applyPolicy(t, status, newPolicy(chaintypes.UnpauseToken))
+	checkAllowed(t, status, newTransfer("owner", "B"))
+}
+
+func TestTokenStatus_PolicyLimit(t *testing.T) {
+	status := newTestTokenStatus(t)
+	for i := 0; i < chaintypes.MaxPolicyList/chaintypes.MaxPolicyAddress; i++ {
+		addrs := make([]string, chaintypes.MaxPolicyAddress)
+		for j := range addrs {
+			addrs[j] = fmt.Sprintf("%d-%d", i, j)
+		}
+		applyPolicy(t, status, newPolicy(chaintypes.FreezeAddress, addrs...))
+	}
+
+	// Addresses already in the list are not counted
+	if err := status.CheckMessage(newPolicy(chaintypes.FreezeAddress, "0-0")); err != nil {
+		t.Fatal(err)
+	}
+	err := status.CheckMessage(newPolicy(chaintypes.FreezeAddress, "0-0", "new"))
+	if msgErr, ok := chaintypes.AsMsgError(err); !ok || msgErr.Code != chaintypes.ErrPolicyLimit {
+		t.Fatalf("the policy list should be full, got %v", err)
+	}
+}
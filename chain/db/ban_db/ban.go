@@ -0,0 +1,51 @@
+package ban_db
+
+import (
+	"github.com/aiot-network/aiotchain/common/db/base"
+	"github.com/aiot-network/aiotchain/tools/rlp"
+	"github.com/aiot-network/aiotchain/types"
+)
+
+const bucket = "ban"
+
+type BanDB struct {
+	base *base.Base
+}
+
+func Open(path string) (*BanDB, error) {
+	baseDB, err := base.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &BanDB{base: baseDB}, nil
+}
+
+func (b *BanDB) Read() []*types.Ban {
+	bans := b.base.Foreach(bucket)
+	rs := make([]*types.Ban, 0, len(bans))
+	for key, bytes := range bans {
+		ban := new(types.Ban)
+		if err := rlp.DecodeBytes(bytes, ban); err != nil {
+			b.base.Delete([]byte(key))
+			continue
+		}
+		rs = append(rs, ban)
+	}
+	return rs
+}
+
+func (b *BanDB) Save(ban *types.Ban) {
+	bytes, err := rlp.EncodeToBytes(ban)
+	if err != nil {
+		return
+	}
+	b.base.Put(base.Key(bucket, []byte(ban.PeerId)), bytes)
+}
+
+func (b *BanDB) Delete(peerId string) {
+	b.base.Delete(base.Key(bucket, []byte(peerId)))
+}
+
+func (b *BanDB) Close() error {
+	return b.base.Close()
+}
@@ -27,8 +27,12 @@ func Open(path string) (*MsgListDB, error) {
 func (t *MsgListDB) Read() []types.IMessage {
 	msgs := t.base.Foreach(bucket)
 	rs := make([]types.IMessage, 0)
-	for _, bytes := range msgs {
-		rlpMsg, _ := chaintypes.DecodeMessage(bytes)
+	for key, bytes := range msgs {
+		rlpMsg, err := chaintypes.DecodeMessage(bytes)
+		if err != nil {
+			t.base.Delete([]byte(key))
+			continue
+		}
 		rs = append(rs, rlpMsg.ToMessage())
 	}
 	return rs
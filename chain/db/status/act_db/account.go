@@ -1,6 +1,7 @@
 package act_db
 
 import (
+	"encoding/binary"
 	"github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/common/db/base"
 	"github.com/aiot-network/aiotchain/tools/arry"
@@ -8,9 +9,20 @@ import (
 	types2 "github.com/aiot-network/aiotchain/types"
 )
 
+const (
+	holderBucket  = "holder"
+	richBucket    = "rich"
+	holderCount   = "holder_count"
+	holderIndexed = "holder_indexed"
+)
+
 type ActDB struct {
 	base *base.Base
 	trie *trie.Trie
+	// A copied database never writes to the base database
+	copied bool
+	// The root of the trie last set or committed
+	committed arry.Hash
 }
 
 func Open(path string) (*ActDB, error) {
@@ -28,6 +40,7 @@ func (a *ActDB) SetRoot(hash arry.Hash) error {
 		return err
 	}
 	a.trie = t
+	a.committed = hash
 	return nil
 }
 
@@ -36,7 +49,25 @@ func (a *ActDB) Root() arry.Hash {
 }
 
 func (a *ActDB) Commit() (arry.Hash, error) {
-	return a.trie.Commit()
+	if a.copied {
+		return a.trie.Hash(), nil
+	}
+	root, err := a.trie.Commit()
+	if err != nil {
+		return root, err
+	}
+	a.committed = root
+	return root, nil
+}
+
+// Copy the account trie of the last committed root, the
+// changes of the copy will be discarded
+func (a *ActDB) Copy() (*ActDB, error) {
+	t, err := trie.New(a.committed, a.base)
+	if err != nil {
+		return nil, err
+	}
+	return &ActDB{base: a.base, trie: t, copied: true}, nil
 }
 
 func (a *ActDB) Account(address arry.Address) types2.IAccount {
@@ -64,3 +95,126 @@ func (a *ActDB) Nonce(address arry.Address) uint64 {
 func (a *ActDB) Close() error {
 	return a.base.Close()
 }
+
+// Update the holder index with the tokens of the account
+func (a *ActDB) IndexAccount(account types2.IAccount) {
+	if a.copied {
+		return
+	}
+	act, ok := account.(*types.Account)
+	if !ok {
+		return
+	}
+	for _, token := range act.Tokens {
+		a.indexHolder(arry.StringToAddress(token.Address), act.Address, token.Balance+token.LockedIn)
+	}
+}
+
+// Set the total of the holder, addresses without the token are removed
+func (a *ActDB) indexHolder(token, address arry.Address, total uint64) {
+	old, err := a.base.GetFromBucket(holderKey(token), address.Bytes())
+	exist := err == nil && len(old) == 8
+	if exist {
+		oldTotal := binary.BigEndian.Uint64(old)
+		if oldTotal == total {
+			return
+		}
+		a.base.Delete(base.Key(richKey(token), richRank(oldTotal, address)))
+	}
+	if total == 0 {
+		if exist {
+			a.base.Delete(base.Key(holderKey(token), address.Bytes()))
+			a.setHolderCount(token, a.HolderCount(token)-1)
+		}
+		return
+	}
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, total)
+	a.base.PutInBucket(holderKey(token), address.Bytes(), value)
+	a.base.PutInBucket(richKey(token), richRank(total, address), []byte{})
+	if !exist {
+		a.setHolderCount(token, a.HolderCount(token)+1)
+	}
+}
+
+// The holders of the token in address order
+func (a *ActDB) Holders(token arry.Address, offset, limit uint64) []arry.Address {
+	keys, _ := a.base.Range(holderKey(token), offset, limit)
+	addrs := make([]arry.Address, len(keys))
+	for i, key := range keys {
+		addrs[i] = arry.BytesToAddress(key)
+	}
+	return addrs
+}
+
+// The holders of the token with the largest totals
+func (a *ActDB) RichList(token arry.Address, limit uint64) []arry.Address {
+	keys, _ := a.base.Range(richKey(token), 0, limit)
+	addrs := make([]arry.Address, len(keys))
+	for i, key := range keys {
+		addrs[i] = arry.BytesToAddress(key[8:])
+	}
+	return addrs
+}
+
+func (a *ActDB) HolderCount(token arry.Address) uint64 {
+	bytes, err := a.base.GetFromBucket(holderCount, token.Bytes())
+	if err != nil || len(bytes) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(bytes)
+}
+
+func (a *ActDB) setHolderCount(token arry.Address, count uint64) {
+	bytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(bytes, count)
+	a.base.PutInBucket(holderCount, token.Bytes(), bytes)
+}
+
+// The holder index is built for the account trie of the root
+func (a *ActDB) HolderIndexed(root arry.Hash) bool {
+	bytes, err := a.base.Get([]byte(holderIndexed))
+	return err == nil && arry.BytesToHash(bytes).IsEqual(root)
+}
+
+func (a *ActDB) SetHolderIndexed(root arry.Hash) {
+	if a.copied {
+		return
+	}
+	a.base.Put([]byte(holderIndexed), root.Bytes())
+}
+
+// Rebuild the holder index from all accounts in the current account trie
+func (a *ActDB) IndexHolders() error {
+	a.base.Clear(holderBucket)
+	a.base.Clear(richBucket)
+	a.base.Clear(holderCount)
+	iter := trie.NewIterator(a.trie.NodeIterator(nil))
+	for iter.Next() {
+		account, err := types.DecodeAccount(iter.Value)
+		if err != nil {
+			continue
+		}
+		a.IndexAccount(account)
+	}
+	if iter.Err != nil {
+		return iter.Err
+	}
+	a.SetHolderIndexed(a.trie.Hash())
+	return nil
+}
+
+func holderKey(token arry.Address) string {
+	return holderBucket + "-" + token.String()
+}
+
+func richKey(token arry.Address) string {
+	return richBucket + "-" + token.String()
+}
+
+// The rank key sorts the holders by their totals in descending order
+func richRank(total uint64, address arry.Address) []byte {
+	key := make([]byte, 8, 8+arry.AddressLength)
+	binary.BigEndian.PutUint64(key, ^total)
+	return append(key, address.Bytes()...)
+}
@@ -24,6 +24,10 @@ const (
 type DPosDB struct {
 	base *base.Base
 	trie *trie.Trie
+	// A copied database never writes to the base database
+	copied bool
+	// The root of the trie last set or committed
+	committed arry.Hash
 }
 
 func Open(path string) (*DPosDB, error) {
@@ -40,6 +44,7 @@ func (d *DPosDB) SetRoot(hash arry.Hash) error {
 		return err
 	}
 	d.trie = t
+	d.committed = hash
 	return nil
 }
 
@@ -48,7 +53,25 @@ func (d *DPosDB) Root() arry.Hash {
 }
 
 func (d *DPosDB) Commit() (arry.Hash, error) {
-	return d.trie.Commit()
+	if d.copied {
+		return d.trie.Hash(), nil
+	}
+	root, err := d.trie.Commit()
+	if err != nil {
+		return root, err
+	}
+	d.committed = root
+	return root, nil
+}
+
+// Copy the dpos trie of the last committed root, the
+// changes of the copy will be discarded
+func (d *DPosDB) Copy() (*DPosDB, error) {
+	t, err := trie.New(d.committed, d.base)
+	if err != nil {
+		return nil, err
+	}
+	return &DPosDB{base: d.base, trie: t, copied: true}, nil
 }
 
 func (d *DPosDB) Confirmed() (uint64, error) {
@@ -101,6 +124,9 @@ func (d *DPosDB) CycleSupers(cycle uint64) (*types.Supers, error) {
 }
 
 func (d *DPosDB) SaveCycle(cycle uint64, supers *types.Supers) {
+	if d.copied {
+		return
+	}
 	value, _ := rlp.EncodeToBytes(supers)
 	key, _ := rlp.EncodeToBytes(cycle)
 	d.base.PutInBucket(_cycleSupers, key, value)
@@ -10,6 +10,10 @@ import (
 type TokenDB struct {
 	base *base.Base
 	trie *trie.Trie
+	// A copied database never writes to the base database
+	copied bool
+	// The root of the trie last set or committed
+	committed arry.Hash
 }
 
 func Open(path string) (*TokenDB, error) {
@@ -27,11 +31,30 @@ func (t *TokenDB) SetRoot(hash arry.Hash) error {
 		return err
 	}
 	t.trie = tri
+	t.committed = hash
 	return nil
 }
 
 func (t *TokenDB) Commit() (arry.Hash, error) {
-	return t.trie.Commit()
+	if t.copied {
+		return t.trie.Hash(), nil
+	}
+	root, err := t.trie.Commit()
+	if err != nil {
+		return root, err
+	}
+	t.committed = root
+	return root, nil
+}
+
+// Copy the token trie of the last committed root, the
+// changes of the copy will be discarded
+func (t *TokenDB) Copy() (*TokenDB, error) {
+	tri, err := trie.New(t.committed, t.base)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenDB{base: t.base, trie: tri, copied: true}, nil
 }
 
 func (t *TokenDB) Root() arry.Hash {
@@ -54,3 +77,68 @@ func (t *TokenDB) Token(address arry.Address) *types.TokenRecord {
 func (t *TokenDB) SetToken(token *types.TokenRecord) {
 	t.trie.Update(token.Address.Bytes(), token.Bytes())
 }
+
+// Traverse the token trie, return the tokens in the range and the total number of tokens
+func (t *TokenDB) Tokens(offset, limit uint64) ([]*types.TokenRecord, uint64) {
+	var count uint64
+	tokens := make([]*types.TokenRecord, 0)
+	iter := trie.NewIterator(t.trie.NodeIterator(nil))
+	for iter.Next() {
+		// Skip the addresses of the policy lists
+		if len(iter.Key) != arry.AddressLength {
+			continue
+		}
+		count++
+		if count <= offset || uint64(len(tokens)) >= limit {
+			continue
+		}
+		token, err := types.DecodeToken(iter.Value)
+		if err != nil {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, count
+}
+
+func (t *TokenDB) InPolicy(token arry.Address, list types.PolicyList, address arry.Address) bool {
+	return len(t.trie.Get(policyKey(token, list, address))) != 0
+}
+
+// Add the addresses to or remove them from the policy list of the
+// token, return the number of addresses actually changed
+func (t *TokenDB) SetPolicy(token arry.Address, list types.PolicyList, addresses []arry.Address, in bool) uint64 {
+	var changed uint64
+	for _, address := range addresses {
+		if t.InPolicy(token, list, address) == in {
+			continue
+		}
+		if in {
+			t.trie.Update(policyKey(token, list, address), []byte{1})
+		} else {
+			t.trie.Delete(policyKey(token, list, address))
+		}
+		changed++
+	}
+	return changed
+}
+
+// All addresses in the policy list of the token
+func (t *TokenDB) PolicyAddresses(token arry.Address, list types.PolicyList) []arry.Address {
+	prefix := policyKey(token, list, arry.Address{})[:arry.AddressLength+1]
+	addrs := make([]arry.Address, 0)
+	iter := trie.NewIterator(t.trie.PrefixIterator(prefix))
+	for iter.Next() {
+		addrs = append(addrs, arry.BytesToAddress(iter.Key[len(prefix):]))
+	}
+	return addrs
+}
+
+// The addresses of the policy lists are kept under the token address,
+// their keys are longer than the keys of the tokens
+func policyKey(token arry.Address, list types.PolicyList, address arry.Address) []byte {
+	key := make([]byte, 0, 2*arry.AddressLength+1)
+	key = append(key, token.Bytes()...)
+	key = append(key, byte(list))
+	return append(key, address.Bytes()...)
+}
@@ -0,0 +1,196 @@
+package request
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/aiot-network/aiotchain/common/param"
+	"io"
+)
+
+// The binary frame of the peer request protocol. A request frame is
+//
+//	magic(1) | version(1) | method | body
+//
+// and a response frame is
+//
+//	magic(1) | version(1) | code(1) | message | body
+//
+// where every variable field is prefixed with its length as 4 bytes big endian.
+// The JSON encoded messages always start with '{', so the first byte tells the
+// two formats apart and old nodes that do not support frames can still be served.
+const (
+	frameMagic   byte = 0xA5
+	frameVersion byte = 1
+)
+
+const (
+	maxMethodLength  = 64
+	maxMessageLength = 1024
+	smallBodyLimit   = 1024 * 4
+	// Enough for the maximum number of inventories of an announcement
+	inventoryLimit = 1024 * 32
+)
+
+// Errors of malformed frames, the peer violates the protocol
+var errProtocol = errors.New("protocol violation")
+
+var (
+	errFrameMagic   = fmt.Errorf("%w: not a binary frame", errProtocol)
+	errFrameVersion = fmt.Errorf("%w: unsupported frame version", errProtocol)
+	errUnknown      = fmt.Errorf("%w: unknown method", errProtocol)
+)
+
+// The maximum body sizes of the requests of each method
+var requestLimits = map[Method]uint32{
+	lastHeight:   smallBodyLimit,
+	sendMsg:      param.MaxReadBytes * 100,
+	sendBlock:    param.MaxReqBytes,
+	getBlocks:    smallBodyLimit,
+	getBlock:     smallBodyLimit,
+	isEqual:      smallBodyLimit,
+	localInfo:    smallBodyLimit,
+	handshake:    smallBodyLimit,
+	announce:     inventoryLimit,
+	getMessage:   smallBodyLimit,
+	getBlockHash: smallBodyLimit,
+	sendCompact:  param.MaxReqBytes,
+	getBlockMsgs: param.MaxReqBytes,
+	getHeaders:   smallBodyLimit,
+}
+
+// The maximum body sizes of the responses of each method
+var responseLimits = map[Method]uint32{
+	lastHeight:   smallBodyLimit,
+	sendMsg:      smallBodyLimit,
+	sendBlock:    smallBodyLimit,
+	getBlocks:    param.MaxReqBytes,
+	getBlock:     param.MaxReqBytes,
+	isEqual:      smallBodyLimit,
+	localInfo:    smallBodyLimit,
+	handshake:    smallBodyLimit,
+	announce:     smallBodyLimit,
+	getMessage:   param.MaxReadBytes * 100,
+	getBlockHash: param.MaxReqBytes,
+	sendCompact:  smallBodyLimit,
+	getBlockMsgs: param.MaxReqBytes,
+	getHeaders:   param.MaxReqBytes,
+}
+
+// Whether the buffered stream starts with a binary frame
+func isFrame(reader *bufio.Reader) bool {
+	first, err := reader.Peek(1)
+	return err == nil && first[0] == frameMagic
+}
+
+func writeRequestFrame(w io.Writer, request *Request) error {
+	bytes := appendRequest([]byte{frameMagic, frameVersion}, request)
+	_, err := w.Write(bytes)
+	return err
+}
+
+func readRequestFrame(r io.Reader) (*Request, error) {
+	if err := readFrameHeader(r); err != nil {
+		return nil, err
+	}
+	return readRequest(r)
+}
+
+func appendRequest(bytes []byte, request *Request) []byte {
+	bytes = appendField(bytes, []byte(request.Method))
+	return appendField(bytes, request.Body)
+}
+
+func readRequest(r io.Reader) (*Request, error) {
+	method, err := readField(r, maxMethodLength)
+	if err != nil {
+		return nil, err
+	}
+	limit, ok := requestLimits[Method(method)]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", errUnknown, string(method))
+	}
+	body, err := readField(r, limit)
+	if err != nil {
+		return nil, err
+	}
+	return NewRequest(Method(method), body), nil
+}
+
+func writeResponseFrame(w io.Writer, response *Response) error {
+	bytes := appendResponse([]byte{frameMagic, frameVersion}, response)
+	_, err := w.Write(bytes)
+	return err
+}
+
+// Read the response of the request method, the body size is limited by the method
+func readResponseFrame(r io.Reader, method Method) (*Response, error) {
+	if err := readFrameHeader(r); err != nil {
+		return nil, err
+	}
+	limit, ok := responseLimits[method]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", errUnknown, string(method))
+	}
+	return readResponse(r, limit)
+}
+
+func appendResponse(bytes []byte, response *Response) []byte {
+	bytes = append(bytes, byte(response.Code))
+	bytes = appendField(bytes, []byte(response.Message))
+	return appendField(bytes, response.Body)
+}
+
+func readResponse(r io.Reader, limit uint32) (*Response, error) {
+	var code [1]byte
+	if _, err := io.ReadFull(r, code[:]); err != nil {
+		return nil, err
+	}
+	message, err := readField(r, maxMessageLength)
+	if err != nil {
+		return nil, err
+	}
+	body, err := readField(r, limit)
+	if err != nil {
+		return nil, err
+	}
+	return NewResponse(Code(code[0]), string(message), body), nil
+}
+
+func readFrameHeader(r io.Reader) error {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+	if header[0] != frameMagic {
+		return errFrameMagic
+	}
+	if header[1] > frameVersion {
+		return fmt.Errorf("%w %d", errFrameVersion, header[1])
+	}
+	return nil
+}
+
+func appendField(bytes []byte, field []byte) []byte {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(field)))
+	bytes = append(bytes, length[:]...)
+	return append(bytes, field...)
+}
+
+func readField(r io.Reader, limit uint32) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size > limit {
+		return nil, fmt.Errorf("%w: the field size %d exceeds the limit %d", errProtocol, size, limit)
+	}
+	field := make([]byte, size)
+	if _, err := io.ReadFull(r, field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
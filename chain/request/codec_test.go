@@ -0,0 +1,75 @@
+package request
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestRequestFrame(t *testing.T) {
+	body := append([]byte(endFlag), 0, 1, 2)
+	buf := new(bytes.Buffer)
+	if err := writeRequestFrame(buf, NewRequest(sendBlock, body)); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(buf)
+	if !isFrame(reader) {
+		t.Fatalf("the frame is not detected")
+	}
+	request, err := readRequestFrame(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Method != sendBlock || !bytes.Equal(request.Body, body) {
+		t.Fatalf("got %s %v, expect %s %v", request.Method, request.Body, sendBlock, body)
+	}
+}
+
+func TestResponseFrame(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := writeResponseFrame(buf, NewResponse(Failed, "block not exist", []byte{1, 2})); err != nil {
+		t.Fatal(err)
+	}
+	response, err := readResponseFrame(buf, getBlocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Code != Failed || response.Message != "block not exist" || !bytes.Equal(response.Body, []byte{1, 2}) {
+		t.Fatalf("wrong response %v", response)
+	}
+}
+
+func TestFrameLimit(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := writeRequestFrame(buf, NewRequest(lastHeight, make([]byte, smallBodyLimit+1))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readRequestFrame(buf); err == nil {
+		t.Fatalf("the body exceeding the limit of the method should be rejected")
+	}
+
+	// The declared length is checked before the body is read
+	frame := []byte{frameMagic, frameVersion}
+	frame = appendField(frame, []byte(sendMsg))
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], requestLimits[sendMsg]+1)
+	if _, err := readRequestFrame(bytes.NewReader(append(frame, length[:]...))); err == nil {
+		t.Fatalf("the declared length exceeding the limit should be rejected")
+	}
+}
+
+func TestFrameVersion(t *testing.T) {
+	frame := []byte{frameMagic, frameVersion + 1}
+	frame = appendField(frame, []byte(lastHeight))
+	frame = appendField(frame, nil)
+	if _, err := readRequestFrame(bytes.NewReader(frame)); err == nil {
+		t.Fatalf("the newer frame version should be rejected")
+	}
+}
+
+func TestLegacyRequest(t *testing.T) {
+	if isFrame(bufio.NewReader(bytes.NewReader([]byte(`{"method":"lastHeight"}`)))) {
+		t.Fatalf("the JSON request is detected as a frame")
+	}
+}
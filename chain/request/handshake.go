@@ -0,0 +1,51 @@
+package request
+
+import (
+	"fmt"
+	"github.com/aiot-network/aiotchain/common/config"
+	request2 "github.com/aiot-network/aiotchain/service/request"
+	"github.com/aiot-network/aiotchain/types"
+	"github.com/aiot-network/aiotchain/version"
+)
+
+const (
+	// Version of the peer request protocol
+	protocolVersion uint32 = 1
+	// Peers with a lower protocol version are rejected
+	minProtocolVersion uint32 = 1
+)
+
+// Capabilities supported by the local node
+const capabilities = types.CapSync | types.CapRelay | types.CapLocalInfo |
+	types.CapFraming | types.CapSession | types.CapInventory | types.CapCompact |
+	types.CapHeaders
+
+func (r *RequestHandler) localHandshake() (*types.Handshake, error) {
+	genesis, err := r.chain.GetHeaderHeight(0)
+	if err != nil {
+		return nil, fmt.Errorf("no genesis block, %s", err.Error())
+	}
+	return &types.Handshake{
+		Protocol:     protocolVersion,
+		Version:      version.StringifySingleLine(config.Param.App),
+		Network:      config.Param.P2pParam.NetWork,
+		Genesis:      genesis.GetHash(),
+		Height:       r.chain.LastHeight(),
+		Confirmed:    r.chain.LastConfirmed(),
+		Capabilities: capabilities,
+	}, nil
+}
+
+// Check whether the peer is on the same chain and can communicate with the local node
+func checkHandshake(local, remote *types.Handshake) error {
+	if remote.Protocol < minProtocolVersion {
+		return fmt.Errorf("%w %d, at least %d is required", request2.Err_Protocol, remote.Protocol, minProtocolVersion)
+	}
+	if remote.Network != local.Network {
+		return fmt.Errorf("%w %s", request2.Err_Network, remote.Network)
+	}
+	if !remote.Genesis.IsEqual(local.Genesis) {
+		return fmt.Errorf("%w %s", request2.Err_Genesis, remote.Genesis.String())
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/common/param"
+	request2 "github.com/aiot-network/aiotchain/service/request"
+	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/tools/rlp"
+	"github.com/aiot-network/aiotchain/types"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"net"
+	"testing"
+)
+
+func TestCheckHandshake(t *testing.T) {
+	local := &types.Handshake{
+		Protocol: protocolVersion,
+		Network:  "testAIOT_NETWORK",
+		Genesis:  arry.BytesToHash([]byte("genesis")),
+	}
+	tests := []struct {
+		name   string
+		modify func(h *types.Handshake)
+		err    error
+	}{
+		{"same chain", func(h *types.Handshake) {}, nil},
+		{"old protocol", func(h *types.Handshake) { h.Protocol = minProtocolVersion - 1 }, request2.Err_Protocol},
+		{"other network", func(h *types.Handshake) { h.Network = "mainAIOT_NETWORK" }, request2.Err_Network},
+		{"other genesis", func(h *types.Handshake) { h.Genesis = arry.BytesToHash([]byte("other")) }, request2.Err_Genesis},
+	}
+	for _, test := range tests {
+		remote := *local
+		test.modify(&remote)
+		if err := checkHandshake(local, &remote); !errors.Is(err, test.err) {
+			t.Errorf("%s: got error %v, expect %v", test.name, err, test.err)
+		}
+	}
+}
+
+// Connect to a peer that reads the handshake and answers it with the bytes,
+// the stream is closed without an answer if there are no bytes
+func handshakeConn(r *RequestHandler, answer []byte) *types.Conn {
+	return &types.Conn{Create: func(id peer.ID) (network.Stream, error) {
+		client, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			r.read(server)
+			if len(answer) != 0 {
+				server.Write(answer)
+			}
+		}()
+		return &pipeStream{pipe: client}, nil
+	}}
+}
+
+func legacyResponse(response *Response) []byte {
+	bytes, _ := json.Marshal(response)
+	return append(bytes, []byte(endFlag)...)
+}
+
+func TestHandshake(t *testing.T) {
+	config.Param = param.TestNetParam
+	r := NewRequestHandler(nil)
+	local := &types.Handshake{
+		Protocol:     protocolVersion,
+		Network:      "testAIOT_NETWORK",
+		Genesis:      arry.BytesToHash([]byte("genesis")),
+		Capabilities: capabilities,
+	}
+	body, _ := rlp.EncodeToBytes(local)
+
+	var penalties []string
+	r.RegisterPeerPenalty(func(id string, score int) { penalties = append(penalties, id) })
+
+	conn := handshakeConn(r, legacyResponse(NewResponse(Success, "", body)))
+	rs, err := r.handshake(conn, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.IsLegacy() || !conn.Framed || !conn.Multiplexed {
+		t.Fatalf("the capabilities of the peer are not negotiated")
+	}
+
+	conn = handshakeConn(r, nil)
+	conn.PeerId = peer.ID("legacy")
+	rs, err = r.handshake(conn, local)
+	if err != nil {
+		t.Fatalf("a peer without the handshake should be kept, got %s", err)
+	}
+	if !rs.IsLegacy() || rs.Capabilities != 0 || conn.Framed || conn.Multiplexed {
+		t.Fatalf("a peer without the handshake should be in legacy mode")
+	}
+	if completed, ok := r.handshakes[conn.PeerId]; !ok || !completed {
+		t.Fatalf("the legacy peer should be recorded")
+	}
+
+	conn = handshakeConn(r, []byte("garbage"))
+	conn.PeerId = peer.ID("garbage")
+	if _, err = r.handshake(conn, local); err == nil {
+		t.Fatalf("a garbage answer should fail the handshake")
+	}
+	if completed, ok := r.handshakes[conn.PeerId]; !ok || completed {
+		t.Fatalf("the peer answering garbage should not be taken for a legacy peer")
+	}
+	if len(penalties) != 1 || penalties[0] != conn.PeerId.String() {
+		t.Fatalf("the peer answering garbage should be penalized, got %v", penalties)
+	}
+
+	conn = handshakeConn(r, legacyResponse(NewResponse(Failed, request2.Err_Genesis.Error(), nil)))
+	if _, err = r.handshake(conn, local); err == nil {
+		t.Fatalf("the rejected handshake should fail")
+	}
+
+	conn = &types.Conn{Create: func(id peer.ID) (network.Stream, error) {
+		return nil, errors.New("no route")
+	}}
+	if _, err = r.handshake(conn, local); !errors.Is(err, request2.Err_PeerClosed) {
+		t.Fatalf("an unreachable peer should fail, got %v", err)
+	}
+}
+
+type peerConn struct {
+	network.Conn
+	id peer.ID
+}
+
+func (c *peerConn) RemotePeer() peer.ID { return c.id }
+
+type peerStream struct {
+	*pipeStream
+	conn *peerConn
+}
+
+func (s *peerStream) Conn() network.Conn { return s.conn }
+
+// Send an announcement without inventories from the peer and read the response code
+func announceFrom(t *testing.T, r *RequestHandler, id peer.ID) Code {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	body, _ := rlp.EncodeToBytes([]*types.Inventory{})
+	stream := &peerStream{pipeStream: &pipeStream{pipe: server}, conn: &peerConn{id: id}}
+	r.readyCh <- &ReqStream{request: NewRequest(announce, body), stream: stream}
+	response, err := r.UnmarshalResponse(&pipeStream{pipe: client}, announce, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.Code
+}
+
+func TestDealRequest_Handshake(t *testing.T) {
+	config.Param = param.TestNetParam
+	r := NewRequestHandler(nil)
+	go r.dealRequest()
+	defer close(r.readyCh)
+
+	id := peer.ID("peer")
+	if code := announceFrom(t, r, id); code != Success {
+		t.Fatalf("the request of a peer without the handshake should be served in legacy mode")
+	}
+	r.setHandshake(id, false)
+	if code := announceFrom(t, r, id); code != Failed {
+		t.Fatalf("the request of a peer with a rejected handshake should be refused")
+	}
+	r.Disconnect(&types.Conn{PeerId: id})
+	if code := announceFrom(t, r, id); code != Success {
+		t.Fatalf("the rejected handshake should be forgotten on disconnection")
+	}
+}
@@ -1,15 +1,25 @@
 package request
 
 import (
+	"fmt"
 	"github.com/aiot-network/aiotchain/chain/types"
 	request2 "github.com/aiot-network/aiotchain/service/request"
+	"github.com/aiot-network/aiotchain/tools/arry"
 	"github.com/aiot-network/aiotchain/tools/rlp"
+	types2 "github.com/aiot-network/aiotchain/types"
+	"github.com/libp2p/go-libp2p-core/peer"
 )
 
 const (
 	maxSyncCount = 1000
 	minSyncCount = 1
 	maxSyncBytes = 1024 * 1024 * 2
+	// Maximum number of inventories in one announcement
+	maxInventories = 500
+	// Maximum number of headers in one response
+	maxHeaderCount = 2000
+	// Maximum number of message indexes in one request
+	maxMsgIndexes = 2000
 )
 
 func (r *RequestHandler) respLastHeight(req *ReqStream) (*Response, error) {
@@ -34,8 +44,9 @@ func (r *RequestHandler) respSendMsg(req *ReqStream) (*Response, error) {
 	if err != nil {
 		code = Failed
 		message = err.Error()
+		r.punish(req.stream.Conn().RemotePeer(), request2.PenaltyProtocol)
 	} else {
-		r.receiveMessage(msg.ToMessage())
+		r.receiveMessage(msg.ToMessage(), req.stream.Conn().RemotePeer().String())
 	}
 	response := NewResponse(code, message, body)
 	return response, nil
@@ -49,8 +60,10 @@ func (r *RequestHandler) respSendBlock(req *ReqStream) (*Response, error) {
 	if err != nil {
 		code = Failed
 		message = err.Error()
+		r.punish(req.stream.Conn().RemotePeer(), request2.PenaltyProtocol)
+	} else {
+		r.receiveBlock(rlpBlock.ToBlock(), req.stream.Conn().RemotePeer().String())
 	}
-	r.receiveBlock(rlpBlock.ToBlock())
 	response := NewResponse(code, message, body)
 	return response, nil
 }
@@ -143,3 +156,170 @@ func (r *RequestHandler) respLocalInfo(req *ReqStream) (*Response, error) {
 	}
 	return NewResponse(code, message, body), nil
 }
+
+func (r *RequestHandler) respHandshake(req *ReqStream) (*Response, error) {
+	var message string
+	var body []byte
+	code := Success
+
+	local, err := r.localHandshake()
+	if err != nil {
+		return NewResponse(Failed, err.Error(), body), nil
+	}
+	id := req.stream.Conn().RemotePeer()
+	var remote *types2.Handshake
+	if err := rlp.DecodeBytes(req.request.Body, &remote); err != nil {
+		r.setHandshake(id, false)
+		return NewResponse(Failed, err.Error(), body), nil
+	}
+	if err := checkHandshake(local, remote); err != nil {
+		r.setHandshake(id, false)
+		return NewResponse(Failed, err.Error(), body), nil
+	}
+	if r.inboundPeer != nil {
+		if err := r.inboundPeer(&peer.AddrInfo{ID: id}, remote); err != nil {
+			r.setHandshake(id, false)
+			return NewResponse(Failed, err.Error(), body), nil
+		}
+	}
+	r.setHandshake(id, true)
+	body, _ = rlp.EncodeToBytes(local)
+	return NewResponse(code, message, body), nil
+}
+
+// Requests of peers with a rejected handshake are refused
+func (r *RequestHandler) respNoHandshake(req *ReqStream) (*Response, error) {
+	return NewResponse(Failed, request2.Err_NoHandshake.Error(), nil), nil
+}
+
+func (r *RequestHandler) respAnnounce(req *ReqStream) (*Response, error) {
+	var message string
+	var body []byte
+	code := Success
+	var invs []*types2.Inventory
+	if err := rlp.DecodeBytes(req.request.Body, &invs); err != nil {
+		code = Failed
+		message = err.Error()
+		r.punish(req.stream.Conn().RemotePeer(), request2.PenaltyProtocol)
+	} else if len(invs) > maxInventories {
+		code = Failed
+		message = "too many inventories"
+		r.punish(req.stream.Conn().RemotePeer(), request2.PenaltyProtocol)
+	} else if r.receiveInv != nil {
+		r.receiveInv(invs, req.stream.Conn().RemotePeer().String())
+	}
+	return NewResponse(code, message, body), nil
+}
+
+func (r *RequestHandler) respGetMessage(req *ReqStream) (*Response, error) {
+	var message string
+	var body []byte
+	code := Success
+	var hash arry.Hash
+	if err := rlp.DecodeBytes(req.request.Body, &hash); err != nil {
+		return NewResponse(Failed, err.Error(), body), nil
+	}
+	if r.getMessage == nil {
+		return NewResponse(Failed, request2.Err_MsgNotFound.Error(), body), nil
+	}
+	msg, ok := r.getMessage(hash)
+	if !ok {
+		return NewResponse(Failed, request2.Err_MsgNotFound.Error(), body), nil
+	}
+	body = msg.ToRlp().Bytes()
+	return NewResponse(code, message, body), nil
+}
+
+func (r *RequestHandler) respGetBlockHash(req *ReqStream) (*Response, error) {
+	var message string
+	var body []byte
+	code := Success
+	var hash arry.Hash
+	if err := rlp.DecodeBytes(req.request.Body, &hash); err != nil {
+		return NewResponse(Failed, err.Error(), body), nil
+	}
+	block, err := r.chain.GetRlpBlockHash(hash)
+	if err != nil {
+		return NewResponse(Failed, request2.Err_BlockNotFound.Error(), body), nil
+	}
+	body = block.(*types.RlpBlock).Bytes()
+	return NewResponse(code, message, body), nil
+}
+
+func (r *RequestHandler) respSendCompact(req *ReqStream) (*Response, error) {
+	var message string
+	var body []byte
+	code := Success
+	compact, err := types.DecodeCompactBlock(req.request.Body)
+	if err != nil {
+		code = Failed
+		message = err.Error()
+		r.punish(req.stream.Conn().RemotePeer(), request2.PenaltyProtocol)
+	} else if r.receiveCompact != nil {
+		r.receiveCompact(compact, req.stream.Conn().RemotePeer().String())
+	}
+	return NewResponse(code, message, body), nil
+}
+
+func (r *RequestHandler) respGetBlockMsgs(req *ReqStream) (*Response, error) {
+	var message string
+	var body []byte
+	code := Success
+	var params *blockMsgsParams
+	if err := rlp.DecodeBytes(req.request.Body, &params); err != nil {
+		return NewResponse(Failed, err.Error(), body), nil
+	} else if len(params.Indexes) > maxMsgIndexes {
+		return NewResponse(Failed, "too many indexes", body), nil
+	}
+	block, err := r.chain.GetRlpBlockHash(params.Hash)
+	if err != nil {
+		return NewResponse(Failed, request2.Err_BlockNotFound.Error(), body), nil
+	}
+	msgs := block.(*types.RlpBlock).RlpBody.MsgList()
+	if len(params.Indexes) > len(msgs) {
+		return NewResponse(Failed, "too many indexes", body), nil
+	}
+	requested := make(map[uint32]bool, len(params.Indexes))
+	rlpMsgs := make([]*types.RlpMessage, 0, len(params.Indexes))
+	for _, index := range params.Indexes {
+		if int(index) >= len(msgs) {
+			return NewResponse(Failed, fmt.Sprintf("index %d out of range", index), body), nil
+		} else if requested[index] {
+			return NewResponse(Failed, fmt.Sprintf("index %d is repeated", index), body), nil
+		}
+		requested[index] = true
+		rlpMsgs = append(rlpMsgs, msgs[index])
+	}
+	body = types.EncodeRlpMessages(rlpMsgs)
+	return NewResponse(code, message, body), nil
+}
+
+func (r *RequestHandler) respGetHeaders(req *ReqStream) (*Response, error) {
+	var message string
+	var body []byte
+	code := Success
+	var params []uint64
+	if err := rlp.DecodeBytes(req.request.Body, &params); err != nil {
+		return NewResponse(Failed, err.Error(), body), nil
+	} else if len(params) != 2 {
+		return NewResponse(Failed, "wrong params", body), nil
+	}
+	height, count := params[0], params[1]
+	if count > maxHeaderCount {
+		count = maxHeaderCount
+	}
+	lastHeight := r.chain.LastHeight()
+	if height > lastHeight {
+		return NewResponse(Failed, request2.Err_BlockNotFound.Error(), body), nil
+	}
+	headers := make([]*types.Header, 0, count)
+	for ; height <= lastHeight && uint64(len(headers)) < count; height++ {
+		header, err := r.chain.GetHeaderHeight(height)
+		if err != nil {
+			return NewResponse(Failed, err.Error(), body), nil
+		}
+		headers = append(headers, header.(*types.Header))
+	}
+	body, _ = rlp.EncodeToBytes(headers)
+	return NewResponse(code, message, body), nil
+}
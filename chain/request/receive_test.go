@@ -0,0 +1,78 @@
+package request
+
+import (
+	"errors"
+	"github.com/aiot-network/aiotchain/chain/types"
+	"github.com/aiot-network/aiotchain/common/blockchain"
+	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/tools/rlp"
+	types2 "github.com/aiot-network/aiotchain/types"
+	"testing"
+)
+
+// A chain that only has the block of the hash
+type blockChain struct {
+	blockchain.IChain
+	hash  arry.Hash
+	block *types.RlpBlock
+}
+
+func (c *blockChain) GetRlpBlockHash(hash arry.Hash) (types2.IRlpBlock, error) {
+	if !hash.IsEqual(c.hash) {
+		return nil, errors.New("not found")
+	}
+	return c.block, nil
+}
+
+func TestRespGetBlockMsgs(t *testing.T) {
+	hash := arry.BytesToHash([]byte("block"))
+	msgs := make([]*types.RlpMessage, 4)
+	for i := range msgs {
+		msgs[i] = &types.RlpMessage{MsgHeader: &types.MsgHeader{Signature: &types.Signature{}}, MsgBody: []byte{byte(i)}}
+	}
+	r := NewRequestHandler(&blockChain{hash: hash, block: &types.RlpBlock{
+		RlpHeader: &types.Header{},
+		RlpBody:   &types.RlpBody{Msgs: msgs},
+	}})
+	tooMany := make([]uint32, maxMsgIndexes+1)
+	for i := range tooMany {
+		tooMany[i] = uint32(i)
+	}
+	tests := []struct {
+		name    string
+		indexes []uint32
+		code    Code
+	}{
+		{"some messages", []uint32{3, 1}, Success},
+		{"all messages", []uint32{0, 1, 2, 3}, Success},
+		{"out of range", []uint32{4}, Failed},
+		{"repeated index", []uint32{1, 2, 1}, Failed},
+		{"more than the block", []uint32{0, 1, 2, 3, 0}, Failed},
+		{"more than the cap", tooMany, Failed},
+	}
+	for _, test := range tests {
+		body, _ := rlp.EncodeToBytes(&blockMsgsParams{Hash: hash, Indexes: test.indexes})
+		response, err := r.respGetBlockMsgs(&ReqStream{request: NewRequest(getBlockMsgs, body)})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if response.Code != test.code {
+			t.Fatalf("%s: expect code %d, got %d %s", test.name, test.code, response.Code, response.Message)
+		}
+		if response.Code != Success {
+			continue
+		}
+		found, err := types.DecodeRlpMessages(response.Body)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(found) != len(test.indexes) {
+			t.Fatalf("%s: expect %d messages, got %d", test.name, len(test.indexes), len(found))
+		}
+		for i, index := range test.indexes {
+			if found[i].MsgBody[0] != byte(index) {
+				t.Fatalf("%s: message %d should be the message %d of the block", test.name, i, index)
+			}
+		}
+	}
+}
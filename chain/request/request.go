@@ -1,14 +1,22 @@
 package request
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/aiot-network/aiotchain/common/blockchain"
 	"github.com/aiot-network/aiotchain/common/config"
 	"github.com/aiot-network/aiotchain/common/param"
+	request2 "github.com/aiot-network/aiotchain/service/request"
+	"github.com/aiot-network/aiotchain/tools/arry"
 	log "github.com/aiot-network/aiotchain/tools/log/log15"
 	"github.com/aiot-network/aiotchain/types"
+	"github.com/libp2p/go-libp2p-core/mux"
 	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"io"
 	"sync"
 	"time"
 )
@@ -18,18 +26,44 @@ const endLength = len(endFlag)
 
 const module = "request"
 
+var (
+	// The peer could not be reached, no request was sent
+	errOpenStream = errors.New("failed to open the stream")
+	// The peer closed or reset the stream without answering
+	errNoResponse = errors.New("stream closed without a response")
+)
+
 type request func(*ReqStream) (*Response, error)
 
 type ReqStream struct {
 	request *Request
 	stream  network.Stream
+	// The request is a binary frame, the response is written in the same format
+	framed bool
+	// The session of the request if it is multiplexed
+	session *session
+	id      uint32
+}
+
+func NewReqStream(r *Request, stream network.Stream, framed bool) *ReqStream {
+	return &ReqStream{request: r, stream: stream, framed: framed}
 }
 
-func NewReqStream(r *Request, stream network.Stream) *ReqStream {
-	return &ReqStream{r, stream}
+func (r *ReqStream) write(response *Response) error {
+	if r.session != nil {
+		return r.session.respond(r.id, response)
+	}
+	return responseStream(response, r.stream, r.framed)
 }
 
+// A session request frees its slot, a single request stream is
+// closed after the requester has read the response
 func (r *ReqStream) Close() {
+	if r.session != nil {
+		r.session.release()
+		return
+	}
+	waitClosed(r.stream)
 	r.stream.Reset()
 	r.stream.Close()
 }
@@ -38,15 +72,27 @@ type RequestHandler struct {
 	chain          blockchain.IChain
 	readyCh        chan *ReqStream
 	bytesPool      sync.Pool
-	receiveBlock   func(block types.IBlock) error
-	receiveMessage func(msg types.IMessage) error
+	receiveBlock   func(block types.IBlock, peerId string) error
+	receiveMessage func(msg types.IMessage, peerId string) error
 	getLocal       func() *types.Local
+	penalize       func(id string, score int)
+	inboundPeer    func(addr *peer.AddrInfo, handshake *types.Handshake) error
+	receiveInv     func(invs []*types.Inventory, peerId string)
+	getMessage     func(hash arry.Hash) (types.IMessage, bool)
+	receiveCompact func(compact types.ICompactBlock, peerId string)
+	sessions       map[peer.ID]*session
+	sessionMutex   sync.Mutex
+	// Whether the handshake of the peer is completed or rejected
+	handshakes     map[peer.ID]bool
+	handshakeMutex sync.RWMutex
 }
 
 func NewRequestHandler(chain blockchain.IChain) *RequestHandler {
 	return &RequestHandler{
-		chain:   chain,
-		readyCh: make(chan *ReqStream, config.Param.PeerRequestChan),
+		chain:      chain,
+		readyCh:    make(chan *ReqStream, config.Param.PeerRequestChan),
+		sessions:   make(map[peer.ID]*session),
+		handshakes: make(map[peer.ID]bool),
 		bytesPool: sync.Pool{
 			New: func() interface{} {
 				return make([]byte, param.MaxReadBytes)
@@ -81,6 +127,11 @@ func (r *RequestHandler) RegisterLocalInfo(f func() *types.Local) {
 func (r *RequestHandler) dealRequest() {
 	var h handler
 	for reqStream := range r.readyCh {
+		// The peers with a rejected handshake are only served the handshake
+		if reqStream.request.Method != handshake && !r.serves(reqStream.stream.Conn().RemotePeer()) {
+			go response(reqStream, r.respNoHandshake)
+			continue
+		}
 		switch reqStream.request.Method {
 		case sendBlock:
 			h = r.respSendBlock
@@ -94,6 +145,20 @@ func (r *RequestHandler) dealRequest() {
 			h = r.respSendMsg
 		case lastHeight:
 			h = r.respLastHeight
+		case handshake:
+			h = r.respHandshake
+		case announce:
+			h = r.respAnnounce
+		case getMessage:
+			h = r.respGetMessage
+		case getBlockHash:
+			h = r.respGetBlockHash
+		case sendCompact:
+			h = r.respSendCompact
+		case getBlockMsgs:
+			h = r.respGetBlockMsgs
+		case getHeaders:
+			h = r.respGetHeaders
 		default:
 			reqStream.Close()
 			continue
@@ -102,14 +167,29 @@ func (r *RequestHandler) dealRequest() {
 	}
 }
 
-func (r *RequestHandler) RegisterReceiveBlock(f func(types.IBlock) error) {
+func (r *RequestHandler) RegisterReceiveBlock(f func(types.IBlock, string) error) {
 	r.receiveBlock = f
 }
 
-func (r *RequestHandler) RegisterReceiveMessage(f func(types.IMessage) error) {
+func (r *RequestHandler) RegisterReceiveMessage(f func(types.IMessage, string) error) {
 	r.receiveMessage = f
 }
 
+// Register the function that handles the inventories announced by peers
+func (r *RequestHandler) RegisterReceiveInventory(f func(invs []*types.Inventory, peerId string)) {
+	r.receiveInv = f
+}
+
+// Register the function that rebuilds the compact blocks sent by peers
+func (r *RequestHandler) RegisterReceiveCompactBlock(f func(compact types.ICompactBlock, peerId string)) {
+	r.receiveCompact = f
+}
+
+// Register the function that looks up the messages requested by peers
+func (r *RequestHandler) RegisterGetMessage(f func(hash arry.Hash) (types.IMessage, bool)) {
+	r.getMessage = f
+}
+
 // Handling message requests
 func response(req *ReqStream, h handler) {
 	defer req.Close()
@@ -121,7 +201,7 @@ func response(req *ReqStream, h handler) {
 			"addr", req.stream.Conn().RemoteMultiaddr(),
 			"error", err)
 	} else if response != nil {
-		if err := responseStream(response, req.stream); err != nil {
+		if err := req.write(response); err != nil {
 			log.Warn("Send response error", "module", module,
 				"method", req.request.Method,
 				"peer", req.stream.Conn().RemotePeer(),
@@ -129,21 +209,32 @@ func response(req *ReqStream, h handler) {
 				"error", err)
 		}
 	}
-	for isAlive(req.stream) {
-		time.Sleep(time.Second * 1)
-	}
 }
 
 func (r *RequestHandler) SendToReady(stream network.Stream) {
-	request, err := r.UnmarshalRequest(stream)
+	var request *Request
+	var err error
+	reader := bufio.NewReader(stream)
+	if first, err := reader.Peek(1); err == nil && first[0] == sessionMagic {
+		r.serveSession(stream, reader)
+		return
+	}
+	framed := isFrame(reader)
+	if framed {
+		request, err = readRequestFrame(reader)
+	} else {
+		request, err = r.UnmarshalRequest(reader)
+	}
 	if err != nil {
+		r.punishViolation(stream.Conn().RemotePeer(), err)
+		stream.Reset()
 		return
 	}
-	r.readyCh <- NewReqStream(request, stream)
+	r.readyCh <- NewReqStream(request, stream, framed)
 }
 
 // Read from request
-func (r *RequestHandler) UnmarshalRequest(stream network.Stream) (*Request, error) {
+func (r *RequestHandler) UnmarshalRequest(stream io.Reader) (*Request, error) {
 	reBytes, _ := r.read(stream)
 	request := &Request{}
 	err := json.Unmarshal(reBytes, request)
@@ -153,12 +244,18 @@ func (r *RequestHandler) UnmarshalRequest(stream network.Stream) (*Request, erro
 	return request, nil
 }
 
-// Read from response
-func (r *RequestHandler) UnmarshalResponse(stream network.Stream) (*Response, error) {
-	reBytes, _ := r.read(stream)
+// Read from response of the request method
+func (r *RequestHandler) UnmarshalResponse(stream network.Stream, method Method, framed bool) (*Response, error) {
+	if framed {
+		return readResponseFrame(stream, method)
+	}
+	reBytes, err := r.read(stream)
+	if len(reBytes) == 0 && isClosed(err) {
+		return nil, errNoResponse
+	}
 
 	resp := &Response{}
-	err := json.Unmarshal(reBytes, resp)
+	err = json.Unmarshal(reBytes, resp)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +263,7 @@ func (r *RequestHandler) UnmarshalResponse(stream network.Stream) (*Response, er
 }
 
 // Read message bytes
-func (rm *RequestHandler) read(stream network.Stream) ([]byte, error) {
+func (rm *RequestHandler) read(stream io.Reader) ([]byte, error) {
 	arry := rm.bytesPool.Get().([]byte)
 	defer rm.bytesPool.Put(arry)
 	var rs []byte
@@ -181,7 +278,7 @@ func (rm *RequestHandler) read(stream network.Stream) ([]byte, error) {
 		}
 		rs = append(rs, arry[0:n]...)
 		len += n
-		if string(rs[len-endLength:]) == endFlag {
+		if len >= endLength && string(rs[len-endLength:]) == endFlag {
 			break
 		}
 	}
@@ -204,7 +301,10 @@ func reset(bytes []byte) {
 	}
 }
 
-func responseStream(response *Response, stream network.Stream) error {
+func responseStream(response *Response, stream network.Stream, framed bool) error {
+	if framed {
+		return writeResponseFrame(stream, response)
+	}
 	bytes, err := json.Marshal(response)
 	if err != nil {
 		return err
@@ -217,7 +317,10 @@ func responseStream(response *Response, stream network.Stream) error {
 	return nil
 }
 
-func requestStream(request *Request, stream network.Stream) error {
+func requestStream(request *Request, stream network.Stream, framed bool) error {
+	if framed {
+		return writeRequestFrame(stream, request)
+	}
 	bytes, err := json.Marshal(request)
 	if err != nil {
 		return err
@@ -230,11 +333,200 @@ func requestStream(request *Request, stream network.Stream) error {
 	return nil
 }
 
-func isAlive(stream network.Stream) bool {
+// Wait for the requester to close the stream after reading the response
+func waitClosed(stream network.Stream) {
+	stream.SetReadDeadline(time.Unix(time.Now().Unix()+timeOut, 0))
 	bytes := [10]byte{}
-	_, err := stream.Read(bytes[:])
+	for {
+		if _, err := stream.Read(bytes[:]); err != nil {
+			return
+		}
+	}
+}
+
+// Serve the requests of the session opened by the peer until it is closed
+func (r *RequestHandler) serveSession(stream network.Stream, reader *bufio.Reader) {
+	if err := readSessionPreface(reader); err != nil {
+		r.punishViolation(stream.Conn().RemotePeer(), err)
+		stream.Reset()
+		return
+	}
+	s := newSession(stream, reader)
+	s.onClose = func(err error) {
+		r.punishViolation(stream.Conn().RemotePeer(), err)
+	}
+	s.serve(func(id uint32, request *Request) {
+		r.readyCh <- &ReqStream{request: request, stream: stream, framed: true, session: s, id: id}
+	})
+}
+
+// Send the request to the peer and read the response. The request is multiplexed
+// on the session of the peer if it is supported, otherwise a new stream is used.
+func (r *RequestHandler) call(conn *types.Conn, request *Request, timeout time.Duration) (*Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var response *Response
+	var err error
+	if conn.Multiplexed {
+		var s *session
+		if s, err = r.session(conn); err != nil {
+			return nil, err
+		}
+		response, err = s.request(ctx, request)
+	} else {
+		response, err = r.callStream(ctx, conn, request)
+	}
+	if isTimeout(err) {
+		r.punish(conn.PeerId, request2.PenaltyTimeout)
+	} else {
+		r.punishViolation(conn.PeerId, err)
+	}
+	return response, err
+}
+
+func (r *RequestHandler) RegisterPeerPenalty(f func(id string, score int)) {
+	r.penalize = f
+}
+
+// Register the function that adds the peers that handshake with the local node,
+// the handshake is rejected if it returns an error
+func (r *RequestHandler) RegisterInboundPeer(f func(addr *peer.AddrInfo, handshake *types.Handshake) error) {
+	r.inboundPeer = f
+}
+
+func (r *RequestHandler) punish(id peer.ID, score int) {
+	if r.penalize != nil {
+		r.penalize(id.String(), score)
+	}
+}
+
+// Penalize the peer if the error is caused by malformed data
+func (r *RequestHandler) punishViolation(id peer.ID, err error) {
+	if errors.Is(err, errProtocol) {
+		r.punish(id, request2.PenaltyProtocol)
+	}
+}
+
+// Whether the peer closed or reset the stream
+func isClosed(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, mux.ErrReset)
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	timeout, ok := err.(interface{ Timeout() bool })
+	return ok && timeout.Timeout()
+}
+
+func (r *RequestHandler) callStream(ctx context.Context, conn *types.Conn, request *Request) (*Response, error) {
+	s, err := conn.Create(conn.PeerId)
 	if err != nil {
-		return false
+		return nil, fmt.Errorf("%w, %s", errOpenStream, err.Error())
+	}
+
+	defer func() {
+		s.Reset()
+		s.Close()
+	}()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		s.SetDeadline(deadline)
+	}
+	if err := requestStream(request, s, conn.Framed); err != nil {
+		return nil, err
+	}
+	return r.UnmarshalResponse(s, request.Method, conn.Framed)
+}
+
+// Get the session of the peer, a new one is opened if there is none
+func (r *RequestHandler) session(conn *types.Conn) (*session, error) {
+	r.sessionMutex.Lock()
+	s, ok := r.sessions[conn.PeerId]
+	r.sessionMutex.Unlock()
+	if ok && !s.isClosed() {
+		return s, nil
+	}
+
+	stream, err := conn.Create(conn.PeerId)
+	if err != nil {
+		return nil, err
 	}
-	return true
+	var opened *session
+	opened, err = openSession(stream, func(err error) {
+		r.removeSession(conn.PeerId, opened)
+		r.punishViolation(conn.PeerId, err)
+	})
+	if err != nil {
+		stream.Reset()
+		return nil, err
+	}
+
+	r.sessionMutex.Lock()
+	// Another request may have opened a session at the same time
+	if s, ok := r.sessions[conn.PeerId]; ok && !s.isClosed() {
+		r.sessionMutex.Unlock()
+		opened.close(nil)
+		return s, nil
+	}
+	r.sessions[conn.PeerId] = opened
+	r.sessionMutex.Unlock()
+	return opened, nil
+}
+
+func (r *RequestHandler) removeSession(id peer.ID, s *session) {
+	r.sessionMutex.Lock()
+	defer r.sessionMutex.Unlock()
+
+	if r.sessions[id] == s {
+		delete(r.sessions, id)
+	}
+}
+
+// Record whether the handshake of the peer is completed in either direction,
+// including the legacy peers that do not answer it, or rejected
+func (r *RequestHandler) setHandshake(id peer.ID, completed bool) {
+	r.handshakeMutex.Lock()
+	defer r.handshakeMutex.Unlock()
+
+	r.handshakes[id] = completed
+}
+
+// Whether the requests of the peer are served. A peer that sends requests
+// without a handshake is an old node, it is served in legacy mode unless
+// its handshake has been rejected.
+func (r *RequestHandler) serves(id peer.ID) bool {
+	r.handshakeMutex.Lock()
+	defer r.handshakeMutex.Unlock()
+
+	completed, ok := r.handshakes[id]
+	if !ok {
+		r.handshakes[id] = true
+		return true
+	}
+	return completed
+}
+
+// Close the session of the peer and forget its handshake
+func (r *RequestHandler) Disconnect(conn *types.Conn) {
+	r.handshakeMutex.Lock()
+	delete(r.handshakes, conn.PeerId)
+	r.handshakeMutex.Unlock()
+
+	r.sessionMutex.Lock()
+	s, ok := r.sessions[conn.PeerId]
+	delete(r.sessions, conn.PeerId)
+	r.sessionMutex.Unlock()
+
+	if ok {
+		s.close(nil)
+	}
+}
+
+// Whether the peer still answers requests
+func (r *RequestHandler) IsAlive(conn *types.Conn) bool {
+	_, err := r.LastHeight(conn)
+	return err == nil
 }
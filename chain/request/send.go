@@ -1,44 +1,45 @@
 package request
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	chaintypes "github.com/aiot-network/aiotchain/chain/types"
 	request2 "github.com/aiot-network/aiotchain/service/request"
+	"github.com/aiot-network/aiotchain/tools/arry"
 	"github.com/aiot-network/aiotchain/tools/rlp"
-	"github.com/aiot-network/aiotchain/tools/utils"
 	"github.com/aiot-network/aiotchain/types"
 	"time"
 )
 
 var (
-	lastHeight = Method("lastHeight")
-	sendMsg    = Method("sendMsg")
-	sendBlock  = Method("sendBlock")
-	getBlocks  = Method("getBlocks")
-	getBlock   = Method("getBlock")
-	isEqual    = Method("isEqual")
-	localInfo  = Method("localInfo")
+	lastHeight   = Method("lastHeight")
+	sendMsg      = Method("sendMsg")
+	sendBlock    = Method("sendBlock")
+	getBlocks    = Method("getBlocks")
+	getBlock     = Method("getBlock")
+	isEqual      = Method("isEqual")
+	localInfo    = Method("localInfo")
+	handshake    = Method("handshake")
+	announce     = Method("announce")
+	getMessage   = Method("getMessage")
+	getBlockHash = Method("getBlockHash")
+	sendCompact  = Method("sendCompact")
+	getBlockMsgs = Method("getBlockMsgs")
+	getHeaders   = Method("getHeaders")
 )
 
-func (r *RequestHandler) LastHeight(conn *types.Conn) (uint64, error) {
-	var height uint64 = 0
-	s, err := conn.Create(conn.PeerId)
-	if err != nil {
-		return 0, err
-	}
+// Parameters of getBlockMsgs
+type blockMsgsParams struct {
+	Hash    arry.Hash
+	Indexes []uint32
+}
 
-	defer func() {
-		s.Reset()
-		s.Close()
-	}()
+const syncTimeOut = 60
 
-	s.SetDeadline(time.Unix(utils.NowUnix()+timeOut, 0))
-	req := NewRequest(lastHeight, nil)
-	err = requestStream(req, s)
-	if err != nil {
-		return 0, err
-	}
-	response, _ := r.UnmarshalResponse(s)
+func (r *RequestHandler) LastHeight(conn *types.Conn) (uint64, error) {
+	var height uint64 = 0
+	response, err := r.call(conn, NewRequest(lastHeight, nil), time.Second*timeOut)
 	if response != nil && response.Code == Success {
 		err := rlp.DecodeBytes(response.Body, &height)
 		if err != nil {
@@ -51,23 +52,7 @@ func (r *RequestHandler) LastHeight(conn *types.Conn) (uint64, error) {
 }
 
 func (r *RequestHandler) SendMsg(conn *types.Conn, msg types.IMessage) error {
-	s, err := conn.Create(conn.PeerId)
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		s.Reset()
-		s.Close()
-	}()
-
-	s.SetDeadline(time.Unix(utils.NowUnix()+timeOut, 0))
-	req := NewRequest(sendMsg, msg.ToRlp().Bytes())
-	err = requestStream(req, s)
-	if err != nil {
-		return err
-	}
-	response, _ := r.UnmarshalResponse(s)
+	response, err := r.call(conn, NewRequest(sendMsg, msg.ToRlp().Bytes()), time.Second*timeOut)
 	if response != nil && response.Code == Success {
 		return nil
 	} else {
@@ -76,24 +61,7 @@ func (r *RequestHandler) SendMsg(conn *types.Conn, msg types.IMessage) error {
 }
 
 func (r *RequestHandler) SendBlock(conn *types.Conn, block types.IBlock) error {
-	s, err := conn.Create(conn.PeerId)
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		s.Reset()
-		s.Close()
-	}()
-
-	s.SetDeadline(time.Unix(utils.NowUnix()+timeOut, 0))
-	//body := xx
-	req := NewRequest(sendBlock, block.ToRlpBlock().Bytes())
-	err = requestStream(req, s)
-	if err != nil {
-		return err
-	}
-	response, _ := r.UnmarshalResponse(s)
+	response, err := r.call(conn, NewRequest(sendBlock, block.ToRlpBlock().Bytes()), time.Second*timeOut)
 	if response != nil && response.Code == Success {
 		return nil
 	} else {
@@ -102,35 +70,22 @@ func (r *RequestHandler) SendBlock(conn *types.Conn, block types.IBlock) error {
 }
 
 func (r *RequestHandler) GetBlocks(conn *types.Conn, height, count uint64) ([]types.IBlock, error) {
-	s, err := conn.Create(conn.PeerId)
-	if err != nil {
-		return nil, err
-	}
-
-	defer func() {
-		s.Reset()
-		s.Close()
-	}()
-
 	params := []uint64{height, count}
 	bytes, err := rlp.EncodeToBytes(params)
 	if err != nil {
 		return nil, err
 	}
-	s.SetDeadline(time.Unix(utils.NowUnix()+60, 0))
-	request := NewRequest(getBlocks, bytes)
-	err = requestStream(request, s)
+	response, err := r.call(conn, NewRequest(getBlocks, bytes), time.Second*syncTimeOut)
 	if err != nil {
 		return nil, request2.Err_PeerClosed
 	}
-	response, _ := r.UnmarshalResponse(s)
-	if response != nil && response.Code == Success {
+	if response.Code == Success {
 		blocks, err := chaintypes.DecodeRlpBlocks(response.Body)
 		if err != nil {
 			return nil, err
 		}
 		return chaintypes.RlpBlocksToBlocks(blocks), nil
-	} else if response != nil && response.Message == request2.Err_BlockNotFound.Error() {
+	} else if response.Message == request2.Err_BlockNotFound.Error() {
 		return nil, request2.Err_BlockNotFound
 	} else {
 		return nil, request2.Err_PeerClosed
@@ -138,34 +93,21 @@ func (r *RequestHandler) GetBlocks(conn *types.Conn, height, count uint64) ([]ty
 }
 
 func (r *RequestHandler) GetBlock(conn *types.Conn, height uint64) (types.IBlock, error) {
-	s, err := conn.Create(conn.PeerId)
-	if err != nil {
-		return nil, err
-	}
-
-	defer func() {
-		s.Reset()
-		s.Close()
-	}()
-
 	bytes, err := rlp.EncodeToBytes(height)
 	if err != nil {
 		return nil, err
 	}
-	s.SetDeadline(time.Unix(utils.NowUnix()+timeOut, 0))
-	request := NewRequest(getBlocks, bytes)
-	err = requestStream(request, s)
+	response, err := r.call(conn, NewRequest(getBlocks, bytes), time.Second*timeOut)
 	if err != nil {
 		return nil, request2.Err_PeerClosed
 	}
-	response, _ := r.UnmarshalResponse(s)
-	if response != nil && response.Code == Success {
+	if response.Code == Success {
 		block, err := chaintypes.DecodeRlpBlock(response.Body)
 		if err != nil {
 			return nil, err
 		}
 		return block.ToBlock(), nil
-	} else if response != nil && response.Message == request2.Err_BlockNotFound.Error() {
+	} else if response.Message == request2.Err_BlockNotFound.Error() {
 		return nil, request2.Err_BlockNotFound
 	} else {
 		return nil, request2.Err_PeerClosed
@@ -173,21 +115,7 @@ func (r *RequestHandler) GetBlock(conn *types.Conn, height uint64) (types.IBlock
 }
 
 func (r *RequestHandler) IsEqual(conn *types.Conn, header types.IHeader) (bool, error) {
-	s, err := conn.Create(conn.PeerId)
-	if err != nil {
-		return false, err
-	}
-
-	defer func() {
-		s.Reset()
-		s.Close()
-	}()
-
-	s.SetDeadline(time.Unix(time.Now().Unix()+timeOut, 0))
-
-	request := NewRequest(isEqual, header.Bytes())
-	err = requestStream(request, s)
-	response, _ := r.UnmarshalResponse(s)
+	response, err := r.call(conn, NewRequest(isEqual, header.Bytes()), time.Second*timeOut)
 	var rs bool
 	if response != nil && response.Code == Success {
 		err := rlp.DecodeBytes(response.Body, &rs)
@@ -201,29 +129,195 @@ func (r *RequestHandler) IsEqual(conn *types.Conn, header types.IHeader) (bool,
 }
 
 func (r *RequestHandler) LocalInfo(conn *types.Conn) (*types.Local, error) {
-	s, err := conn.Create(conn.PeerId)
+	response, err := r.call(conn, NewRequest(localInfo, nil), time.Second*timeOut)
+	var rs *types.Local
+	if response != nil && response.Code == Success {
+		err := rlp.DecodeBytes(response.Body, &rs)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, fmt.Errorf("peer error: %v", err)
+	}
+	return rs, nil
+}
+
+// Exchange the chain identity with the peer, an error is returned if the peer is incompatible
+func (r *RequestHandler) Handshake(conn *types.Conn) (*types.Handshake, error) {
+	local, err := r.localHandshake()
+	if err != nil {
+		return nil, err
+	}
+	return r.handshake(conn, local)
+}
+
+func (r *RequestHandler) handshake(conn *types.Conn, local *types.Handshake) (*types.Handshake, error) {
+	bytes, err := rlp.EncodeToBytes(local)
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() {
-		s.Reset()
-		s.Close()
-	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*timeOut)
+	defer cancel()
 
-	s.SetDeadline(time.Unix(time.Now().Unix()+timeOut, 0))
+	// The handshake is always JSON encoded on a new stream, the frame
+	// format and the session are negotiated by it
+	legacy := &types.Conn{PeerId: conn.PeerId, Create: conn.Create}
+	response, err := r.callStream(ctx, legacy, NewRequest(handshake, bytes))
+	if errors.Is(err, errOpenStream) {
+		return nil, request2.Err_PeerClosed
+	} else if errors.Is(err, errNoResponse) {
+		// Nodes before the handshake close the stream of the unknown method
+		// without an answer, they are kept as legacy peers
+		rs := types.LegacyHandshake()
+		conn.Negotiate(rs)
+		r.setHandshake(conn.PeerId, true)
+		return rs, nil
+	} else if err != nil {
+		// A peer that stalls or answers garbage is not taken for a legacy peer
+		r.setHandshake(conn.PeerId, false)
+		if isTimeout(err) {
+			r.punish(conn.PeerId, request2.PenaltyTimeout)
+		} else {
+			r.punish(conn.PeerId, request2.PenaltyProtocol)
+		}
+		return nil, err
+	} else if response.Code != Success {
+		r.setHandshake(conn.PeerId, false)
+		return nil, fmt.Errorf("peer rejected the handshake: %s", response.Message)
+	}
+	var rs *types.Handshake
+	if err := rlp.DecodeBytes(response.Body, &rs); err != nil {
+		return nil, err
+	}
+	if err := checkHandshake(local, rs); err != nil {
+		r.setHandshake(conn.PeerId, false)
+		return nil, err
+	}
+	conn.Negotiate(rs)
+	r.setHandshake(conn.PeerId, true)
+	return rs, nil
+}
 
-	request := NewRequest(localInfo, nil)
-	err = requestStream(request, s)
-	response, _ := r.UnmarshalResponse(s)
-	var rs *types.Local
+func (r *RequestHandler) Announce(conn *types.Conn, invs []*types.Inventory) error {
+	bytes, err := rlp.EncodeToBytes(invs)
+	if err != nil {
+		return err
+	}
+	response, err := r.call(conn, NewRequest(announce, bytes), time.Second*timeOut)
 	if response != nil && response.Code == Success {
-		err := rlp.DecodeBytes(response.Body, &rs)
+		return nil
+	} else {
+		return fmt.Errorf("peer error: %v", err)
+	}
+}
+
+func (r *RequestHandler) GetMessage(conn *types.Conn, hash arry.Hash) (types.IMessage, error) {
+	bytes, err := rlp.EncodeToBytes(hash)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.call(conn, NewRequest(getMessage, bytes), time.Second*timeOut)
+	if err != nil {
+		return nil, request2.Err_PeerClosed
+	}
+	if response.Code == Success {
+		msg, err := chaintypes.DecodeMessage(response.Body)
 		if err != nil {
 			return nil, err
 		}
+		return msg.ToMessage(), nil
+	} else if response.Message == request2.Err_MsgNotFound.Error() {
+		return nil, request2.Err_MsgNotFound
 	} else {
-		return nil, fmt.Errorf("peer error: %v", err)
+		return nil, request2.Err_PeerClosed
+	}
+}
+
+func (r *RequestHandler) GetBlockByHash(conn *types.Conn, hash arry.Hash) (types.IBlock, error) {
+	bytes, err := rlp.EncodeToBytes(hash)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.call(conn, NewRequest(getBlockHash, bytes), time.Second*timeOut)
+	if err != nil {
+		return nil, request2.Err_PeerClosed
+	}
+	if response.Code == Success {
+		block, err := chaintypes.DecodeRlpBlock(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		return block.ToBlock(), nil
+	} else if response.Message == request2.Err_BlockNotFound.Error() {
+		return nil, request2.Err_BlockNotFound
+	} else {
+		return nil, request2.Err_PeerClosed
+	}
+}
+
+func (r *RequestHandler) SendCompactBlock(conn *types.Conn, block types.IBlock) error {
+	compact := chaintypes.NewCompactBlock(block)
+	response, err := r.call(conn, NewRequest(sendCompact, compact.Bytes()), time.Second*timeOut)
+	if response != nil && response.Code == Success {
+		return nil
+	} else {
+		return fmt.Errorf("peer error: %v", err)
+	}
+}
+
+// Get the messages of the indexes of the block
+func (r *RequestHandler) GetBlockMsgs(conn *types.Conn, hash arry.Hash, indexes []uint32) ([]types.IMessage, error) {
+	bytes, err := rlp.EncodeToBytes(&blockMsgsParams{Hash: hash, Indexes: indexes})
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.call(conn, NewRequest(getBlockMsgs, bytes), time.Second*timeOut)
+	if err != nil {
+		return nil, request2.Err_PeerClosed
+	}
+	if response.Code == Success {
+		rlpMsgs, err := chaintypes.DecodeRlpMessages(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(rlpMsgs) != len(indexes) {
+			return nil, fmt.Errorf("%d messages are returned, %d are requested", len(rlpMsgs), len(indexes))
+		}
+		msgs := make([]types.IMessage, len(rlpMsgs))
+		for i, rlpMsg := range rlpMsgs {
+			msgs[i] = rlpMsg.ToMessage()
+		}
+		return msgs, nil
+	} else if response.Message == request2.Err_BlockNotFound.Error() {
+		return nil, request2.Err_BlockNotFound
+	} else {
+		return nil, request2.Err_PeerClosed
+	}
+}
+
+func (r *RequestHandler) GetHeaders(conn *types.Conn, height, count uint64) ([]types.IHeader, error) {
+	bytes, err := rlp.EncodeToBytes([]uint64{height, count})
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.call(conn, NewRequest(getHeaders, bytes), time.Second*syncTimeOut)
+	if err != nil {
+		return nil, request2.Err_PeerClosed
+	}
+	if response.Code == Success {
+		var headers []*chaintypes.Header
+		if err := rlp.DecodeBytes(response.Body, &headers); err != nil {
+			return nil, err
+		}
+		rs := make([]types.IHeader, len(headers))
+		for i, header := range headers {
+			rs[i] = header
+		}
+		return rs, nil
+	} else if response.Message == request2.Err_BlockNotFound.Error() {
+		return nil, request2.Err_BlockNotFound
+	} else {
+		return nil, request2.Err_PeerClosed
 	}
-	return rs, nil
 }
@@ -0,0 +1,255 @@
+package request
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/aiot-network/aiotchain/common/param"
+	"github.com/libp2p/go-libp2p-core/network"
+	"io"
+	"sync"
+	"time"
+)
+
+// A session is a long-lived stream to a peer on which concurrent requests
+// are multiplexed. The opener writes the preface
+//
+//	magic(1) | version(1)
+//
+// once, then every request is written as
+//
+//	id(4) | method | body
+//
+// and answered with
+//
+//	id(4) | code(1) | message | body
+//
+// in any order, where the fields are encoded as in the binary frames.
+const (
+	sessionMagic   byte = 0xA6
+	sessionVersion byte = 1
+	// The maximum number of requests of a session that are processed at the same time,
+	// further requests wait for a free slot
+	maxSessionRequests = 16
+)
+
+var errSessionClosed = errors.New("session closed")
+
+type session struct {
+	stream network.Stream
+	reader *bufio.Reader
+	// Serializes the writes of requests and responses
+	wm      sync.Mutex
+	mutex   sync.Mutex
+	nextId  uint32
+	pending map[uint32]*pendingCall
+	slots   chan struct{}
+	closed  chan struct{}
+	once    sync.Once
+	err     error
+	onClose func(err error)
+}
+
+type pendingCall struct {
+	method   Method
+	response chan *Response
+}
+
+func newSession(stream network.Stream, reader *bufio.Reader) *session {
+	return &session{
+		stream:  stream,
+		reader:  reader,
+		pending: make(map[uint32]*pendingCall),
+		slots:   make(chan struct{}, maxSessionRequests),
+		closed:  make(chan struct{}),
+	}
+}
+
+// Open a session on the stream and start reading the responses
+func openSession(stream network.Stream, onClose func(err error)) (*session, error) {
+	s := newSession(stream, bufio.NewReader(stream))
+	s.onClose = onClose
+	if err := s.write(context.Background(), []byte{sessionMagic, sessionVersion}); err != nil {
+		return nil, err
+	}
+	go s.readResponses()
+	return s, nil
+}
+
+// Send the request and wait for the response until the context is done
+func (s *session) request(ctx context.Context, request *Request) (*Response, error) {
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.closed:
+		return nil, s.err
+	}
+
+	id, call := s.register(request.Method)
+	defer s.unregister(id)
+
+	var idBytes [4]byte
+	binary.BigEndian.PutUint32(idBytes[:], id)
+	if err := s.write(ctx, appendRequest(idBytes[:], request)); err != nil {
+		return nil, err
+	}
+	select {
+	case response := <-call.response:
+		return response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.closed:
+		return nil, s.err
+	}
+}
+
+func (s *session) register(method Method) (uint32, *pendingCall) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.nextId++
+	call := &pendingCall{method: method, response: make(chan *Response, 1)}
+	s.pending[s.nextId] = call
+	return s.nextId, call
+}
+
+func (s *session) unregister(id uint32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.pending, id)
+}
+
+// Read the responses and deliver them to the waiting requests. The responses
+// of the requests that have timed out are discarded.
+func (s *session) readResponses() {
+	for {
+		id, err := readId(s.reader)
+		if err != nil {
+			s.close(err)
+			return
+		}
+		s.mutex.Lock()
+		call, ok := s.pending[id]
+		s.mutex.Unlock()
+
+		limit := uint32(param.MaxReqBytes)
+		if ok {
+			limit = responseLimits[call.method]
+		}
+		response, err := readResponse(s.reader, limit)
+		if err != nil {
+			s.close(err)
+			return
+		}
+		if ok {
+			call.response <- response
+		}
+	}
+}
+
+// Read the requests of the peer, a request is only read when there is a free slot
+func (s *session) serve(deal func(id uint32, request *Request)) {
+	for {
+		select {
+		case s.slots <- struct{}{}:
+		case <-s.closed:
+			return
+		}
+		id, err := readId(s.reader)
+		if err != nil {
+			s.close(err)
+			return
+		}
+		request, err := readRequest(s.reader)
+		if err != nil {
+			s.close(err)
+			return
+		}
+		deal(id, request)
+	}
+}
+
+// Write the response of the request and free its slot
+func (s *session) respond(id uint32, response *Response) error {
+	var idBytes [4]byte
+	binary.BigEndian.PutUint32(idBytes[:], id)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*timeOut)
+	defer cancel()
+	return s.write(ctx, appendResponse(idBytes[:], response))
+}
+
+func (s *session) release() {
+	<-s.slots
+}
+
+func (s *session) write(ctx context.Context, bytes []byte) error {
+	s.wm.Lock()
+	defer s.wm.Unlock()
+
+	select {
+	case <-s.closed:
+		return s.err
+	default:
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		s.stream.SetWriteDeadline(deadline)
+		defer s.stream.SetWriteDeadline(time.Time{})
+	}
+	if _, err := s.stream.Write(bytes); err != nil {
+		// The stream may contain a partial frame
+		s.close(err)
+		return err
+	}
+	return nil
+}
+
+func (s *session) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close the stream and fail all waiting requests
+func (s *session) close(err error) {
+	s.once.Do(func() {
+		s.err = err
+		if err == nil || err == io.EOF {
+			s.err = errSessionClosed
+		}
+		close(s.closed)
+		s.stream.Reset()
+		if s.onClose != nil {
+			s.onClose(err)
+		}
+	})
+}
+
+func readId(r io.Reader) (uint32, error) {
+	var id [4]byte
+	if _, err := io.ReadFull(r, id[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(id[:]), nil
+}
+
+func readSessionPreface(r io.Reader) error {
+	var preface [2]byte
+	if _, err := io.ReadFull(r, preface[:]); err != nil {
+		return err
+	}
+	if preface[0] != sessionMagic {
+		return errFrameMagic
+	}
+	if preface[1] > sessionVersion {
+		return fmt.Errorf("%w: unsupported session version %d", errProtocol, preface[1])
+	}
+	return nil
+}
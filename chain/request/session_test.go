@@ -0,0 +1,108 @@
+package request
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/libp2p/go-libp2p-core/network"
+	"net"
+	"testing"
+	"time"
+)
+
+type pipeStream struct {
+	network.Stream
+	pipe net.Conn
+}
+
+func (s *pipeStream) Read(b []byte) (int, error)         { return s.pipe.Read(b) }
+func (s *pipeStream) Write(b []byte) (int, error)        { return s.pipe.Write(b) }
+func (s *pipeStream) Close() error                       { return s.pipe.Close() }
+func (s *pipeStream) Reset() error                       { return s.pipe.Close() }
+func (s *pipeStream) SetDeadline(t time.Time) error      { return s.pipe.SetDeadline(t) }
+func (s *pipeStream) SetReadDeadline(t time.Time) error  { return s.pipe.SetReadDeadline(t) }
+func (s *pipeStream) SetWriteDeadline(t time.Time) error { return s.pipe.SetWriteDeadline(t) }
+
+// Open a session to a server that answers the lastHeight requests with their bodies,
+// the requests with the body "slow" are answered after the given delay
+func newTestSession(t *testing.T, delay time.Duration) *session {
+	client, server := net.Pipe()
+	go func() {
+		reader := bufio.NewReader(server)
+		if err := readSessionPreface(reader); err != nil {
+			server.Close()
+			return
+		}
+		s := newSession(&pipeStream{pipe: server}, reader)
+		s.serve(func(id uint32, request *Request) {
+			go func() {
+				defer s.release()
+				if string(request.Body) == "slow" {
+					time.Sleep(delay)
+				}
+				s.respond(id, NewResponse(Success, "", request.Body))
+			}()
+		})
+	}()
+	s, err := openSession(&pipeStream{pipe: client}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSession_Concurrent(t *testing.T) {
+	s := newTestSession(t, 0)
+	defer s.close(nil)
+
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		go func(i int) {
+			body := fmt.Sprintf("request%d", i)
+			response, err := s.request(context.Background(), NewRequest(lastHeight, []byte(body)))
+			if err == nil && string(response.Body) != body {
+				err = fmt.Errorf("got response %s for %s", string(response.Body), body)
+			}
+			errs <- err
+		}(i)
+	}
+	for i := 0; i < 50; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSession_Timeout(t *testing.T) {
+	s := newTestSession(t, time.Millisecond*200)
+	defer s.close(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if _, err := s.request(ctx, NewRequest(lastHeight, []byte("slow"))); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, expect the deadline to be exceeded", err)
+	}
+
+	// The late response is discarded and the session is still usable
+	time.Sleep(time.Millisecond * 200)
+	response, err := s.request(context.Background(), NewRequest(lastHeight, []byte("fast")))
+	if err != nil || string(response.Body) != "fast" {
+		t.Fatalf("got response %v and error %v", response, err)
+	}
+}
+
+func TestSession_Close(t *testing.T) {
+	s := newTestSession(t, time.Second)
+
+	done := make(chan error)
+	go func() {
+		_, err := s.request(context.Background(), NewRequest(lastHeight, []byte("slow")))
+		done <- err
+	}()
+	time.Sleep(time.Millisecond * 50)
+	s.close(nil)
+	if err := <-done; err != errSessionClosed {
+		t.Fatalf("got error %v, expect %v", err, errSessionClosed)
+	}
+}
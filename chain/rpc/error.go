@@ -1,5 +1,10 @@
 package rpc
 
+import (
+	"encoding/json"
+	chaintypes "github.com/aiot-network/aiotchain/chain/types"
+)
+
 const (
 	Success     = 0
 	Err_Params  = 1
@@ -9,4 +14,22 @@ const (
 	Err_Token   = 5
 	Err_Local   = 6
 	Err_Unknown = 7
+	Err_Peers   = 8
 )
+
+// Subcodes of message errors start from 100, see chaintypes.ErrCode
+
+type ErrDetails struct {
+	Name    string                `json:"name"`
+	Details chaintypes.ErrDetails `json:"details,omitempty"`
+}
+
+// Create the response of the error with the code passed in, the code
+// and details of a message error are returned as the subcode and details
+func NewErrResponse(code int32, err error) *Response {
+	if msgErr, ok := chaintypes.AsMsgError(err); ok {
+		details, _ := json.Marshal(&ErrDetails{Name: msgErr.Code.String(), Details: msgErr.Details})
+		return &Response{Code: code, Err: err.Error(), Details: details, Subcode: int32(msgErr.Code)}
+	}
+	return NewResponse(code, nil, err.Error())
+}
@@ -21,6 +21,7 @@ import (
 	log "github.com/aiot-network/aiotchain/tools/log/log15"
 	"github.com/aiot-network/aiotchain/tools/utils"
 	"github.com/aiot-network/aiotchain/types"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
@@ -33,6 +34,12 @@ import (
 
 const module = "rpc"
 
+// Maximum number of items returned by list queries
+const maxPageLimit = 100
+
+// Number of recent blocks used to estimate fees
+const feeEstimateBlocks = 20
+
 type Rpc struct {
 	grpcServer *grpc.Server
 	httpServer *http.Server
@@ -120,29 +127,108 @@ func (r *Rpc) RegisterLocalInfo(f func() *types.Local) {
 func (r *Rpc) GetAccount(_ context.Context, address *AddressReq) (*Response, error) {
 	arryAddr := arry.StringToAddress(address.Address)
 	if !kit.CheckAddress(config.Param.Name, arryAddr.String()) {
-		return NewResponse(Err_Params, nil, fmt.Sprintf("%s address check failed", address.Address)), nil
+		return NewErrResponse(Err_Params, chaintypes.NewMsgError(chaintypes.ErrBadAddress,
+			chaintypes.ErrDetails{"address": address.Address}, "%s address check failed", address.Address)), nil
+	}
+	account := r.status.Account(arryAddr).(*chaintypes.Account)
+	rpcAccount := rpctypes.ToRpcAccount(account, r.tokenDecimals)
+	if address.Pending {
+		ready, future := r.msgPool.GetByAddress(arryAddr)
+		rpcAccount.Pending = rpctypes.NewPendingState(account.Nonce, append(ready, future...), config.Param.MainToken, r.tokenDecimals)
 	}
-	account := r.status.Account(arryAddr)
 
-	bytes, _ := json.Marshal(rpctypes.ToRpcAccount(account.(*chaintypes.Account)))
+	bytes, _ := json.Marshal(rpcAccount)
 	return NewResponse(Success, bytes, ""), nil
 }
 
+// The decimals of the token, the main token and unknown tokens use the default decimals
+func (r *Rpc) tokenDecimals(token string) uint8 {
+	if token == config.Param.MainToken.String() {
+		return chaintypes.DefaultDecimals
+	}
+	iToken, err := r.status.Token(arry.StringToAddress(token))
+	if err != nil {
+		return chaintypes.DefaultDecimals
+	}
+	return iToken.(*chaintypes.TokenRecord).Meta.GetDecimals()
+}
+
 func (r *Rpc) SendMessageRaw(ctx context.Context, code *SendMessageCodeReq) (*Response, error) {
 	var rpcMsg *chaintypes.RpcMessage
 	if err := json.Unmarshal(code.Code, &rpcMsg); err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	tx, err := chaintypes.RpcMsgToMsg(rpcMsg)
 	if err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	if err := r.msgPool.Put(tx, false); err != nil {
-		return NewResponse(Err_MsgPool, nil, err.Error()), nil
+		return NewErrResponse(Err_MsgPool, err), nil
 	}
 	return NewResponse(Success, []byte(fmt.Sprintf("send message raw %s success", tx.Hash().String())), ""), nil
 }
 
+func (r *Rpc) SimulateMessage(ctx context.Context, code *SendMessageCodeReq) (*Response, error) {
+	var rpcMsg *chaintypes.RpcMessage
+	if err := json.Unmarshal(code.Code, &rpcMsg); err != nil {
+		return NewErrResponse(Err_Params, err), nil
+	}
+	tx, err := chaintypes.RpcMsgToMsg(rpcMsg)
+	if err != nil {
+		return NewErrResponse(Err_Params, err), nil
+	}
+	sim := &rpctypes.Simulation{MsgHash: tx.Hash().String(), Accounts: make([]*rpctypes.AccountDelta, 0)}
+	if err := r.simulate(tx, sim); err != nil {
+		return NewErrResponse(Err_Chain, err), nil
+	}
+	bytes, _ := json.Marshal(sim)
+	return NewResponse(Success, bytes, ""), nil
+}
+
+// Apply the message to a copy of the status, the failure of the
+// message is recorded in the simulation
+func (r *Rpc) simulate(tx *chaintypes.Message, sim *rpctypes.Simulation) error {
+	if err := tx.Check(); err != nil {
+		sim.Fail(rpctypes.StageCheck, err)
+		return nil
+	}
+	if err := r.status.CheckMsg(tx, true); err != nil {
+		sim.Fail(rpctypes.StageStatus, err)
+		return nil
+	}
+	copied, err := r.status.Copy()
+	if err != nil {
+		return err
+	}
+
+	addrs := []arry.Address{tx.From()}
+	for _, re := range tx.MsgBody().MsgTo().ReceiverList() {
+		addrs = append(addrs, re.Address)
+	}
+	before := make(map[arry.Address]*chaintypes.Account)
+	touched := make([]arry.Address, 0)
+	for _, addr := range addrs {
+		if _, ok := before[addr]; !ok {
+			before[addr] = copied.Account(addr).(*chaintypes.Account)
+			touched = append(touched, addr)
+		}
+	}
+
+	header := chaintypes.NewHeader(arry.Hash{}, arry.Hash{}, arry.Hash{}, arry.Hash{}, arry.Hash{},
+		r.chain.LastHeight()+1, uint64(utils.NowUnix()), arry.Address{})
+	block := &chaintypes.Block{Header: header, Body: &chaintypes.Body{Messages: []types.IMessage{tx}}}
+	if err := copied.Change(block.BlockBody().MsgList(), block); err != nil {
+		sim.Fail(rpctypes.StageApply, err)
+		return nil
+	}
+	for _, addr := range touched {
+		after := copied.Account(addr).(*chaintypes.Account)
+		sim.Accounts = append(sim.Accounts, rpctypes.NewAccountDelta(addr.String(), before[addr], after, r.tokenDecimals))
+	}
+	sim.Valid = true
+	return nil
+}
+
 func (r *Rpc) GetMessage(ctx context.Context, hash *HashReq) (*Response, error) {
 	var msg types.IMessage
 	var exist bool
@@ -190,11 +276,11 @@ func (r *Rpc) GetBlockHash(ctx context.Context, hash *HashReq) (*Response, error
 	}
 	block, err := r.chain.GetBlockHash(hashArry)
 	if err != nil {
-		return NewResponse(Err_Chain, nil, err.Error()), nil
+		return NewErrResponse(Err_Chain, err), nil
 	}
 	rpcBlock, err := rpctypes.BlockToRpcBlock(block.(*chaintypes.Block), r.chain.LastConfirmed())
 	if err != nil {
-		return NewResponse(Err_Chain, nil, err.Error()), nil
+		return NewErrResponse(Err_Chain, err), nil
 	}
 	bytes, _ := json.Marshal(rpcBlock)
 	return NewResponse(Success, bytes, ""), nil
@@ -203,11 +289,11 @@ func (r *Rpc) GetBlockHash(ctx context.Context, hash *HashReq) (*Response, error
 func (r *Rpc) GetBlockHeight(ctx context.Context, height *HeightReq) (*Response, error) {
 	block, err := r.chain.GetBlockHeight(height.Height)
 	if err != nil {
-		return NewResponse(Err_Chain, nil, err.Error()), nil
+		return NewErrResponse(Err_Chain, err), nil
 	}
 	rpcBlock, err := rpctypes.BlockToRpcBlock(block.(*chaintypes.Block), r.chain.LastConfirmed())
 	if err != nil {
-		return NewResponse(Err_Chain, nil, err.Error()), nil
+		return NewErrResponse(Err_Chain, err), nil
 	}
 	bytes, _ := json.Marshal(rpcBlock)
 	return NewResponse(Success, bytes, ""), nil
@@ -231,6 +317,71 @@ func (r *Rpc) GetMsgPool(context.Context, *NullReq) (*Response, error) {
 	return NewResponse(Success, bytes, ""), nil
 }
 
+func (r *Rpc) GetPoolMessage(_ context.Context, req *HashReq) (*Response, error) {
+	hash, err := arry.StringToHash(req.Hash)
+	if err != nil {
+		return NewErrResponse(Err_Params, fmt.Errorf("wrong hash %s", err.Error())), nil
+	}
+	msg, exist := r.msgPool.GetMessage(hash)
+	if !exist {
+		return NewErrResponse(Err_MsgPool, fmt.Errorf("message hash %s is not in the pool", req.Hash)), nil
+	}
+	state := rpctypes.PoolFuture
+	ready, _ := r.msgPool.GetByAddress(msg.From())
+	for _, readyMsg := range ready {
+		if readyMsg.Hash().IsEqual(msg.Hash()) {
+			state = rpctypes.PoolReady
+			break
+		}
+	}
+	bytes, _ := json.Marshal(rpctypes.NewPoolMessage(msg, state))
+	return NewResponse(Success, bytes, ""), nil
+}
+
+func (r *Rpc) GetPoolByAddress(_ context.Context, req *AddressReq) (*Response, error) {
+	address := arry.StringToAddress(req.Address)
+	if !kit.CheckAddress(config.Param.Name, address.String()) {
+		return NewErrResponse(Err_Params, chaintypes.NewMsgError(chaintypes.ErrBadAddress,
+			chaintypes.ErrDetails{"address": req.Address}, "%s address check failed", req.Address)), nil
+	}
+	ready, future := r.msgPool.GetByAddress(address)
+	pool := rpctypes.NewAddressPool(req.Address, r.status.Account(address).(*chaintypes.Account).Nonce, ready, future)
+	bytes, _ := json.Marshal(pool)
+	return NewResponse(Success, bytes, ""), nil
+}
+
+func (r *Rpc) EstimateFee(ctx context.Context, req *EstimateFeeReq) (*Response, error) {
+	receivers := int(req.Receivers)
+	if receivers == 0 {
+		receivers = 1
+	}
+	minFee := chaintypes.MinFee(chaintypes.MessageType(req.Type), receivers)
+
+	var fees []uint64
+	var blocks uint64
+	for height := r.chain.LastHeight(); height > 0 && blocks < feeEstimateBlocks; height-- {
+		block, err := r.chain.GetBlockHeight(height)
+		if err != nil {
+			break
+		}
+		for _, msg := range block.BlockBody().MsgList() {
+			if msg.IsCoinBase() {
+				continue
+			}
+			count := uint64(chaintypes.FeeUnits(msg.MsgBody()))
+			if count == 0 {
+				count = 1
+			}
+			fees = append(fees, msg.Fee()/count)
+		}
+		blocks++
+	}
+	poolCount, poolCapacity := r.msgPool.Occupancy()
+	estimate := rpctypes.NewFeeEstimate(minFee, receivers, fees, blocks, poolCount, poolCapacity)
+	bytes, _ := json.Marshal(estimate)
+	return NewResponse(Success, bytes, ""), nil
+}
+
 func (r *Rpc) Candidates(context.Context, *NullReq) (*Response, error) {
 	candidates := r.status.Candidates()
 	if candidates == nil || candidates.Len() == 0 {
@@ -269,12 +420,59 @@ func (r *Rpc) GetSupersReward(ctx context.Context, in *CycleReq) (*Response, err
 func (r *Rpc) Token(ctx context.Context, token *TokenAddressReq) (*Response, error) {
 	iToken, err := r.status.Token(arry.StringToAddress(token.Token))
 	if err != nil {
-		return NewResponse(Err_Token, nil, fmt.Sprintf("token address %s is not exist", token.Token)), nil
+		return NewErrResponse(Err_Token, chaintypes.NewMsgError(chaintypes.ErrTokenNotFound,
+			chaintypes.ErrDetails{"token": token.Token}, "token address %s is not exist", token.Token)), nil
 	}
 	bytes, _ := json.Marshal(rpctypes.TokenToRpcToken(iToken.(*chaintypes.TokenRecord)))
 	return NewResponse(Success, bytes, ""), nil
 }
 
+func (r *Rpc) ListTokens(ctx context.Context, req *PageReq) (*Response, error) {
+	iTokens, count := r.status.Tokens(req.Offset, pageLimit(req.Limit))
+	list := &rpctypes.RpcTokenList{Count: count, Tokens: make([]*rpctypes.RpcToken, len(iTokens))}
+	for i, iToken := range iTokens {
+		list.Tokens[i] = rpctypes.TokenToRpcToken(iToken.(*chaintypes.TokenRecord))
+	}
+	bytes, _ := json.Marshal(list)
+	return NewResponse(Success, bytes, ""), nil
+}
+
+func (r *Rpc) TokenHolders(ctx context.Context, req *TokenHoldersReq) (*Response, error) {
+	token := arry.StringToAddress(req.Token)
+	if !token.IsEqual(config.Param.MainToken) {
+		if _, err := r.status.Token(token); err != nil {
+			return NewErrResponse(Err_Token, chaintypes.NewMsgError(chaintypes.ErrTokenNotFound,
+				chaintypes.ErrDetails{"token": req.Token}, "token address %s is not exist", req.Token)), nil
+		}
+	}
+	holders, count := r.status.TokenHolders(token, req.Offset, pageLimit(req.Limit))
+	rs := &rpctypes.RpcTokenHolders{Token: req.Token, Count: count, Holders: make([]*rpctypes.Holder, len(holders))}
+	decimals := r.tokenDecimals(req.Token)
+	for i, holder := range holders {
+		rs.Holders[i] = rpctypes.AccountToHolder(holder.(*chaintypes.Account), req.Token, decimals)
+	}
+	bytes, _ := json.Marshal(rs)
+	return NewResponse(Success, bytes, ""), nil
+}
+
+func (r *Rpc) RichList(ctx context.Context, req *RichListReq) (*Response, error) {
+	mainToken := config.Param.MainToken.String()
+	holders := r.status.RichList(config.Param.MainToken, pageLimit(req.Limit))
+	rs := &rpctypes.RpcTokenHolders{Token: mainToken, Count: uint64(len(holders)), Holders: make([]*rpctypes.Holder, len(holders))}
+	for i, holder := range holders {
+		rs.Holders[i] = rpctypes.AccountToHolder(holder.(*chaintypes.Account), mainToken, chaintypes.MaxDecimals)
+	}
+	bytes, _ := json.Marshal(rs)
+	return NewResponse(Success, bytes, ""), nil
+}
+
+func pageLimit(limit uint64) uint64 {
+	if limit == 0 || limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
+
 func (r *Rpc) PeersInfo(context.Context, *NullReq) (*Response, error) {
 	peersInfo := r.peers.PeersInfo()
 	bytes, _ := json.Marshal(peersInfo)
@@ -289,10 +487,39 @@ func (r *Rpc) LocalInfo(context.Context, *NullReq) (*Response, error) {
 	return NewResponse(Err_Local, nil, "no local info"), nil
 }
 
+func (r *Rpc) ListBans(context.Context, *NullReq) (*Response, error) {
+	bytes, _ := json.Marshal(r.peers.Bans())
+	return NewResponse(Success, bytes, ""), nil
+}
+
+func (r *Rpc) BanPeer(ctx context.Context, req *BanReq) (*Response, error) {
+	id, err := peer.Decode(req.Peer)
+	if err != nil {
+		return NewResponse(Err_Params, nil, fmt.Sprintf("wrong peer id %s", req.Peer)), nil
+	}
+	if id.String() == r.peers.Local().Address.ID.String() {
+		return NewResponse(Err_Peers, nil, "cannot ban the local node"), nil
+	}
+	reason := req.Reason
+	if reason == "" {
+		reason = "banned by rpc"
+	}
+	ban := r.peers.Ban(id.String(), reason, req.Seconds)
+	bytes, _ := json.Marshal(ban)
+	return NewResponse(Success, bytes, ""), nil
+}
+
+func (r *Rpc) UnbanPeer(ctx context.Context, req *PeerReq) (*Response, error) {
+	if !r.peers.Unban(req.Peer) {
+		return NewResponse(Err_Peers, nil, fmt.Sprintf("peer %s is not banned", req.Peer)), nil
+	}
+	return NewResponse(Success, []byte(req.Peer), ""), nil
+}
+
 func (r *Rpc) GenerateAddress(ctx context.Context, req *GenerateReq) (*Response, error) {
 	address, err := kit.GenerateAddress(req.Network, req.Publickey)
 	if err != nil {
-		return NewResponse(Err_Unknown, nil, err.Error()), nil
+		return NewErrResponse(Err_Unknown, err), nil
 	}
 	return NewResponse(Success, []byte(address), ""), nil
 }
@@ -300,7 +527,7 @@ func (r *Rpc) GenerateAddress(ctx context.Context, req *GenerateReq) (*Response,
 func (r *Rpc) GenerateTokenAddress(ctx context.Context, req *GenerateTokenReq) (*Response, error) {
 	address, err := kit.GenerateTokenAddress(req.Network, req.Abbr)
 	if err != nil {
-		return NewResponse(Err_Unknown, nil, err.Error()), nil
+		return NewErrResponse(Err_Unknown, err), nil
 	}
 	return NewResponse(Success, []byte(address), ""), nil
 }
@@ -347,17 +574,17 @@ func (r *Rpc) SendTransaction(ctx context.Context, req *TransactionReq) (*Respon
 
 	signature, err := hex.DecodeString(req.Signature)
 	if err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	pubKey, err := hex.DecodeString(req.Publickey)
 	if err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	message.Header.Signature.Bytes = signature
 	message.Header.Signature.PubKey = pubKey
 
 	if err := r.msgPool.Put(message, false); err != nil {
-		return NewResponse(Err_MsgPool, nil, err.Error()), nil
+		return NewErrResponse(Err_MsgPool, err), nil
 	}
 	return NewResponse(Success, []byte(message.Hash().String()), ""), nil
 }
@@ -370,17 +597,17 @@ func (r *Rpc) SendToken(ctx context.Context, req *TokenReq) (*Response, error) {
 	fmt.Println(string(x))
 	signature, err := hex.DecodeString(req.Signature)
 	if err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	pubKey, err := hex.DecodeString(req.Publickey)
 	if err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	message.Header.Signature.Bytes = signature
 	message.Header.Signature.PubKey = pubKey
 
 	if err := r.msgPool.Put(message, false); err != nil {
-		return NewResponse(Err_MsgPool, nil, err.Error()), nil
+		return NewErrResponse(Err_MsgPool, err), nil
 	}
 	return NewResponse(Success, []byte(message.Hash().String()), ""), nil
 }
@@ -389,17 +616,17 @@ func (r *Rpc) SendCandidate(ctx context.Context, req *CandidateReq) (*Response,
 	message := message.NewCandidate(req.From, req.P2Pid, req.Fees, req.Nonce, req.Timestamp)
 	signature, err := hex.DecodeString(req.Signature)
 	if err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	pubKey, err := hex.DecodeString(req.Publickey)
 	if err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	message.Header.Signature.Bytes = signature
 	message.Header.Signature.PubKey = pubKey
 
 	if err := r.msgPool.Put(message, false); err != nil {
-		return NewResponse(Err_MsgPool, nil, err.Error()), nil
+		return NewErrResponse(Err_MsgPool, err), nil
 	}
 	return NewResponse(Success, []byte(message.Hash().String()), ""), nil
 }
@@ -408,17 +635,17 @@ func (r *Rpc) SendCancel(ctx context.Context, req *CancelReq) (*Response, error)
 	message := message.NewCancel(req.From, req.Fees, req.Nonce, req.Timestamp)
 	signature, err := hex.DecodeString(req.Signature)
 	if err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	pubKey, err := hex.DecodeString(req.Publickey)
 	if err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	message.Header.Signature.Bytes = signature
 	message.Header.Signature.PubKey = pubKey
 
 	if err := r.msgPool.Put(message, false); err != nil {
-		return NewResponse(Err_MsgPool, nil, err.Error()), nil
+		return NewErrResponse(Err_MsgPool, err), nil
 	}
 	return NewResponse(Success, []byte(message.Hash().String()), ""), nil
 }
@@ -427,17 +654,17 @@ func (r *Rpc) SendVote(ctx context.Context, req *VoteReq) (*Response, error) {
 	message := message.NewVote(req.From, req.To, req.Fees, req.Nonce, req.Timestamp)
 	signature, err := hex.DecodeString(req.Signature)
 	if err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	pubKey, err := hex.DecodeString(req.Publickey)
 	if err != nil {
-		return NewResponse(Err_Params, nil, err.Error()), nil
+		return NewErrResponse(Err_Params, err), nil
 	}
 	message.Header.Signature.Bytes = signature
 	message.Header.Signature.PubKey = pubKey
 
 	if err := r.msgPool.Put(message, false); err != nil {
-		return NewResponse(Err_MsgPool, nil, err.Error()), nil
+		return NewErrResponse(Err_MsgPool, err), nil
 	}
 	return NewResponse(Success, []byte(message.Hash().String()), ""), nil
 }
@@ -24,6 +24,7 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// null req
 type NullReq struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -56,7 +57,10 @@ func (m *NullReq) XXX_DiscardUnknown() {
 var xxx_messageInfo_NullReq proto.InternalMessageInfo
 
 type AddressReq struct {
-	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// address
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// overlay the messages in the pool
+	Pending              bool     `protobuf:"varint,2,opt,name=pending,proto3" json:"pending,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -94,7 +98,15 @@ func (m *AddressReq) GetAddress() string {
 	return ""
 }
 
+func (m *AddressReq) GetPending() bool {
+	if m != nil {
+		return m.Pending
+	}
+	return false
+}
+
 type TokenAddressReq struct {
+	// token address
 	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -134,6 +146,7 @@ func (m *TokenAddressReq) GetToken() string {
 }
 
 type SendMessageCodeReq struct {
+	// message data
 	Code                 []byte   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -173,6 +186,7 @@ func (m *SendMessageCodeReq) GetCode() []byte {
 }
 
 type HashReq struct {
+	// hash
 	Hash                 string   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -212,6 +226,7 @@ func (m *HashReq) GetHash() string {
 }
 
 type HeightReq struct {
+	// height
 	Height               uint64   `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -251,6 +266,7 @@ func (m *HeightReq) GetHeight() uint64 {
 }
 
 type CycleReq struct {
+	// cycle
 	Cycle                uint64   `protobuf:"varint,1,opt,name=cycle,proto3" json:"cycle,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -290,7 +306,9 @@ func (m *CycleReq) GetCycle() uint64 {
 }
 
 type GenerateReq struct {
-	Network              string   `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	// mainnet or testnet
+	Network string `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	// public key
 	Publickey            string   `protobuf:"bytes,2,opt,name=publickey,proto3" json:"publickey,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -337,8 +355,11 @@ func (m *GenerateReq) GetPublickey() string {
 }
 
 type GenerateTokenReq struct {
-	Network              string   `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
-	Address              string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	// mainnet or testnet
+	Network string `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	// address
+	Address string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	// token symbol
 	Abbr                 string   `protobuf:"bytes,3,opt,name=abbr,proto3" json:"abbr,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -392,15 +413,25 @@ func (m *GenerateTokenReq) GetAbbr() string {
 }
 
 type TransactionReq struct {
-	From                 string   `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
-	To                   string   `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
-	Token                string   `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
-	Note                 string   `protobuf:"bytes,4,opt,name=note,proto3" json:"note,omitempty"`
-	Amount               uint64   `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`
-	Fees                 uint64   `protobuf:"varint,6,opt,name=fees,proto3" json:"fees,omitempty"`
-	Timestamp            uint64   `protobuf:"varint,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Nonce                uint64   `protobuf:"varint,8,opt,name=nonce,proto3" json:"nonce,omitempty"`
-	Signature            string   `protobuf:"bytes,9,opt,name=signature,proto3" json:"signature,omitempty"`
+	// transfer from
+	From string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// transfer to
+	To string `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// transfer token
+	Token string `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
+	// transfer note
+	Note string `protobuf:"bytes,4,opt,name=note,proto3" json:"note,omitempty"`
+	// transfer amount
+	Amount uint64 `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	// transfer fees
+	Fees uint64 `protobuf:"varint,6,opt,name=fees,proto3" json:"fees,omitempty"`
+	// transfer time
+	Timestamp uint64 `protobuf:"varint,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// transfer nonce
+	Nonce uint64 `protobuf:"varint,8,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// signature
+	Signature string `protobuf:"bytes,9,opt,name=signature,proto3" json:"signature,omitempty"`
+	// public key
 	Publickey            string   `protobuf:"bytes,10,opt,name=publickey,proto3" json:"publickey,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -503,17 +534,29 @@ func (m *TransactionReq) GetPublickey() string {
 }
 
 type TokenReq struct {
-	From                 string   `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
-	Receiver             string   `protobuf:"bytes,2,opt,name=receiver,proto3" json:"receiver,omitempty"`
-	Token                string   `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"`
-	Name                 string   `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
-	Abbr                 string   `protobuf:"bytes,6,opt,name=abbr,proto3" json:"abbr,omitempty"`
-	Increase             bool     `protobuf:"varint,7,opt,name=increase,proto3" json:"increase,omitempty"`
-	Amount               uint64   `protobuf:"varint,8,opt,name=amount,proto3" json:"amount,omitempty"`
-	Fees                 uint64   `protobuf:"varint,9,opt,name=fees,proto3" json:"fees,omitempty"`
-	Timestamp            uint64   `protobuf:"varint,10,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Nonce                uint64   `protobuf:"varint,11,opt,name=nonce,proto3" json:"nonce,omitempty"`
-	Signature            string   `protobuf:"bytes,12,opt,name=signature,proto3" json:"signature,omitempty"`
+	// token from
+	From string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// token receiver
+	Receiver string `protobuf:"bytes,2,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	// token address
+	Token string `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"`
+	// token name
+	Name string `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	// token symbol
+	Abbr string `protobuf:"bytes,6,opt,name=abbr,proto3" json:"abbr,omitempty"`
+	// false
+	Increase bool `protobuf:"varint,7,opt,name=increase,proto3" json:"increase,omitempty"`
+	// token amount
+	Amount uint64 `protobuf:"varint,8,opt,name=amount,proto3" json:"amount,omitempty"`
+	// fees
+	Fees uint64 `protobuf:"varint,9,opt,name=fees,proto3" json:"fees,omitempty"`
+	// time
+	Timestamp uint64 `protobuf:"varint,10,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// nonce
+	Nonce uint64 `protobuf:"varint,11,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// signature
+	Signature string `protobuf:"bytes,12,opt,name=signature,proto3" json:"signature,omitempty"`
+	// public key
 	Publickey            string   `protobuf:"bytes,13,opt,name=publickey,proto3" json:"publickey,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -630,12 +673,19 @@ func (m *TokenReq) GetPublickey() string {
 }
 
 type CandidateReq struct {
-	From                 string   `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
-	P2Pid                string   `protobuf:"bytes,2,opt,name=p2pid,proto3" json:"p2pid,omitempty"`
-	Fees                 uint64   `protobuf:"varint,3,opt,name=fees,proto3" json:"fees,omitempty"`
-	Timestamp            uint64   `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Nonce                uint64   `protobuf:"varint,5,opt,name=nonce,proto3" json:"nonce,omitempty"`
-	Signature            string   `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+	// candidate from
+	From string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// p2p id
+	P2Pid string `protobuf:"bytes,2,opt,name=p2pid,proto3" json:"p2pid,omitempty"`
+	// fees
+	Fees uint64 `protobuf:"varint,3,opt,name=fees,proto3" json:"fees,omitempty"`
+	// time
+	Timestamp uint64 `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// nonce
+	Nonce uint64 `protobuf:"varint,5,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// signature
+	Signature string `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+	// public key
 	Publickey            string   `protobuf:"bytes,7,opt,name=publickey,proto3" json:"publickey,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -717,11 +767,17 @@ func (m *CandidateReq) GetPublickey() string {
 }
 
 type CancelReq struct {
-	From                 string   `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
-	Fees                 uint64   `protobuf:"varint,2,opt,name=fees,proto3" json:"fees,omitempty"`
-	Timestamp            uint64   `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Nonce                uint64   `protobuf:"varint,4,opt,name=nonce,proto3" json:"nonce,omitempty"`
-	Signature            string   `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	// cancel from
+	From string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// fees
+	Fees uint64 `protobuf:"varint,2,opt,name=fees,proto3" json:"fees,omitempty"`
+	// time
+	Timestamp uint64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// nonce
+	Nonce uint64 `protobuf:"varint,4,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// signature
+	Signature string `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	// public key
 	Publickey            string   `protobuf:"bytes,6,opt,name=publickey,proto3" json:"publickey,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -796,12 +852,19 @@ func (m *CancelReq) GetPublickey() string {
 }
 
 type VoteReq struct {
-	From                 string   `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
-	To                   string   `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
-	Fees                 uint64   `protobuf:"varint,3,opt,name=fees,proto3" json:"fees,omitempty"`
-	Timestamp            uint64   `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Nonce                uint64   `protobuf:"varint,5,opt,name=nonce,proto3" json:"nonce,omitempty"`
-	Signature            string   `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+	// vote from
+	From string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// vote to
+	To string `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// fees
+	Fees uint64 `protobuf:"varint,3,opt,name=fees,proto3" json:"fees,omitempty"`
+	// time
+	Timestamp uint64 `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// nonce
+	Nonce uint64 `protobuf:"varint,5,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// signature
+	Signature string `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+	// public key
 	Publickey            string   `protobuf:"bytes,7,opt,name=publickey,proto3" json:"publickey,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -884,9 +947,13 @@ func (m *VoteReq) GetPublickey() string {
 
 // The response message containing the greetings
 type Response struct {
-	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
-	Result               []byte   `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
-	Err                  string   `protobuf:"bytes,3,opt,name=err,proto3" json:"err,omitempty"`
+	Code   int32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Result []byte `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	Err    string `protobuf:"bytes,3,opt,name=err,proto3" json:"err,omitempty"`
+	// machine readable details of the error
+	Details []byte `protobuf:"bytes,4,opt,name=details,proto3" json:"details,omitempty"`
+	// code of the message error, see chaintypes.ErrCode
+	Subcode              int32    `protobuf:"varint,5,opt,name=subcode,proto3" json:"subcode,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -938,6 +1005,314 @@ func (m *Response) GetErr() string {
 	return ""
 }
 
+func (m *Response) GetDetails() []byte {
+	if m != nil {
+		return m.Details
+	}
+	return nil
+}
+
+func (m *Response) GetSubcode() int32 {
+	if m != nil {
+		return m.Subcode
+	}
+	return 0
+}
+
+type PageReq struct {
+	// number of skipped items
+	Offset uint64 `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	// maximum number of items
+	Limit                uint64   `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PageReq) Reset()         { *m = PageReq{} }
+func (m *PageReq) String() string { return proto.CompactTextString(m) }
+func (*PageReq) ProtoMessage()    {}
+func (*PageReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{15}
+}
+
+func (m *PageReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PageReq.Unmarshal(m, b)
+}
+func (m *PageReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PageReq.Marshal(b, m, deterministic)
+}
+func (m *PageReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PageReq.Merge(m, src)
+}
+func (m *PageReq) XXX_Size() int {
+	return xxx_messageInfo_PageReq.Size(m)
+}
+func (m *PageReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_PageReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PageReq proto.InternalMessageInfo
+
+func (m *PageReq) GetOffset() uint64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *PageReq) GetLimit() uint64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type TokenHoldersReq struct {
+	// token address
+	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// number of skipped holders
+	Offset uint64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// maximum number of holders
+	Limit                uint64   `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TokenHoldersReq) Reset()         { *m = TokenHoldersReq{} }
+func (m *TokenHoldersReq) String() string { return proto.CompactTextString(m) }
+func (*TokenHoldersReq) ProtoMessage()    {}
+func (*TokenHoldersReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{16}
+}
+
+func (m *TokenHoldersReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TokenHoldersReq.Unmarshal(m, b)
+}
+func (m *TokenHoldersReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TokenHoldersReq.Marshal(b, m, deterministic)
+}
+func (m *TokenHoldersReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TokenHoldersReq.Merge(m, src)
+}
+func (m *TokenHoldersReq) XXX_Size() int {
+	return xxx_messageInfo_TokenHoldersReq.Size(m)
+}
+func (m *TokenHoldersReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_TokenHoldersReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TokenHoldersReq proto.InternalMessageInfo
+
+func (m *TokenHoldersReq) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *TokenHoldersReq) GetOffset() uint64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *TokenHoldersReq) GetLimit() uint64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type RichListReq struct {
+	// maximum number of holders
+	Limit                uint64   `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RichListReq) Reset()         { *m = RichListReq{} }
+func (m *RichListReq) String() string { return proto.CompactTextString(m) }
+func (*RichListReq) ProtoMessage()    {}
+func (*RichListReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{17}
+}
+
+func (m *RichListReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RichListReq.Unmarshal(m, b)
+}
+func (m *RichListReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RichListReq.Marshal(b, m, deterministic)
+}
+func (m *RichListReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RichListReq.Merge(m, src)
+}
+func (m *RichListReq) XXX_Size() int {
+	return xxx_messageInfo_RichListReq.Size(m)
+}
+func (m *RichListReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_RichListReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RichListReq proto.InternalMessageInfo
+
+func (m *RichListReq) GetLimit() uint64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type EstimateFeeReq struct {
+	// message type
+	Type uint32 `protobuf:"varint,1,opt,name=type,proto3" json:"type,omitempty"`
+	// number of receivers
+	Receivers            uint64   `protobuf:"varint,2,opt,name=receivers,proto3" json:"receivers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EstimateFeeReq) Reset()         { *m = EstimateFeeReq{} }
+func (m *EstimateFeeReq) String() string { return proto.CompactTextString(m) }
+func (*EstimateFeeReq) ProtoMessage()    {}
+func (*EstimateFeeReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{18}
+}
+
+func (m *EstimateFeeReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EstimateFeeReq.Unmarshal(m, b)
+}
+func (m *EstimateFeeReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EstimateFeeReq.Marshal(b, m, deterministic)
+}
+func (m *EstimateFeeReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EstimateFeeReq.Merge(m, src)
+}
+func (m *EstimateFeeReq) XXX_Size() int {
+	return xxx_messageInfo_EstimateFeeReq.Size(m)
+}
+func (m *EstimateFeeReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_EstimateFeeReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EstimateFeeReq proto.InternalMessageInfo
+
+func (m *EstimateFeeReq) GetType() uint32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+func (m *EstimateFeeReq) GetReceivers() uint64 {
+	if m != nil {
+		return m.Receivers
+	}
+	return 0
+}
+
+type PeerReq struct {
+	// peer p2p id
+	Peer                 string   `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PeerReq) Reset()         { *m = PeerReq{} }
+func (m *PeerReq) String() string { return proto.CompactTextString(m) }
+func (*PeerReq) ProtoMessage()    {}
+func (*PeerReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{19}
+}
+
+func (m *PeerReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeerReq.Unmarshal(m, b)
+}
+func (m *PeerReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeerReq.Marshal(b, m, deterministic)
+}
+func (m *PeerReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerReq.Merge(m, src)
+}
+func (m *PeerReq) XXX_Size() int {
+	return xxx_messageInfo_PeerReq.Size(m)
+}
+func (m *PeerReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerReq proto.InternalMessageInfo
+
+func (m *PeerReq) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+type BanReq struct {
+	// peer p2p id
+	Peer string `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+	// seconds of the ban, the ban is permanent if it is 0
+	Seconds uint64 `protobuf:"varint,2,opt,name=seconds,proto3" json:"seconds,omitempty"`
+	// reason of the ban
+	Reason               string   `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BanReq) Reset()         { *m = BanReq{} }
+func (m *BanReq) String() string { return proto.CompactTextString(m) }
+func (*BanReq) ProtoMessage()    {}
+func (*BanReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{20}
+}
+
+func (m *BanReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BanReq.Unmarshal(m, b)
+}
+func (m *BanReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BanReq.Marshal(b, m, deterministic)
+}
+func (m *BanReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BanReq.Merge(m, src)
+}
+func (m *BanReq) XXX_Size() int {
+	return xxx_messageInfo_BanReq.Size(m)
+}
+func (m *BanReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_BanReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BanReq proto.InternalMessageInfo
+
+func (m *BanReq) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *BanReq) GetSeconds() uint64 {
+	if m != nil {
+		return m.Seconds
+	}
+	return 0
+}
+
+func (m *BanReq) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*NullReq)(nil), "rpc.NullReq")
 	proto.RegisterType((*AddressReq)(nil), "rpc.AddressReq")
@@ -954,64 +1329,87 @@ func init() {
 	proto.RegisterType((*CancelReq)(nil), "rpc.CancelReq")
 	proto.RegisterType((*VoteReq)(nil), "rpc.VoteReq")
 	proto.RegisterType((*Response)(nil), "rpc.Response")
+	proto.RegisterType((*PageReq)(nil), "rpc.PageReq")
+	proto.RegisterType((*TokenHoldersReq)(nil), "rpc.TokenHoldersReq")
+	proto.RegisterType((*RichListReq)(nil), "rpc.RichListReq")
+	proto.RegisterType((*EstimateFeeReq)(nil), "rpc.EstimateFeeReq")
+	proto.RegisterType((*PeerReq)(nil), "rpc.PeerReq")
+	proto.RegisterType((*BanReq)(nil), "rpc.BanReq")
 }
 
 func init() { proto.RegisterFile("rpc.proto", fileDescriptor_77a6da22d6a3feb1) }
 
 var fileDescriptor_77a6da22d6a3feb1 = []byte{
-	// 825 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x96, 0xcd, 0x8e, 0xe3, 0x44,
-	0x10, 0xc7, 0xc9, 0xa7, 0xe3, 0x9a, 0x4c, 0x32, 0x34, 0x01, 0xac, 0x15, 0x48, 0x2b, 0x23, 0xc1,
-	0x8a, 0x15, 0x11, 0x2c, 0x48, 0x08, 0x0e, 0x48, 0x4b, 0x84, 0x32, 0x48, 0x0b, 0x5a, 0x79, 0x57,
-	0x1c, 0xb8, 0x75, 0xec, 0x9a, 0xc4, 0x8a, 0xd3, 0xed, 0xed, 0xee, 0x30, 0x9a, 0xe7, 0xe1, 0x05,
-	0xe0, 0xce, 0x13, 0xf1, 0x08, 0x9c, 0x50, 0x7f, 0xd8, 0xce, 0x87, 0x9d, 0x84, 0x1b, 0xb7, 0xaa,
-	0xee, 0x7f, 0x75, 0xff, 0xeb, 0x27, 0xdb, 0x65, 0xf0, 0x45, 0x1e, 0x4f, 0x73, 0xc1, 0x15, 0x27,
-	0x1d, 0x91, 0xc7, 0xa1, 0x0f, 0xde, 0xcf, 0xdb, 0x2c, 0x8b, 0xf0, 0x4d, 0xf8, 0x31, 0xc0, 0xf3,
-	0x24, 0x11, 0x28, 0x65, 0x84, 0x6f, 0x48, 0x00, 0x1e, 0xb5, 0x59, 0xd0, 0x7a, 0xdc, 0x7a, 0xe2,
-	0x47, 0x45, 0x1a, 0x7e, 0x02, 0xe3, 0xd7, 0x7c, 0x8d, 0x6c, 0x47, 0x3c, 0x81, 0x9e, 0xd2, 0x4b,
-	0x4e, 0x6a, 0x93, 0xf0, 0x09, 0x90, 0x57, 0xc8, 0x92, 0x9f, 0x50, 0x4a, 0xba, 0xc4, 0x19, 0x4f,
-	0x50, 0x6b, 0x09, 0x74, 0x63, 0x9e, 0xa0, 0x91, 0x0e, 0x23, 0x13, 0x87, 0x1f, 0x82, 0x77, 0x4b,
-	0xe5, 0xca, 0x6d, 0xaf, 0xa8, 0x5c, 0xb9, 0x93, 0x4c, 0x1c, 0x7e, 0x04, 0xfe, 0x2d, 0xa6, 0xcb,
-	0x95, 0xd2, 0x82, 0xf7, 0xa0, 0xbf, 0x32, 0x89, 0x91, 0x74, 0x23, 0x97, 0x85, 0x8f, 0x61, 0x30,
-	0x7b, 0x88, 0x33, 0x74, 0x7e, 0x62, 0x1d, 0x3b, 0x89, 0x4d, 0xc2, 0x1f, 0xe0, 0x6a, 0x8e, 0x0c,
-	0x05, 0x55, 0xe8, 0x3a, 0x64, 0xa8, 0xee, 0xb9, 0x58, 0x17, 0x1d, 0xba, 0x94, 0x7c, 0x00, 0x7e,
-	0xbe, 0x5d, 0x64, 0x69, 0xbc, 0xc6, 0x87, 0xa0, 0x6d, 0xf6, 0xaa, 0x85, 0xf0, 0x57, 0xb8, 0x29,
-	0x8e, 0x31, 0x1c, 0x4e, 0x9f, 0xb5, 0xc3, 0xb1, 0xbd, 0xc7, 0x51, 0x77, 0x4a, 0x17, 0x0b, 0x11,
-	0x74, 0x6c, 0xa7, 0x3a, 0x0e, 0xff, 0x69, 0xc1, 0xe8, 0xb5, 0xa0, 0x4c, 0xd2, 0x58, 0xa5, 0x9c,
-	0x39, 0x20, 0x77, 0x82, 0x6f, 0x0a, 0x20, 0x3a, 0x26, 0x23, 0x68, 0x2b, 0xee, 0xce, 0x6b, 0x2b,
-	0x5e, 0xf1, 0xef, 0xec, 0xf0, 0xd7, 0x95, 0x8c, 0x2b, 0x0c, 0xba, 0xb6, 0x52, 0xc7, 0x9a, 0x1e,
-	0xdd, 0xf0, 0x2d, 0x53, 0x41, 0xcf, 0xd2, 0xb3, 0x99, 0xb9, 0x05, 0x51, 0x06, 0x7d, 0xb3, 0x6a,
-	0x62, 0x8d, 0x41, 0xa5, 0x1b, 0x94, 0x8a, 0x6e, 0xf2, 0xc0, 0x33, 0x1b, 0xd5, 0x82, 0xbe, 0x93,
-	0x71, 0x16, 0x63, 0x30, 0xb0, 0x8c, 0x4d, 0xa2, 0x6b, 0x64, 0xba, 0x64, 0x54, 0x6d, 0x05, 0x06,
-	0xbe, 0x45, 0x57, 0x2e, 0xec, 0x83, 0x85, 0x43, 0xb0, 0x7f, 0xb4, 0x61, 0x50, 0x12, 0xad, 0x6b,
-	0xfb, 0x11, 0x0c, 0x04, 0xc6, 0x98, 0xfe, 0x86, 0xc2, 0x35, 0x5f, 0xe6, 0x15, 0x82, 0xee, 0x21,
-	0x02, 0xba, 0x41, 0xd3, 0xac, 0x46, 0x40, 0x37, 0x58, 0x72, 0xef, 0x57, 0xdc, 0xf5, 0xc9, 0x29,
-	0x8b, 0x05, 0x52, 0x89, 0xa6, 0xd3, 0x41, 0x54, 0xe6, 0x3b, 0xc8, 0x06, 0xb5, 0xc8, 0xfc, 0x26,
-	0x64, 0xd0, 0x88, 0xec, 0xaa, 0x11, 0xd9, 0xf0, 0x24, 0xb2, 0xeb, 0x43, 0x64, 0x7f, 0xb5, 0x60,
-	0x38, 0xa3, 0x2c, 0x49, 0x13, 0xf7, 0x50, 0xd7, 0x61, 0x9b, 0x40, 0x2f, 0x7f, 0x96, 0xa7, 0x89,
-	0x63, 0x66, 0x93, 0xd2, 0x7e, 0xa7, 0xc9, 0x7e, 0xb7, 0xd1, 0x7e, 0xaf, 0xd1, 0x7e, 0xff, 0xa4,
-	0x7d, 0xef, 0xd0, 0xfe, 0xef, 0x2d, 0xf0, 0x67, 0x94, 0xc5, 0x98, 0x35, 0x79, 0x2f, 0x5c, 0xb6,
-	0x9b, 0x5c, 0x76, 0x1a, 0x5d, 0x76, 0x1b, 0x5d, 0xf6, 0x4e, 0xba, 0xec, 0x1f, 0xba, 0xfc, 0xb3,
-	0x05, 0xde, 0x2f, 0xbc, 0x99, 0xef, 0xe1, 0xdb, 0xf8, 0x7f, 0x20, 0x7b, 0x0b, 0x83, 0x08, 0x65,
-	0xce, 0x99, 0xc4, 0xbd, 0x2f, 0x6e, 0xcf, 0x7e, 0x71, 0xf5, 0x43, 0x2d, 0x50, 0x6e, 0x33, 0x65,
-	0x7c, 0x0f, 0x23, 0x97, 0x91, 0x1b, 0xe8, 0xa0, 0x28, 0xbe, 0x49, 0x3a, 0x7c, 0xf6, 0xb7, 0x07,
-	0xde, 0x5c, 0x20, 0x2a, 0x14, 0x64, 0x0a, 0x30, 0x47, 0xf5, 0x3c, 0x8e, 0xcd, 0x0b, 0x30, 0x9e,
-	0xea, 0x61, 0x52, 0x8d, 0x81, 0x47, 0xd7, 0x66, 0xa1, 0xb8, 0x37, 0x7c, 0x8b, 0x7c, 0x0b, 0xa3,
-	0x9d, 0x09, 0x10, 0xd1, 0x7b, 0xf2, 0xbe, 0x91, 0x1c, 0x8f, 0x85, 0xe3, 0xda, 0xa7, 0xe6, 0x2e,
-	0xa7, 0x22, 0x43, 0xb3, 0xed, 0x86, 0xc4, 0xb1, 0xf8, 0x33, 0x18, 0xce, 0x51, 0x7d, 0x9f, 0xf1,
-	0x78, 0xad, 0x35, 0xe7, 0xe4, 0x5f, 0xc0, 0xa8, 0x94, 0x9b, 0xe9, 0x41, 0x46, 0xb6, 0xa0, 0x98,
-	0x32, 0xb5, 0x76, 0x5e, 0x50, 0xa9, 0x9c, 0xdc, 0x9e, 0xef, 0x26, 0xe7, 0xb1, 0xf8, 0x53, 0xf0,
-	0x67, 0x9c, 0xdd, 0xa5, 0x62, 0x83, 0xc9, 0x39, 0xad, 0xeb, 0x53, 0x2e, 0x5f, 0x72, 0x9e, 0x5d,
-	0x20, 0x2e, 0x5f, 0x77, 0x79, 0x4e, 0xfc, 0xb9, 0xe9, 0xd2, 0x0c, 0xc5, 0x57, 0xdb, 0x1c, 0x85,
-	0x24, 0x56, 0x52, 0x8c, 0xc9, 0x3a, 0x2e, 0xe3, 0x39, 0x2a, 0x2b, 0x8e, 0xf0, 0x9e, 0x8a, 0xe4,
-	0x6c, 0xc9, 0x14, 0x7a, 0xe6, 0x9b, 0x4d, 0x26, 0x66, 0xe7, 0xe0, 0xcf, 0xa0, 0x16, 0xcd, 0x4b,
-	0x44, 0x21, 0x7f, 0x64, 0x77, 0xfc, 0x02, 0x8c, 0x2f, 0x78, 0x4c, 0xb3, 0x4b, 0xb4, 0x5f, 0x69,
-	0xeb, 0x76, 0x2a, 0xbb, 0xeb, 0xc9, 0x8d, 0xd1, 0xec, 0x8c, 0xfc, 0xe3, 0xaa, 0xef, 0x60, 0xb2,
-	0x37, 0xcb, 0x8b, 0xd2, 0x77, 0xf7, 0x4a, 0x8b, 0xa1, 0x74, 0x5c, 0xff, 0x0d, 0xbc, 0x3d, 0x13,
-	0xa8, 0x25, 0xd5, 0xd0, 0x26, 0xef, 0x58, 0x12, 0x7b, 0x63, 0xbc, 0xee, 0x91, 0xbd, 0x72, 0xa5,
-	0x06, 0xdf, 0x75, 0x85, 0xaf, 0x56, 0xfe, 0x35, 0x8c, 0xf5, 0x5b, 0xf3, 0xdf, 0xef, 0x79, 0x0a,
-	0xbe, 0x29, 0xbc, 0xe4, 0x96, 0x45, 0xdf, 0xfc, 0x1a, 0x7e, 0xf9, 0x6f, 0x00, 0x00, 0x00, 0xff,
-	0xff, 0xfd, 0xa5, 0xc3, 0x62, 0x27, 0x0a, 0x00, 0x00,
+	// 1096 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x57, 0xcd, 0x6e, 0x23, 0x45,
+	0x10, 0xc6, 0xff, 0x76, 0xd9, 0xb1, 0xc3, 0x10, 0x60, 0xb4, 0x02, 0x29, 0x9a, 0x3d, 0x6c, 0x60,
+	0x45, 0x80, 0x5d, 0xd0, 0x0a, 0x90, 0x10, 0x6b, 0x6b, 0x49, 0x90, 0xc2, 0x2a, 0x9a, 0xec, 0x72,
+	0xe0, 0xd6, 0x9e, 0xa9, 0xd8, 0xa3, 0x8c, 0xbb, 0x67, 0xbb, 0xdb, 0x44, 0x91, 0x78, 0x1b, 0x5e,
+	0x00, 0xee, 0x3c, 0x07, 0x0f, 0xc3, 0x09, 0xf5, 0x9f, 0x67, 0x6c, 0xcf, 0xc4, 0xde, 0x1b, 0xb7,
+	0xae, 0xe9, 0xaf, 0xaa, 0xbf, 0xfa, 0xba, 0x5c, 0x5d, 0x86, 0x1e, 0xcf, 0xa2, 0xd3, 0x8c, 0x33,
+	0xc9, 0xbc, 0x06, 0xcf, 0xa2, 0xa0, 0x07, 0x9d, 0x97, 0xcb, 0x34, 0x0d, 0xf1, 0x4d, 0xf0, 0x03,
+	0xc0, 0xf3, 0x38, 0xe6, 0x28, 0x44, 0x88, 0x6f, 0x3c, 0x1f, 0x3a, 0xc4, 0x58, 0x7e, 0xed, 0xb8,
+	0x76, 0xd2, 0x0b, 0x9d, 0xa9, 0x76, 0x32, 0xa4, 0x71, 0x42, 0x67, 0x7e, 0xfd, 0xb8, 0x76, 0xd2,
+	0x0d, 0x9d, 0x19, 0x3c, 0x82, 0xd1, 0x2b, 0x76, 0x83, 0xb4, 0x10, 0xe6, 0x08, 0x5a, 0x52, 0x7d,
+	0xb2, 0x41, 0x8c, 0x11, 0x9c, 0x80, 0x77, 0x85, 0x34, 0xfe, 0x19, 0x85, 0x20, 0x33, 0x9c, 0xb0,
+	0x18, 0x15, 0xd6, 0x83, 0x66, 0xc4, 0x62, 0xd4, 0xd0, 0x41, 0xa8, 0xd7, 0xc1, 0xc7, 0xd0, 0x39,
+	0x27, 0x62, 0x6e, 0xb7, 0xe7, 0x44, 0xcc, 0x6d, 0x24, 0xbd, 0x0e, 0x1e, 0x42, 0xef, 0x1c, 0x93,
+	0xd9, 0x5c, 0x2a, 0xc0, 0x07, 0xd0, 0x9e, 0x6b, 0x43, 0x43, 0x9a, 0xa1, 0xb5, 0x82, 0x63, 0xe8,
+	0x4e, 0xee, 0xa2, 0x14, 0x2d, 0x9f, 0x48, 0xad, 0x2d, 0xc4, 0x18, 0xc1, 0x0b, 0xe8, 0x9f, 0x21,
+	0x45, 0x4e, 0x24, 0xda, 0xdc, 0x29, 0xca, 0x5b, 0xc6, 0x6f, 0x5c, 0xee, 0xd6, 0xf4, 0x3e, 0x82,
+	0x5e, 0xb6, 0x9c, 0xa6, 0x49, 0x74, 0x83, 0x77, 0x3a, 0xfb, 0x5e, 0x98, 0x7f, 0x08, 0x7e, 0x85,
+	0x43, 0x17, 0x46, 0xeb, 0x70, 0x7f, 0xac, 0x82, 0xc2, 0xf5, 0x75, 0x85, 0x3d, 0x68, 0x92, 0xe9,
+	0x94, 0xfb, 0x0d, 0x93, 0xa9, 0x5a, 0x07, 0xff, 0xd6, 0x60, 0xf8, 0x8a, 0x13, 0x2a, 0x48, 0x24,
+	0x13, 0x46, 0xad, 0x20, 0xd7, 0x9c, 0x2d, 0x9c, 0x20, 0x6a, 0xed, 0x0d, 0xa1, 0x2e, 0x99, 0x8d,
+	0x57, 0x97, 0x2c, 0xd7, 0xbf, 0x51, 0xd0, 0x5f, 0x79, 0x52, 0x26, 0xd1, 0x6f, 0x1a, 0x4f, 0xb5,
+	0x56, 0xea, 0x91, 0x05, 0x5b, 0x52, 0xe9, 0xb7, 0x8c, 0x7a, 0xc6, 0xd2, 0xa7, 0x20, 0x0a, 0xbf,
+	0xad, 0xbf, 0xea, 0xb5, 0x92, 0x41, 0x26, 0x0b, 0x14, 0x92, 0x2c, 0x32, 0xbf, 0xa3, 0x37, 0xf2,
+	0x0f, 0xea, 0x4c, 0xca, 0x68, 0x84, 0x7e, 0xd7, 0x68, 0xac, 0x0d, 0xe5, 0x23, 0x92, 0x19, 0x25,
+	0x72, 0xc9, 0xd1, 0xef, 0x19, 0xe9, 0x56, 0x1f, 0xd6, 0x85, 0x85, 0x4d, 0x61, 0xff, 0xac, 0x43,
+	0x77, 0xa5, 0x68, 0x59, 0xda, 0x0f, 0xa0, 0xcb, 0x31, 0xc2, 0xe4, 0x37, 0xe4, 0x36, 0xf9, 0x95,
+	0x9d, 0x4b, 0xd0, 0xdc, 0x94, 0x80, 0x2c, 0xd0, 0x6f, 0x59, 0x09, 0xc8, 0x02, 0x57, 0xba, 0xb7,
+	0x73, 0xdd, 0x55, 0xe4, 0x84, 0x46, 0x1c, 0x89, 0x40, 0x9d, 0x69, 0x37, 0x5c, 0xd9, 0x05, 0xc9,
+	0xba, 0xa5, 0x92, 0xf5, 0xaa, 0x24, 0x83, 0x4a, 0xc9, 0xfa, 0x95, 0x92, 0x0d, 0xee, 0x95, 0xec,
+	0x60, 0x53, 0xb2, 0xbf, 0x6b, 0x30, 0x98, 0x10, 0x1a, 0x27, 0xb1, 0x2d, 0xea, 0x32, 0xd9, 0x8e,
+	0xa0, 0x95, 0x3d, 0xc9, 0x92, 0xd8, 0x6a, 0x66, 0x8c, 0x15, 0xfd, 0x46, 0x15, 0xfd, 0x66, 0x25,
+	0xfd, 0x56, 0x25, 0xfd, 0xf6, 0xbd, 0xf4, 0x3b, 0x9b, 0xf4, 0xff, 0xa8, 0x41, 0x6f, 0x42, 0x68,
+	0x84, 0x69, 0x15, 0x77, 0xc7, 0xb2, 0x5e, 0xc5, 0xb2, 0x51, 0xc9, 0xb2, 0x59, 0xc9, 0xb2, 0x75,
+	0x2f, 0xcb, 0xf6, 0x26, 0xcb, 0xbf, 0x6a, 0xd0, 0xf9, 0x85, 0x49, 0xdc, 0xf7, 0xd7, 0xf8, 0x7f,
+	0x50, 0xf6, 0x77, 0xe8, 0x86, 0x28, 0x32, 0x46, 0x05, 0xae, 0x75, 0xdc, 0x96, 0xe9, 0xb8, 0xaa,
+	0xa8, 0x39, 0x8a, 0x65, 0x2a, 0x35, 0xef, 0x41, 0x68, 0x2d, 0xef, 0x10, 0x1a, 0xc8, 0x5d, 0x4f,
+	0x52, 0x4b, 0xd5, 0xc0, 0x62, 0x94, 0x24, 0x49, 0x85, 0xe6, 0x3d, 0x08, 0x9d, 0xa9, 0x76, 0xc4,
+	0x72, 0xaa, 0x43, 0xb7, 0x74, 0x68, 0x67, 0x06, 0xcf, 0xa0, 0x73, 0x49, 0x66, 0x68, 0xdb, 0x35,
+	0xbb, 0xbe, 0x16, 0xb8, 0x6a, 0xd7, 0xc6, 0x52, 0x29, 0xa7, 0xc9, 0x22, 0x91, 0xf6, 0x66, 0x8d,
+	0x11, 0xbc, 0xb6, 0x6f, 0xcb, 0x39, 0x4b, 0x63, 0xe4, 0xd5, 0x6f, 0x4b, 0x21, 0x6c, 0xbd, 0x3c,
+	0x6c, 0xa3, 0x18, 0xf6, 0x21, 0xf4, 0xc3, 0x24, 0x9a, 0x5f, 0x24, 0x42, 0xda, 0x90, 0x06, 0x54,
+	0x2b, 0x82, 0xc6, 0x30, 0x7c, 0x21, 0x64, 0xb2, 0x20, 0x12, 0x7f, 0x44, 0x77, 0xd9, 0xf2, 0x2e,
+	0x33, 0xc2, 0x1d, 0x84, 0x7a, 0xad, 0x64, 0x77, 0x3d, 0xc7, 0x55, 0x65, 0xfe, 0x41, 0x3d, 0x64,
+	0x97, 0x88, 0xdc, 0x3a, 0x67, 0x88, 0xdc, 0x55, 0x8a, 0x5a, 0x07, 0x2f, 0xa1, 0x3d, 0x26, 0xb4,
+	0x62, 0x57, 0xeb, 0x89, 0x11, 0xa3, 0xb1, 0x0b, 0xec, 0x4c, 0x73, 0x5b, 0x44, 0x30, 0xd7, 0xe0,
+	0xad, 0xf5, 0xe4, 0x9f, 0x3e, 0x74, 0xce, 0x38, 0xa2, 0x44, 0xee, 0x9d, 0x02, 0x9c, 0xa1, 0x7c,
+	0x1e, 0x45, 0xba, 0x39, 0x8d, 0x4e, 0xd5, 0x08, 0x90, 0x3f, 0xd1, 0x0f, 0x0e, 0xf4, 0x07, 0x57,
+	0x13, 0xc1, 0x3b, 0xde, 0xb7, 0x30, 0x2c, 0xbc, 0xce, 0x21, 0xb9, 0xf5, 0x3e, 0xd4, 0x90, 0xed,
+	0x27, 0x7b, 0xdb, 0xf7, 0x3b, 0x18, 0x5d, 0x25, 0x8b, 0x65, 0x4a, 0x24, 0x5a, 0xe8, 0x5b, 0x38,
+	0x3f, 0xd6, 0x44, 0x9d, 0xdf, 0x40, 0x6f, 0xdb, 0xd7, 0x7f, 0x1b, 0xfc, 0x19, 0x0c, 0xce, 0x50,
+	0x8e, 0x53, 0x16, 0xdd, 0x28, 0xcc, 0x2e, 0xf8, 0x97, 0x30, 0x5c, 0xc1, 0xf5, 0x58, 0xe0, 0x0d,
+	0x8d, 0x83, 0x1b, 0x1f, 0x4a, 0xe9, 0x5c, 0x10, 0x21, 0x2d, 0xdc, 0xc4, 0xb7, 0xc3, 0xd2, 0x36,
+	0xf8, 0x53, 0xe8, 0x4d, 0x18, 0xbd, 0x4e, 0xf8, 0x02, 0xe3, 0x5d, 0x58, 0x9b, 0xa7, 0x98, 0x5d,
+	0x32, 0x96, 0xee, 0x02, 0x7f, 0xae, 0x89, 0x2b, 0xe4, 0x9e, 0xc2, 0x7c, 0x05, 0x87, 0xd6, 0x61,
+	0x7c, 0x67, 0xaf, 0x79, 0x8f, 0x4b, 0x7f, 0x0a, 0xfd, 0x42, 0x8d, 0x7b, 0xef, 0xe9, 0xfd, 0xf5,
+	0xaa, 0x2f, 0x4d, 0x64, 0xf5, 0xc6, 0x88, 0x5d, 0x89, 0x7c, 0xa1, 0x13, 0xd1, 0x93, 0xd8, 0xd5,
+	0x32, 0x43, 0x2e, 0x3c, 0x03, 0x71, 0xb3, 0x59, 0xd9, 0x9d, 0x8d, 0xce, 0x50, 0x1a, 0x70, 0x88,
+	0xb7, 0x84, 0xc7, 0x3b, 0x5d, 0x4e, 0xa1, 0xa5, 0xdb, 0x84, 0x77, 0xa4, 0x77, 0x36, 0xc6, 0xd1,
+	0xf2, 0x3b, 0x4e, 0x84, 0xd4, 0x38, 0x97, 0x81, 0x6d, 0x50, 0xdb, 0xe0, 0xaf, 0x61, 0x50, 0xec,
+	0x41, 0xc5, 0x33, 0xf2, 0xb6, 0x54, 0x56, 0xa9, 0x5d, 0xd7, 0x63, 0xbc, 0x43, 0xb3, 0x99, 0xb7,
+	0x9c, 0xd2, 0x4a, 0x52, 0x9d, 0x42, 0xfc, 0x44, 0xaf, 0xd9, 0x1e, 0x55, 0x77, 0xc1, 0x22, 0x92,
+	0xee, 0x83, 0xfd, 0x04, 0xba, 0xea, 0xcc, 0x31, 0xa1, 0x3b, 0xaf, 0xea, 0x11, 0x74, 0xc6, 0x84,
+	0x2a, 0x16, 0x5e, 0x5f, 0xef, 0x99, 0xde, 0x54, 0x7a, 0xfe, 0x6b, 0x3a, 0xb5, 0x50, 0xab, 0x9e,
+	0xe9, 0x72, 0x65, 0x75, 0x39, 0x72, 0xd3, 0xb1, 0x2b, 0x4b, 0xa3, 0x46, 0x61, 0xf4, 0xde, 0xf6,
+	0xfa, 0x1e, 0x8e, 0xd6, 0x66, 0x6a, 0xe7, 0xfa, 0xfe, 0x9a, 0xab, 0x1b, 0x0e, 0xb7, 0xfd, 0xbf,
+	0x81, 0x77, 0x27, 0x1c, 0x15, 0x24, 0x1f, 0x9e, 0x6d, 0x75, 0xaf, 0x8f, 0xd3, 0x65, 0xf7, 0xd6,
+	0xb7, 0xae, 0xba, 0xa2, 0x0e, 0xf2, 0xdb, 0x2e, 0x85, 0x3f, 0x83, 0x91, 0x6a, 0x72, 0x6f, 0x7f,
+	0xce, 0x63, 0xe8, 0x69, 0xc7, 0x7d, 0x4e, 0x99, 0xb6, 0xf5, 0x9f, 0xb7, 0xa7, 0xff, 0x0d, 0x00,
+	0x8d, 0xd2, 0xdf, 0xee, 0xc9, 0x0d, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1026,26 +1424,63 @@ const _ = grpc.SupportPackageIsVersion4
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type GreeterClient interface {
-	// Sends a greeting
+	// Get account information
 	GetAccount(ctx context.Context, in *AddressReq, opts ...grpc.CallOption) (*Response, error)
+	// Send a signed message
 	SendMessageRaw(ctx context.Context, in *SendMessageCodeReq, opts ...grpc.CallOption) (*Response, error)
+	// Simulate a signed message without sending it
+	SimulateMessage(ctx context.Context, in *SendMessageCodeReq, opts ...grpc.CallOption) (*Response, error)
+	// Query message
 	GetMessage(ctx context.Context, in *HashReq, opts ...grpc.CallOption) (*Response, error)
+	// Query block using hash
 	GetBlockHash(ctx context.Context, in *HashReq, opts ...grpc.CallOption) (*Response, error)
+	// Query block using height
 	GetBlockHeight(ctx context.Context, in *HeightReq, opts ...grpc.CallOption) (*Response, error)
+	// The final height
 	LastHeight(ctx context.Context, in *NullReq, opts ...grpc.CallOption) (*Response, error)
+	// Confirmed height
 	Confirmed(ctx context.Context, in *NullReq, opts ...grpc.CallOption) (*Response, error)
+	// Get message pool information
 	GetMsgPool(ctx context.Context, in *NullReq, opts ...grpc.CallOption) (*Response, error)
+	// Get a message in the pool and the fee required to replace it
+	GetPoolMessage(ctx context.Context, in *HashReq, opts ...grpc.CallOption) (*Response, error)
+	// Get the messages of an address in the pool
+	GetPoolByAddress(ctx context.Context, in *AddressReq, opts ...grpc.CallOption) (*Response, error)
+	// Estimate the fee of a message
+	EstimateFee(ctx context.Context, in *EstimateFeeReq, opts ...grpc.CallOption) (*Response, error)
+	// Get candidates information
 	Candidates(ctx context.Context, in *NullReq, opts ...grpc.CallOption) (*Response, error)
 	GetCycleSupers(ctx context.Context, in *CycleReq, opts ...grpc.CallOption) (*Response, error)
+	// Get reward information
 	GetSupersReward(ctx context.Context, in *CycleReq, opts ...grpc.CallOption) (*Response, error)
+	// Get token information
 	Token(ctx context.Context, in *TokenAddressReq, opts ...grpc.CallOption) (*Response, error)
+	// List tokens
+	ListTokens(ctx context.Context, in *PageReq, opts ...grpc.CallOption) (*Response, error)
+	// Get the holders of token
+	TokenHolders(ctx context.Context, in *TokenHoldersReq, opts ...grpc.CallOption) (*Response, error)
+	// Get the top holders of main token
+	RichList(ctx context.Context, in *RichListReq, opts ...grpc.CallOption) (*Response, error)
+	// Get peer information
 	PeersInfo(ctx context.Context, in *NullReq, opts ...grpc.CallOption) (*Response, error)
+	// Get local node information
 	LocalInfo(ctx context.Context, in *NullReq, opts ...grpc.CallOption) (*Response, error)
+	// Get the banned peers
+	ListBans(ctx context.Context, in *NullReq, opts ...grpc.CallOption) (*Response, error)
+	// Ban a peer
+	BanPeer(ctx context.Context, in *BanReq, opts ...grpc.CallOption) (*Response, error)
+	// Unban a peer
+	UnbanPeer(ctx context.Context, in *PeerReq, opts ...grpc.CallOption) (*Response, error)
+	// To generate address
 	GenerateAddress(ctx context.Context, in *GenerateReq, opts ...grpc.CallOption) (*Response, error)
+	// To generate token address
 	GenerateTokenAddress(ctx context.Context, in *GenerateTokenReq, opts ...grpc.CallOption) (*Response, error)
+	// Create a transaction
 	CreateTransaction(ctx context.Context, in *TransactionReq, opts ...grpc.CallOption) (*Response, error)
+	// Create a token
 	CreateToken(ctx context.Context, in *TokenReq, opts ...grpc.CallOption) (*Response, error)
 	SendTransaction(ctx context.Context, in *TransactionReq, opts ...grpc.CallOption) (*Response, error)
+	// Send a Token
 	SendToken(ctx context.Context, in *TokenReq, opts ...grpc.CallOption) (*Response, error)
 }
 
@@ -1075,6 +1510,15 @@ func (c *greeterClient) SendMessageRaw(ctx context.Context, in *SendMessageCodeR
 	return out, nil
 }
 
+func (c *greeterClient) SimulateMessage(ctx context.Context, in *SendMessageCodeReq, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.Greeter/SimulateMessage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *greeterClient) GetMessage(ctx context.Context, in *HashReq, opts ...grpc.CallOption) (*Response, error) {
 	out := new(Response)
 	err := c.cc.Invoke(ctx, "/rpc.Greeter/GetMessage", in, out, opts...)
@@ -1129,6 +1573,33 @@ func (c *greeterClient) GetMsgPool(ctx context.Context, in *NullReq, opts ...grp
 	return out, nil
 }
 
+func (c *greeterClient) GetPoolMessage(ctx context.Context, in *HashReq, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.Greeter/GetPoolMessage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) GetPoolByAddress(ctx context.Context, in *AddressReq, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.Greeter/GetPoolByAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) EstimateFee(ctx context.Context, in *EstimateFeeReq, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.Greeter/EstimateFee", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *greeterClient) Candidates(ctx context.Context, in *NullReq, opts ...grpc.CallOption) (*Response, error) {
 	out := new(Response)
 	err := c.cc.Invoke(ctx, "/rpc.Greeter/Candidates", in, out, opts...)
@@ -1165,6 +1636,33 @@ func (c *greeterClient) Token(ctx context.Context, in *TokenAddressReq, opts ...
 	return out, nil
 }
 
+func (c *greeterClient) ListTokens(ctx context.Context, in *PageReq, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.Greeter/ListTokens", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) TokenHolders(ctx context.Context, in *TokenHoldersReq, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.Greeter/TokenHolders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) RichList(ctx context.Context, in *RichListReq, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.Greeter/RichList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *greeterClient) PeersInfo(ctx context.Context, in *NullReq, opts ...grpc.CallOption) (*Response, error) {
 	out := new(Response)
 	err := c.cc.Invoke(ctx, "/rpc.Greeter/PeersInfo", in, out, opts...)
@@ -1183,6 +1681,33 @@ func (c *greeterClient) LocalInfo(ctx context.Context, in *NullReq, opts ...grpc
 	return out, nil
 }
 
+func (c *greeterClient) ListBans(ctx context.Context, in *NullReq, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.Greeter/ListBans", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) BanPeer(ctx context.Context, in *BanReq, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.Greeter/BanPeer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) UnbanPeer(ctx context.Context, in *PeerReq, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.Greeter/UnbanPeer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *greeterClient) GenerateAddress(ctx context.Context, in *GenerateReq, opts ...grpc.CallOption) (*Response, error) {
 	out := new(Response)
 	err := c.cc.Invoke(ctx, "/rpc.Greeter/GenerateAddress", in, out, opts...)
@@ -1239,26 +1764,63 @@ func (c *greeterClient) SendToken(ctx context.Context, in *TokenReq, opts ...grp
 
 // GreeterServer is the server API for Greeter service.
 type GreeterServer interface {
-	// Sends a greeting
+	// Get account information
 	GetAccount(context.Context, *AddressReq) (*Response, error)
+	// Send a signed message
 	SendMessageRaw(context.Context, *SendMessageCodeReq) (*Response, error)
+	// Simulate a signed message without sending it
+	SimulateMessage(context.Context, *SendMessageCodeReq) (*Response, error)
+	// Query message
 	GetMessage(context.Context, *HashReq) (*Response, error)
+	// Query block using hash
 	GetBlockHash(context.Context, *HashReq) (*Response, error)
+	// Query block using height
 	GetBlockHeight(context.Context, *HeightReq) (*Response, error)
+	// The final height
 	LastHeight(context.Context, *NullReq) (*Response, error)
+	// Confirmed height
 	Confirmed(context.Context, *NullReq) (*Response, error)
+	// Get message pool information
 	GetMsgPool(context.Context, *NullReq) (*Response, error)
+	// Get a message in the pool and the fee required to replace it
+	GetPoolMessage(context.Context, *HashReq) (*Response, error)
+	// Get the messages of an address in the pool
+	GetPoolByAddress(context.Context, *AddressReq) (*Response, error)
+	// Estimate the fee of a message
+	EstimateFee(context.Context, *EstimateFeeReq) (*Response, error)
+	// Get candidates information
 	Candidates(context.Context, *NullReq) (*Response, error)
 	GetCycleSupers(context.Context, *CycleReq) (*Response, error)
+	// Get reward information
 	GetSupersReward(context.Context, *CycleReq) (*Response, error)
+	// Get token information
 	Token(context.Context, *TokenAddressReq) (*Response, error)
+	// List tokens
+	ListTokens(context.Context, *PageReq) (*Response, error)
+	// Get the holders of token
+	TokenHolders(context.Context, *TokenHoldersReq) (*Response, error)
+	// Get the top holders of main token
+	RichList(context.Context, *RichListReq) (*Response, error)
+	// Get peer information
 	PeersInfo(context.Context, *NullReq) (*Response, error)
+	// Get local node information
 	LocalInfo(context.Context, *NullReq) (*Response, error)
+	// Get the banned peers
+	ListBans(context.Context, *NullReq) (*Response, error)
+	// Ban a peer
+	BanPeer(context.Context, *BanReq) (*Response, error)
+	// Unban a peer
+	UnbanPeer(context.Context, *PeerReq) (*Response, error)
+	// To generate address
 	GenerateAddress(context.Context, *GenerateReq) (*Response, error)
+	// To generate token address
 	GenerateTokenAddress(context.Context, *GenerateTokenReq) (*Response, error)
+	// Create a transaction
 	CreateTransaction(context.Context, *TransactionReq) (*Response, error)
+	// Create a token
 	CreateToken(context.Context, *TokenReq) (*Response, error)
 	SendTransaction(context.Context, *TransactionReq) (*Response, error)
+	// Send a Token
 	SendToken(context.Context, *TokenReq) (*Response, error)
 }
 
@@ -1272,6 +1834,9 @@ func (*UnimplementedGreeterServer) GetAccount(ctx context.Context, req *AddressR
 func (*UnimplementedGreeterServer) SendMessageRaw(ctx context.Context, req *SendMessageCodeReq) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendMessageRaw not implemented")
 }
+func (*UnimplementedGreeterServer) SimulateMessage(ctx context.Context, req *SendMessageCodeReq) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SimulateMessage not implemented")
+}
 func (*UnimplementedGreeterServer) GetMessage(ctx context.Context, req *HashReq) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMessage not implemented")
 }
@@ -1290,6 +1855,15 @@ func (*UnimplementedGreeterServer) Confirmed(ctx context.Context, req *NullReq)
 func (*UnimplementedGreeterServer) GetMsgPool(ctx context.Context, req *NullReq) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMsgPool not implemented")
 }
+func (*UnimplementedGreeterServer) GetPoolMessage(ctx context.Context, req *HashReq) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPoolMessage not implemented")
+}
+func (*UnimplementedGreeterServer) GetPoolByAddress(ctx context.Context, req *AddressReq) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPoolByAddress not implemented")
+}
+func (*UnimplementedGreeterServer) EstimateFee(ctx context.Context, req *EstimateFeeReq) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EstimateFee not implemented")
+}
 func (*UnimplementedGreeterServer) Candidates(ctx context.Context, req *NullReq) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Candidates not implemented")
 }
@@ -1302,12 +1876,30 @@ func (*UnimplementedGreeterServer) GetSupersReward(ctx context.Context, req *Cyc
 func (*UnimplementedGreeterServer) Token(ctx context.Context, req *TokenAddressReq) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Token not implemented")
 }
+func (*UnimplementedGreeterServer) ListTokens(ctx context.Context, req *PageReq) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTokens not implemented")
+}
+func (*UnimplementedGreeterServer) TokenHolders(ctx context.Context, req *TokenHoldersReq) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TokenHolders not implemented")
+}
+func (*UnimplementedGreeterServer) RichList(ctx context.Context, req *RichListReq) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RichList not implemented")
+}
 func (*UnimplementedGreeterServer) PeersInfo(ctx context.Context, req *NullReq) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PeersInfo not implemented")
 }
 func (*UnimplementedGreeterServer) LocalInfo(ctx context.Context, req *NullReq) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LocalInfo not implemented")
 }
+func (*UnimplementedGreeterServer) ListBans(ctx context.Context, req *NullReq) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBans not implemented")
+}
+func (*UnimplementedGreeterServer) BanPeer(ctx context.Context, req *BanReq) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BanPeer not implemented")
+}
+func (*UnimplementedGreeterServer) UnbanPeer(ctx context.Context, req *PeerReq) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnbanPeer not implemented")
+}
 func (*UnimplementedGreeterServer) GenerateAddress(ctx context.Context, req *GenerateReq) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GenerateAddress not implemented")
 }
@@ -1367,6 +1959,24 @@ func _Greeter_SendMessageRaw_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Greeter_SimulateMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendMessageCodeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).SimulateMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Greeter/SimulateMessage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).SimulateMessage(ctx, req.(*SendMessageCodeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Greeter_GetMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HashReq)
 	if err := dec(in); err != nil {
@@ -1475,6 +2085,60 @@ func _Greeter_GetMsgPool_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Greeter_GetPoolMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HashReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).GetPoolMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Greeter/GetPoolMessage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).GetPoolMessage(ctx, req.(*HashReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_GetPoolByAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).GetPoolByAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Greeter/GetPoolByAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).GetPoolByAddress(ctx, req.(*AddressReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_EstimateFee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EstimateFeeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).EstimateFee(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Greeter/EstimateFee",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).EstimateFee(ctx, req.(*EstimateFeeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Greeter_Candidates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(NullReq)
 	if err := dec(in); err != nil {
@@ -1547,6 +2211,60 @@ func _Greeter_Token_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Greeter_ListTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PageReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).ListTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Greeter/ListTokens",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).ListTokens(ctx, req.(*PageReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_TokenHolders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TokenHoldersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).TokenHolders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Greeter/TokenHolders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).TokenHolders(ctx, req.(*TokenHoldersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_RichList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RichListReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).RichList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Greeter/RichList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).RichList(ctx, req.(*RichListReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Greeter_PeersInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(NullReq)
 	if err := dec(in); err != nil {
@@ -1583,6 +2301,60 @@ func _Greeter_LocalInfo_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Greeter_ListBans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NullReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).ListBans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Greeter/ListBans",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).ListBans(ctx, req.(*NullReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_BanPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).BanPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Greeter/BanPeer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).BanPeer(ctx, req.(*BanReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_UnbanPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).UnbanPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Greeter/UnbanPeer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).UnbanPeer(ctx, req.(*PeerReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Greeter_GenerateAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GenerateReq)
 	if err := dec(in); err != nil {
@@ -1703,6 +2475,10 @@ var _Greeter_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SendMessageRaw",
 			Handler:    _Greeter_SendMessageRaw_Handler,
 		},
+		{
+			MethodName: "SimulateMessage",
+			Handler:    _Greeter_SimulateMessage_Handler,
+		},
 		{
 			MethodName: "GetMessage",
 			Handler:    _Greeter_GetMessage_Handler,
@@ -1727,6 +2503,18 @@ var _Greeter_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetMsgPool",
 			Handler:    _Greeter_GetMsgPool_Handler,
 		},
+		{
+			MethodName: "GetPoolMessage",
+			Handler:    _Greeter_GetPoolMessage_Handler,
+		},
+		{
+			MethodName: "GetPoolByAddress",
+			Handler:    _Greeter_GetPoolByAddress_Handler,
+		},
+		{
+			MethodName: "EstimateFee",
+			Handler:    _Greeter_EstimateFee_Handler,
+		},
 		{
 			MethodName: "Candidates",
 			Handler:    _Greeter_Candidates_Handler,
@@ -1743,6 +2531,18 @@ var _Greeter_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Token",
 			Handler:    _Greeter_Token_Handler,
 		},
+		{
+			MethodName: "ListTokens",
+			Handler:    _Greeter_ListTokens_Handler,
+		},
+		{
+			MethodName: "TokenHolders",
+			Handler:    _Greeter_TokenHolders_Handler,
+		},
+		{
+			MethodName: "RichList",
+			Handler:    _Greeter_RichList_Handler,
+		},
 		{
 			MethodName: "PeersInfo",
 			Handler:    _Greeter_PeersInfo_Handler,
@@ -1751,6 +2551,18 @@ var _Greeter_serviceDesc = grpc.ServiceDesc{
 			MethodName: "LocalInfo",
 			Handler:    _Greeter_LocalInfo_Handler,
 		},
+		{
+			MethodName: "ListBans",
+			Handler:    _Greeter_ListBans_Handler,
+		},
+		{
+			MethodName: "BanPeer",
+			Handler:    _Greeter_BanPeer_Handler,
+		},
+		{
+			MethodName: "UnbanPeer",
+			Handler:    _Greeter_UnbanPeer_Handler,
+		},
 		{
 			MethodName: "GenerateAddress",
 			Handler:    _Greeter_GenerateAddress_Handler,
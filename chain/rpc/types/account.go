@@ -3,7 +3,9 @@ package types
 import (
 	"github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/tools/amount"
+	"github.com/aiot-network/aiotchain/tools/arry"
 	types2 "github.com/aiot-network/aiotchain/types"
+	"sort"
 )
 
 type Account struct {
@@ -12,6 +14,65 @@ type Account struct {
 	Tokens    Tokens    `json:"tokens"`
 	Confirmed uint64    `json:"confirmed"`
 	Works     *RpcWorks `json:"work"`
+	// The messages of the account in the pool, only filled when requested
+	Pending *PendingState `json:"pending,omitempty"`
+}
+
+// The nonce value of the next message of the account
+func (a *Account) NextNonce() uint64 {
+	if a.Pending != nil {
+		return a.Pending.Nonce
+	}
+	return a.Nonce + 1
+}
+
+type PendingState struct {
+	// The next usable nonce after the pending messages
+	Nonce    uint64          `json:"nonce"`
+	Count    int             `json:"count"`
+	Outgoing []*PendingToken `json:"outgoing"`
+}
+
+// The amount of the token to be spent by the pending messages, including fees
+type PendingToken struct {
+	Address string  `json:"address"`
+	Amount  float64 `json:"amount"`
+}
+
+func NewPendingState(nonce uint64, msgs []types2.IMessage, mainToken arry.Address, decimals func(token string) uint8) *PendingState {
+	sort.Slice(msgs, func(i, j int) bool {
+		return msgs[i].Nonce() < msgs[j].Nonce()
+	})
+	next := nonce + 1
+	var tokens []string
+	amounts := make(map[string]uint64)
+	add := func(token string, amount uint64) {
+		if _, ok := amounts[token]; !ok {
+			tokens = append(tokens, token)
+		}
+		amounts[token] += amount
+	}
+	for _, msg := range msgs {
+		if msg.Nonce() == next {
+			next++
+		}
+		add(mainToken.String(), msg.Fee())
+		if msgAmount := msg.MsgBody().MsgAmount(); msgAmount > 0 {
+			add(msg.MsgBody().MsgToken().String(), msgAmount)
+		}
+	}
+	outgoing := make([]*PendingToken, len(tokens))
+	for i, token := range tokens {
+		outgoing[i] = &PendingToken{
+			Address: token,
+			Amount:  amount.Amount(amounts[token]).ToDecimals(decimals(token)),
+		}
+	}
+	return &PendingState{
+		Nonce:    next,
+		Count:    len(msgs),
+		Outgoing: outgoing,
+	}
 }
 
 type RpcWorks struct {
@@ -30,14 +91,16 @@ type TokenAccount struct {
 // List of secondary accounts
 type Tokens []*TokenAccount
 
-func ToRpcAccount(a *types.Account) *Account {
+// The balance of each token is formatted with the decimals of the token
+func ToRpcAccount(a *types.Account, decimals func(token string) uint8) *Account {
 	tokens := make(Tokens, len(a.Tokens))
 	for i, t := range a.Tokens {
+		d := decimals(t.Address)
 		tokens[i] = &TokenAccount{
 			Pledge:   amount.Amount(t.Pledge).ToCoin(),
 			Address:  t.Address,
-			Balance:  amount.Amount(t.Balance).ToCoin(),
-			LockedIn: amount.Amount(t.LockedIn).ToCoin(),
+			Balance:  amount.Amount(t.Balance).ToDecimals(d),
+			LockedIn: amount.Amount(t.LockedIn).ToDecimals(d),
 		}
 	}
 	return &Account{
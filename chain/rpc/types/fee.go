@@ -0,0 +1,52 @@
+package types
+
+import "sort"
+
+type FeeEstimate struct {
+	MinFee       uint64 `json:"minfee"`
+	Low          uint64 `json:"low"`
+	Medium       uint64 `json:"medium"`
+	High         uint64 `json:"high"`
+	Recommended  uint64 `json:"recommended"`
+	Blocks       uint64 `json:"blocks"`
+	Samples      int    `json:"samples"`
+	PoolCount    int    `json:"poolcount"`
+	PoolCapacity int    `json:"poolcapacity"`
+}
+
+// Estimate the fees through the fee of each receiver observed in recent
+// blocks, the more the message pool is occupied, the higher the fee recommended
+func NewFeeEstimate(minFee uint64, receivers int, fees []uint64, blocks uint64, poolCount, poolCapacity int) *FeeEstimate {
+	sort.Slice(fees, func(i, j int) bool {
+		return fees[i] < fees[j]
+	})
+	percentile := func(p int) uint64 {
+		if len(fees) == 0 {
+			return minFee
+		}
+		fee := fees[(len(fees)-1)*p/100] * uint64(receivers)
+		if fee < minFee {
+			return minFee
+		}
+		return fee
+	}
+	estimate := &FeeEstimate{
+		MinFee:       minFee,
+		Low:          percentile(25),
+		Medium:       percentile(50),
+		High:         percentile(90),
+		Blocks:       blocks,
+		Samples:      len(fees),
+		PoolCount:    poolCount,
+		PoolCapacity: poolCapacity,
+	}
+	switch {
+	case poolCount*4 < poolCapacity:
+		estimate.Recommended = estimate.Low
+	case poolCount*4 < poolCapacity*3:
+		estimate.Recommended = estimate.Medium
+	default:
+		estimate.Recommended = estimate.High
+	}
+	return estimate
+}
@@ -36,3 +36,63 @@ func MsgsToRpcMsgsPool(readyMsgs []types.IMessage, cacheMsgs []types.IMessage) *
 		CacheMsgs:  cacheRpcMsgs,
 	}
 }
+
+const (
+	PoolReady  = "ready"
+	PoolFuture = "future"
+)
+
+// A message in the pool, it can be replaced by a message of
+// the same nonce with a fee not lower than ReplaceFee
+type PoolMessage struct {
+	Message    *chaintypes.RpcMessage `json:"message"`
+	State      string                 `json:"state"`
+	ReplaceFee uint64                 `json:"replacefee"`
+	FeeBump    uint64                 `json:"feebump"`
+}
+
+func NewPoolMessage(msg types.IMessage, state string) *PoolMessage {
+	rpcMsg, _ := chaintypes.MsgToRpcMsg(msg.(*chaintypes.Message))
+	replaceFee := chaintypes.ReplaceFee(msg.Fee())
+	return &PoolMessage{
+		Message:    rpcMsg,
+		State:      state,
+		ReplaceFee: replaceFee,
+		FeeBump:    replaceFee - msg.Fee(),
+	}
+}
+
+type AddressPool struct {
+	Address string         `json:"address"`
+	Nonce   uint64         `json:"nonce"`
+	Ready   []*PoolMessage `json:"ready"`
+	Future  []*PoolMessage `json:"future"`
+}
+
+func NewAddressPool(address string, nonce uint64, readyMsgs, futureMsgs []types.IMessage) *AddressPool {
+	pool := &AddressPool{
+		Address: address,
+		Nonce:   nonce,
+		Ready:   make([]*PoolMessage, 0, len(readyMsgs)),
+		Future:  make([]*PoolMessage, 0, len(futureMsgs)),
+	}
+	for _, msg := range readyMsgs {
+		pool.Ready = append(pool.Ready, NewPoolMessage(msg, PoolReady))
+	}
+	for _, msg := range futureMsgs {
+		pool.Future = append(pool.Future, NewPoolMessage(msg, PoolFuture))
+	}
+	return pool
+}
+
+// Find the message of the nonce
+func (a *AddressPool) Find(nonce uint64) (*PoolMessage, bool) {
+	for _, list := range [][]*PoolMessage{a.Ready, a.Future} {
+		for _, msg := range list {
+			if msg.Message.MsgHeader.Nonce == nonce {
+				return msg, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"github.com/aiot-network/aiotchain/chain/types"
+	"github.com/aiot-network/aiotchain/tools/amount"
+)
+
+const (
+	// Stages at which the simulation of a message can fail
+	StageCheck  = "check"
+	StageStatus = "status"
+	StageApply  = "apply"
+)
+
+type Simulation struct {
+	MsgHash  string           `json:"msghash"`
+	Valid    bool             `json:"valid"`
+	Stage    string           `json:"stage,omitempty"`
+	Code     int32            `json:"code,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Details  types.ErrDetails `json:"details,omitempty"`
+	Accounts []*AccountDelta  `json:"accounts"`
+}
+
+func (s *Simulation) Fail(stage string, err error) {
+	s.Valid = false
+	s.Stage = stage
+	s.Error = err.Error()
+	if msgErr, ok := types.AsMsgError(err); ok {
+		s.Code = int32(msgErr.Code)
+		s.Details = msgErr.Details
+	}
+}
+
+type AccountDelta struct {
+	Address string        `json:"address"`
+	Nonce   uint64        `json:"nonce"`
+	Tokens  []*TokenDelta `json:"tokens"`
+}
+
+type TokenDelta struct {
+	Address   string  `json:"address"`
+	Balance   float64 `json:"balance"`
+	LockedIn  float64 `json:"locked"`
+	LockedOut float64 `json:"lockedout"`
+}
+
+// The changes of every token of the account after the message is applied,
+// tokens that have not changed are not listed
+func NewAccountDelta(address string, before, after *types.Account, decimals func(token string) uint8) *AccountDelta {
+	delta := &AccountDelta{
+		Address: address,
+		Nonce:   after.Nonce,
+		Tokens:  make([]*TokenDelta, 0),
+	}
+	tokens := make([]string, 0)
+	for _, t := range before.Tokens {
+		tokens = append(tokens, t.Address)
+	}
+	for _, t := range after.Tokens {
+		if _, ok := before.Tokens.Get(t.Address); !ok {
+			tokens = append(tokens, t.Address)
+		}
+	}
+	for _, token := range tokens {
+		b, _ := before.Tokens.Get(token)
+		a, _ := after.Tokens.Get(token)
+		if a.Balance == b.Balance && a.LockedIn == b.LockedIn && a.LockedOut == b.LockedOut {
+			continue
+		}
+		d := decimals(token)
+		delta.Tokens = append(delta.Tokens, &TokenDelta{
+			Address:   token,
+			Balance:   amountDelta(b.Balance, a.Balance, d),
+			LockedIn:  amountDelta(b.LockedIn, a.LockedIn, d),
+			LockedOut: amountDelta(b.LockedOut, a.LockedOut, d),
+		})
+	}
+	return delta
+}
+
+func amountDelta(before, after uint64, decimals uint8) float64 {
+	if after >= before {
+		return amount.Amount(after - before).ToDecimals(decimals)
+	}
+	return -amount.Amount(before - after).ToDecimals(decimals)
+}
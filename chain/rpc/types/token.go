@@ -10,12 +10,25 @@ type RpcToken struct {
 	Sender         string    `json:"sender"`
 	Name           string    `json:"name"`
 	Shorthand      string    `json:"shorthand"`
+	Decimals       uint8     `json:"decimals"`
+	URI            string    `json:"uri"`
+	Description    string    `json:"description"`
 	IncreaseIssues bool      `json:"increaseissues"`
 	PledgeRate     int       `json:"pledgerate"`
 	PledgeAmount   float64   `json:"pledgeamount"`
+	Owner          string    `json:"owner"`
+	MaxSupply      float64   `json:"maxsupply"`
+	Policy         *Policy   `json:"policy"`
 	Records        []*Record `json:"records"`
 }
 
+type Policy struct {
+	Paused        bool     `json:"paused"`
+	AllowlistOnly bool     `json:"allowlistonly"`
+	Frozen        []string `json:"frozen"`
+	Allowlist     []string `json:"allowlist"`
+}
+
 type Record struct {
 	Height   uint64  `json:"height"`
 	Type     string  `json:"type"`
@@ -31,10 +44,29 @@ func TokenToRpcToken(token *types.TokenRecord) *RpcToken {
 		Sender:         token.Sender.String(),
 		Name:           token.Name,
 		Shorthand:      token.Shorthand,
+		Decimals:       token.Meta.GetDecimals(),
+		URI:            token.Meta.GetURI(),
+		Description:    token.Meta.GetDescription(),
 		IncreaseIssues: token.IncreaseIssues,
 		PledgeRate:     int(token.PledgeRate),
 		PledgeAmount:   amount.Amount(token.PledgeAmount).ToCoin(),
-		Records:        make([]*Record, token.Records.Len()),
+		Owner:          token.OwnerAddress().String(),
+		MaxSupply:      amount.Amount(token.MaxSupply).ToDecimals(token.Meta.GetDecimals()),
+		Policy: &Policy{
+			Frozen:    make([]string, 0),
+			Allowlist: make([]string, 0),
+		},
+		Records: make([]*Record, token.Records.Len()),
+	}
+	if token.Policy != nil {
+		rpcToken.Policy.Paused = token.Policy.Paused
+		rpcToken.Policy.AllowlistOnly = token.Policy.AllowlistOnly
+		for _, addr := range token.Policy.FrozenAddrs {
+			rpcToken.Policy.Frozen = append(rpcToken.Policy.Frozen, addr.String())
+		}
+		for _, addr := range token.Policy.AllowedAddrs {
+			rpcToken.Policy.Allowlist = append(rpcToken.Policy.Allowlist, addr.String())
+		}
 	}
 	for i, record := range *token.Records {
 		rpcToken.Records[i] = &Record{
@@ -43,8 +75,34 @@ func TokenToRpcToken(token *types.TokenRecord) *RpcToken {
 			MsgHash:  record.MsgHash.String(),
 			Receiver: record.Receiver.String(),
 			Time:     record.Time,
-			Amount:   amount.Amount(record.Amount).ToCoin(),
+			Amount:   amount.Amount(record.Amount).ToDecimals(token.Meta.GetDecimals()),
 		}
 	}
 	return rpcToken
 }
+
+type RpcTokenList struct {
+	Count  uint64      `json:"count"`
+	Tokens []*RpcToken `json:"tokens"`
+}
+
+type Holder struct {
+	Address string  `json:"address"`
+	Balance float64 `json:"balance"`
+	Locked  float64 `json:"locked"`
+}
+
+type RpcTokenHolders struct {
+	Token   string    `json:"token"`
+	Count   uint64    `json:"count"`
+	Holders []*Holder `json:"holders"`
+}
+
+func AccountToHolder(account *types.Account, token string, decimals uint8) *Holder {
+	holder := &Holder{Address: account.Address.String()}
+	if tokenAccount, ok := account.Tokens.Get(token); ok {
+		holder.Balance = amount.Amount(tokenAccount.Balance).ToDecimals(decimals)
+		holder.Locked = amount.Amount(tokenAccount.LockedIn).ToDecimals(decimals)
+	}
+	return holder
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/aiot-network/aiotchain/tools/arry"
 	"github.com/aiot-network/aiotchain/tools/rlp"
 	"github.com/aiot-network/aiotchain/types"
+	"sort"
 )
 
 type Account struct {
@@ -65,6 +66,14 @@ func (a *Account) UpdateLocked(confirmed uint64) error {
 			} else {
 				return errors.New("locked out amount not enough when update account journal")
 			}
+			for _, other := range out.Others {
+				otherAccount, ok := a.Tokens.Get(other.TokenAddress)
+				if !ok || otherAccount.LockedOut < other.Amount {
+					return errors.New("locked out amount not enough when update account journal")
+				}
+				otherAccount.LockedOut -= other.Amount
+				a.Tokens.Set(otherAccount)
+			}
 			a.JournalOut.Remove(out.Height)
 
 		} else {
@@ -98,7 +107,7 @@ func (a *Account) UpdateLocked(confirmed uint64) error {
 
 func (a *Account) FromMessage(msg types.IMessage, height uint64) error {
 	if a.Nonce+1 != msg.Nonce() {
-		return fmt.Errorf("wrong nonce value")
+		return wrongNonce(msg.Nonce(), a.Nonce)
 	}
 
 	switch MessageType(msg.Type()) {
@@ -108,6 +117,8 @@ func (a *Account) FromMessage(msg types.IMessage, height uint64) error {
 		return a.addTokenV2(msg, height)
 	case Redemption:
 		return a.addRedemption(msg, height)
+	case Batch:
+		return a.changeBatch(msg, height)
 	default:
 		body := msg.MsgBody()
 		tokenAddr := body.MsgToken()
@@ -124,17 +135,19 @@ func (a *Account) addToken(msg types.IMessage, height uint64) error {
 	fees := msg.Fee()
 	mainAccount, ok := a.Tokens.Get(config.Param.MainToken.String())
 	if !ok {
-		return errors.New("account is not exist")
+		return insufficientBalance(config.Param.MainToken.String(), 0, fees, "account is not exist")
 	}
 	if mainAccount.Balance < fees {
-		return fmt.Errorf("balance %d is not enough to pay the fee %d", mainAccount.Balance, fees)
+		return insufficientBalance(config.Param.MainToken.String(), mainAccount.Balance, fees,
+			"balance %d is not enough to pay the fee %d", mainAccount.Balance, fees)
 	}
 	mainAccount.Balance -= fees
 	mainAccount.LockedOut += fees
 
 	consume := config.Param.Consume
 	if mainAccount.Balance < consume {
-		return fmt.Errorf("insufficient balance, %d %s is required to publish tokens", consume, config.Param.MainToken.String())
+		return insufficientBalance(config.Param.MainToken.String(), mainAccount.Balance, consume,
+			"insufficient balance, %d %s is required to publish tokens", consume, config.Param.MainToken.String())
 	}
 	mainAccount.Balance -= consume
 	mainAccount.LockedOut += consume
@@ -150,10 +163,11 @@ func (a *Account) addTokenV2(msg types.IMessage, height uint64) error {
 	fees := msg.Fee()
 	mainAccount, ok := a.Tokens.Get(config.Param.MainToken.String())
 	if !ok {
-		return errors.New("account is not exist")
+		return insufficientBalance(config.Param.MainToken.String(), 0, fees, "account is not exist")
 	}
 	if mainAccount.Balance < fees {
-		return fmt.Errorf("balance %d is not enough to pay the fee %d", mainAccount.Balance, fees)
+		return insufficientBalance(config.Param.MainToken.String(), mainAccount.Balance, fees,
+			"balance %d is not enough to pay the fee %d", mainAccount.Balance, fees)
 	}
 	mainAccount.Balance -= fees
 	mainAccount.LockedOut += fees
@@ -161,7 +175,8 @@ func (a *Account) addTokenV2(msg types.IMessage, height uint64) error {
 	body, _ := msg.MsgBody().(*TokenV2Body)
 	pledgeAmount := body.PledgeAmount()
 	if mainAccount.Balance < pledgeAmount {
-		return fmt.Errorf("insufficient balance,a pledge of %d%s is required to generate the token", pledgeAmount, config.Param.MainToken.String())
+		return insufficientBalance(config.Param.MainToken.String(), mainAccount.Balance, pledgeAmount,
+			"insufficient balance,a pledge of %d%s is required to generate the token", pledgeAmount, config.Param.MainToken.String())
 	}
 	mainAccount.Balance -= pledgeAmount
 	mainAccount.LockedOut += pledgeAmount
@@ -179,27 +194,28 @@ func (a *Account) addRedemption(msg types.IMessage, height uint64) error {
 	body, _ := msg.MsgBody().(*RedemptionBody)
 	mainAccount, ok := a.Tokens.Get(config.Param.MainToken.String())
 	if !ok {
-		return errors.New("account is not exist")
+		return insufficientBalance(config.Param.MainToken.String(), 0, fees, "account is not exist")
 	}
 	if mainAccount.Balance < fees {
-		return fmt.Errorf("balance %d is not enough to pay the fee %d", mainAccount.Balance, fees)
+		return insufficientBalance(config.Param.MainToken.String(), mainAccount.Balance, fees,
+			"balance %d is not enough to pay the fee %d", mainAccount.Balance, fees)
 	}
 	mainAccount.Balance -= fees
 	mainAccount.LockedOut += fees
 
 	token, ok := a.Tokens.Get(body.TokenAddress.String())
 	if !ok {
-		return fmt.Errorf("token %s is not exist", body.TokenAddress.String())
+		return insufficientBalance(body.TokenAddress.String(), 0, body.MsgAmount(), "token %s is not exist", body.TokenAddress.String())
 	}
 	if token.Balance < body.MsgAmount() {
-		return fmt.Errorf("insufficient redemption amount")
+		return insufficientBalance(body.TokenAddress.String(), token.Balance, body.MsgAmount(), "insufficient redemption amount")
 	}
 	token.Balance -= body.MsgAmount()
 	token.LockedOut += body.MsgAmount()
 
 	reAmount := body.RedemptionAmount()
 	if mainAccount.Pledge < reAmount {
-		return fmt.Errorf("the redeemable amount is insufficient")
+		return insufficientBalance(config.Param.MainToken.String(), mainAccount.Pledge, reAmount, "the redeemable amount is insufficient")
 	}
 	mainAccount.Pledge -= reAmount
 
@@ -222,10 +238,10 @@ func (a *Account) changeMain(msg types.IMessage, height uint64) error {
 	}
 
 	if mainAccount.Balance < amount {
-		return fmt.Errorf("insufficient balance")
+		return insufficientBalance(mainAccount.Address, mainAccount.Balance, amount, "insufficient balance")
 	}
 	if a.Nonce+1 != msg.Nonce() {
-		return fmt.Errorf("wrong nonce value")
+		return wrongNonce(msg.Nonce(), a.Nonce)
 	}
 
 	mainAccount.Balance -= amount
@@ -236,6 +252,44 @@ func (a *Account) changeMain(msg types.IMessage, height uint64) error {
 	return nil
 }
 
+// Change the account status of all operations of the batch. The fee and
+// the amount of each token are locked together under one journal.
+func (a *Account) changeBatch(msg types.IMessage, height uint64) error {
+	body, ok := msg.MsgBody().(*BatchBody)
+	if !ok {
+		return NewMsgError(ErrBadMessage, nil, "incorrect message type and message body")
+	}
+	if !a.Exist() {
+		a.Address = msg.From()
+	}
+	main := config.Param.MainToken.String()
+	amounts := body.Amounts()
+	if err := a.checkAmounts(amounts, msg.Fee()); err != nil {
+		return err
+	}
+
+	mainAccount, _ := a.Tokens.Get(main)
+	mainAccount.Address = main
+	mainAccount.Balance -= amounts[main] + msg.Fee()
+	mainAccount.LockedOut += amounts[main] + msg.Fee()
+	a.Tokens.Set(mainAccount)
+
+	others := make([]*tokenAmount, 0)
+	for _, token := range sortedTokens(amounts) {
+		if token == main {
+			continue
+		}
+		tokenAccount, _ := a.Tokens.Get(token)
+		tokenAccount.Balance -= amounts[token]
+		tokenAccount.LockedOut += amounts[token]
+		a.Tokens.Set(tokenAccount)
+		others = append(others, &tokenAmount{TokenAddress: token, Amount: amounts[token]})
+	}
+	a.Nonce = msg.Nonce()
+	a.JournalOut.Add(config.Param.MainToken, amounts[main], msg.Fee(), msg.Nonce(), msg.Time(), height, others...)
+	return nil
+}
+
 // Change the status of the secondary account of the transaction transfer party.
 // The transaction of the secondary account needs to consume the fee of the
 // primary account.
@@ -246,18 +300,18 @@ func (a *Account) changeToken(msg types.IMessage, height uint64) error {
 	amount := msgBody.MsgAmount()
 	mainAccount, ok := a.Tokens.Get(config.Param.MainToken.String())
 	if !ok {
-		return errors.New("account is not exist")
+		return insufficientBalance(config.Param.MainToken.String(), 0, fees, "account is not exist")
 	}
 	if mainAccount.Balance < fees {
-		return fmt.Errorf("insufficient balance")
+		return insufficientBalance(config.Param.MainToken.String(), mainAccount.Balance, fees, "insufficient balance")
 	}
 	tokenAddr := msgBody.MsgToken()
 	coinAccount, ok := a.Tokens.Get(tokenAddr.String())
 	if !ok {
-		return errors.New("account is not exist")
+		return insufficientBalance(tokenAddr.String(), 0, amount, "account is not exist")
 	}
 	if coinAccount.Balance < amount {
-		return fmt.Errorf("insufficient balance")
+		return insufficientBalance(tokenAddr.String(), coinAccount.Balance, amount, "insufficient balance")
 	}
 
 	mainAccount.Balance -= fees
@@ -349,18 +403,21 @@ func (a *Account) Check(msg types.IMessage, strict bool) error {
 		a.Address = msg.From()
 	}
 
+	nonceDetails := ErrDetails{"nonce": msg.Nonce(), "account_nonce": a.Nonce}
 	if strict {
-		if msg.Nonce() != a.Nonce+1 {
-			return fmt.Errorf("nonce value must be %d", a.Nonce+1)
+		if msg.Nonce() <= a.Nonce {
+			return NewMsgError(ErrNonceTooLow, nonceDetails, "nonce value must be %d", a.Nonce+1)
+		} else if msg.Nonce() != a.Nonce+1 {
+			return NewMsgError(ErrNonceTooHigh, nonceDetails, "nonce value must be %d", a.Nonce+1)
 		}
 	} else if msg.Nonce() <= a.Nonce {
-		return fmt.Errorf("the nonce value of the message must be greater than %d", a.Nonce)
+		return NewMsgError(ErrNonceTooLow, nonceDetails, "the nonce value of the message must be greater than %d", a.Nonce)
 	}
 
 	// The nonce value cannot be greater than the
 	// maximum number of address transactions
 	if msg.Nonce() > a.Nonce+config.Param.MaxAddressMsg {
-		return fmt.Errorf("the nonce value of the message cannot be greater "+
+		return NewMsgError(ErrNonceTooHigh, nonceDetails, "the nonce value of the message cannot be greater "+
 			"than the nonce value of the account %d", config.Param.MaxAddressMsg)
 	}
 
@@ -369,7 +426,7 @@ func (a *Account) Check(msg types.IMessage, strict bool) error {
 	case Transaction:
 		body, ok := msg.MsgBody().(*TransactionBody)
 		if !ok {
-			return errors.New("incorrect message type and message body")
+			return NewMsgError(ErrBadMessage, nil, "incorrect message type and message body")
 		}
 		if body.TokenAddress.IsEqual(config.Param.MainToken) {
 			return a.checkMainBalance(msg)
@@ -382,9 +439,15 @@ func (a *Account) Check(msg types.IMessage, strict bool) error {
 		return a.checkPledge(msg)
 	case Redemption:
 		return a.checkRedemption(msg)
+	case Batch:
+		body, ok := msg.MsgBody().(*BatchBody)
+		if !ok {
+			return NewMsgError(ErrBadMessage, nil, "incorrect message type and message body")
+		}
+		return a.checkAmounts(body.Amounts(), msg.Fee())
 	default:
 		if msg.MsgBody().MsgAmount() != 0 {
-			return errors.New("wrong amount")
+			return NewMsgError(ErrBadAmount, ErrDetails{"amount": msg.MsgBody().MsgAmount()}, "wrong amount")
 		}
 		return a.checkFees(msg)
 	}
@@ -393,11 +456,9 @@ func (a *Account) Check(msg types.IMessage, strict bool) error {
 func (a *Account) checkConsume(msg types.IMessage) error {
 	main := config.Param.MainToken.String()
 	consume := msg.Fee() + config.Param.Consume
-	token, ok := a.Tokens.Get(main)
-	if !ok {
-		return fmt.Errorf("insufficient balance, %d %s is required to publish tokens", consume, main)
-	} else if token.Balance < consume {
-		return fmt.Errorf("insufficient balance, %d %s is required to publish tokens", consume, main)
+	token, _ := a.Tokens.Get(main)
+	if token.Balance < consume {
+		return insufficientBalance(main, token.Balance, consume, "insufficient balance, %d %s is required to publish tokens", consume, main)
 	}
 	return nil
 }
@@ -407,27 +468,23 @@ func (a *Account) checkPledge(msg types.IMessage) error {
 	body, _ := msg.MsgBody().(*TokenV2Body)
 	amount := body.PledgeAmount()
 	consume := msg.Fee() + amount
-	token, ok := a.Tokens.Get(main)
-	if !ok {
-		return fmt.Errorf("insufficient balance, need to pledge %s %d to generate tokens", main, amount)
-	} else if token.Balance < consume {
-		return fmt.Errorf("insufficient balance, need to pledge %s %d to generate tokens", main, amount)
+	token, _ := a.Tokens.Get(main)
+	if token.Balance < consume {
+		return insufficientBalance(main, token.Balance, consume, "insufficient balance, need to pledge %s %d to generate tokens", main, amount)
 	}
 	return nil
 }
 
 func (a *Account) checkRedemption(msg types.IMessage) error {
 	body, _ := msg.MsgBody().(*RedemptionBody)
-	token, ok := a.Tokens.Get(body.TokenAddress.String())
-	if !ok {
-		return fmt.Errorf("insufficient balance, it's not enough to redeem %d", body.MsgAmount())
-	} else if token.Balance < body.MsgAmount() {
-		return fmt.Errorf("insufficient balance, it's not enough to redeem %d", body.MsgAmount())
+	token, _ := a.Tokens.Get(body.TokenAddress.String())
+	if token.Balance < body.MsgAmount() {
+		return insufficientBalance(body.TokenAddress.String(), token.Balance, body.MsgAmount(), "insufficient balance, it's not enough to redeem %d", body.MsgAmount())
 	}
 	main, _ := a.Tokens.Get(config.Param.MainToken.String())
 	reAmount := body.RedemptionAmount()
 	if reAmount > main.Pledge {
-		return fmt.Errorf("the redeemable amount is insufficient")
+		return NewMsgError(ErrInsufficientBalance, ErrDetails{"token": main.Address, "pledge": main.Pledge, "required": reAmount}, "the redeemable amount is insufficient")
 	}
 	return nil
 }
@@ -436,43 +493,80 @@ func (a *Account) checkRedemption(msg types.IMessage) error {
 // value and transaction fee cannot be greater than the balance.
 func (a *Account) checkMainBalance(msg types.IMessage) error {
 	main := config.Param.MainToken.String()
-	token, ok := a.Tokens.Get(main)
-	if !ok {
-		return fmt.Errorf("%s does not have enough balance", main)
-	} else if token.Balance < msg.Fee()+msg.MsgBody().MsgAmount() {
-		return fmt.Errorf("%s does not have enough balance", main)
+	token, _ := a.Tokens.Get(main)
+	if required := msg.Fee() + msg.MsgBody().MsgAmount(); token.Balance < required {
+		return insufficientBalance(main, token.Balance, required, "%s does not have enough balance", main)
 	}
 	return nil
 }
 
 // Verify the account balance of the secondary transaction, the transaction
-// value cannot be greater than the balance.
+// value cannot be greater than the balance. The transfer policy of the token
+// is verified by the token status, which holds the policy lists.
 func (a *Account) checkTokenBalance(msg types.IMessage, body *TransactionBody) error {
 	if err := a.checkFees(msg); err != nil {
 		return err
 	}
 
-	coinAccount, ok := a.Tokens.Get(body.TokenAddress.String())
-	if !ok {
-		return fmt.Errorf("%s does not have enough balance", body.TokenAddress.String())
-	} else if coinAccount.Balance < body.MsgAmount() {
-		return fmt.Errorf("%s does not have enough balance", body.TokenAddress.String())
+	tokenAddr := body.TokenAddress.String()
+	coinAccount, _ := a.Tokens.Get(tokenAddr)
+	if coinAccount.Balance < body.MsgAmount() {
+		return insufficientBalance(tokenAddr, coinAccount.Balance, body.MsgAmount(), "%s does not have enough balance", tokenAddr)
 	}
 	return nil
 }
 
+// Verify that the balance of each token covers the amount, the fee is
+// paid by the main token.
+func (a *Account) checkAmounts(amounts map[string]uint64, fees uint64) error {
+	main := config.Param.MainToken.String()
+	mainAccount, _ := a.Tokens.Get(main)
+	if mainAccount.Balance < fees || mainAccount.Balance-fees < amounts[main] {
+		return insufficientBalance(main, mainAccount.Balance, fees+amounts[main], "%s does not have enough balance", main)
+	}
+	for _, token := range sortedTokens(amounts) {
+		if token == main {
+			continue
+		}
+		tokenAccount, _ := a.Tokens.Get(token)
+		if tokenAccount.Balance < amounts[token] {
+			return insufficientBalance(token, tokenAccount.Balance, amounts[token], "%s does not have enough balance", token)
+		}
+	}
+	return nil
+}
+
+func sortedTokens(amounts map[string]uint64) []string {
+	tokens := make([]string, 0, len(amounts))
+	for token := range amounts {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	return tokens
+}
+
 // Verification fee
 func (a *Account) checkFees(msg types.IMessage) error {
 	main := config.Param.MainToken.String()
-	token, ok := a.Tokens.Get(main)
-	if !ok && msg.Fee() != 0 {
-		return fmt.Errorf("%s does not have enough balance to pay the handling fee", main)
-	} else if token.Balance < msg.Fee() {
-		return fmt.Errorf("%s does not have enough balance to pay the handling fee", main)
+	token, _ := a.Tokens.Get(main)
+	if token.Balance < msg.Fee() {
+		return insufficientBalance(main, token.Balance, msg.Fee(), "%s does not have enough balance to pay the handling fee", main)
 	}
 	return nil
 }
 
+func wrongNonce(nonce, accountNonce uint64) error {
+	details := ErrDetails{"nonce": nonce, "account_nonce": accountNonce}
+	if nonce <= accountNonce {
+		return NewMsgError(ErrNonceTooLow, details, "wrong nonce value")
+	}
+	return NewMsgError(ErrNonceTooHigh, details, "wrong nonce value")
+}
+
+func insufficientBalance(token string, balance, required uint64, format string, a ...interface{}) error {
+	return NewMsgError(ErrInsufficientBalance, ErrDetails{"token": token, "balance": balance, "required": required}, format, a...)
+}
+
 func (a *Account) Exist() bool {
 	return !arry.EmptyAddress(a.Address)
 }
@@ -525,7 +619,19 @@ func newJournalOut() *journalOut {
 	return &journalOut{Outs: &TxOutList{}}
 }
 
-func (j *journalOut) Add(token arry.Address, amount, fees, nonce, time, height uint64) {
+func (j *journalOut) Add(token arry.Address, amount, fees, nonce, time, height uint64, others ...*tokenAmount) {
+	// Several messages of one address can be packed into the same block,
+	// their locked amounts are merged into the journal of that height
+	if out, ok := j.Outs.Get(height); ok {
+		out.Fees += fees
+		out.Nonce = nonce
+		out.Time = time
+		out.add(token.String(), amount)
+		for _, other := range others {
+			out.add(other.TokenAddress, other.Amount)
+		}
+		return
+	}
 	j.Outs.Set(&txOut{
 		TokenAddress: token.String(),
 		Amount:       amount,
@@ -533,6 +639,7 @@ func (j *journalOut) Add(token arry.Address, amount, fees, nonce, time, height u
 		Nonce:        nonce,
 		Time:         time,
 		Height:       height,
+		Others:       others,
 	})
 }
 
@@ -578,6 +685,9 @@ func (j *journalOut) Amount() map[string]uint64 {
 		} else {
 			amounts[txIn.TokenAddress] = txIn.Amount
 		}
+		for _, other := range txIn.Others {
+			amounts[other.TokenAddress] += other.Amount
+		}
 	}
 	return amounts
 }
@@ -596,6 +706,30 @@ type txOut struct {
 	Nonce        uint64
 	Time         uint64
 	Height       uint64
+	// Amounts of other tokens locked by a batch
+	Others []*tokenAmount `rlp:"tail"`
+}
+
+func (t *txOut) add(token string, amount uint64) {
+	if amount == 0 {
+		return
+	}
+	if t.TokenAddress == token {
+		t.Amount += amount
+		return
+	}
+	for _, other := range t.Others {
+		if other.TokenAddress == token {
+			other.Amount += amount
+			return
+		}
+	}
+	t.Others = append(t.Others, &tokenAmount{token, amount})
+}
+
+type tokenAmount struct {
+	TokenAddress string
+	Amount       uint64
 }
 
 type TxOutList []*txOut
@@ -0,0 +1,112 @@
+package types
+
+import (
+	"fmt"
+	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/tools/rlp"
+	"github.com/aiot-network/aiotchain/types"
+)
+
+// Length of the short message ids of compact blocks
+const ShortIdLength = 8
+
+type ShortId [ShortIdLength]byte
+
+func NewShortId(hash arry.Hash) ShortId {
+	var id ShortId
+	copy(id[:], hash[:ShortIdLength])
+	return id
+}
+
+// A block relayed with the short ids of its messages, the receiver rebuilds
+// it from the messages of its pool. Coinbase messages are never in the pool
+// and are prefilled in full.
+type CompactBlock struct {
+	Header *Header
+	// Short ids of the messages that are not prefilled, in the block order
+	ShortIds  []ShortId
+	Prefilled []*PrefilledMsg
+}
+
+type PrefilledMsg struct {
+	// Index of the message in the block
+	Index uint32
+	Msg   *RlpMessage
+}
+
+func NewCompactBlock(block types.IBlock) *CompactBlock {
+	compact := &CompactBlock{Header: block.BlockHeader().(*Header)}
+	for index, msg := range block.BlockBody().MsgList() {
+		if msg.IsCoinBase() {
+			compact.Prefilled = append(compact.Prefilled, &PrefilledMsg{
+				Index: uint32(index),
+				Msg:   msg.ToRlp().(*RlpMessage),
+			})
+		} else {
+			compact.ShortIds = append(compact.ShortIds, NewShortId(msg.Hash()))
+		}
+	}
+	return compact
+}
+
+func DecodeCompactBlock(bytes []byte) (*CompactBlock, error) {
+	var compact *CompactBlock
+	if err := rlp.DecodeBytes(bytes, &compact); err != nil {
+		return nil, err
+	}
+	if compact.Header == nil {
+		return nil, fmt.Errorf("compact block without header")
+	}
+	return compact, nil
+}
+
+func (c *CompactBlock) Bytes() []byte {
+	bytes, _ := rlp.EncodeToBytes(c)
+	return bytes
+}
+
+func (c *CompactBlock) BlockHeader() types.IHeader {
+	return c.Header
+}
+
+// Number of the messages of the block
+func (c *CompactBlock) Count() int {
+	return len(c.ShortIds) + len(c.Prefilled)
+}
+
+// Fill the messages of the block with the prefilled messages and the messages
+// of the pool, returns the indexes of the messages that are not found
+func (c *CompactBlock) Fill(pool []types.IMessage) ([]types.IMessage, []uint32, error) {
+	ids := make(map[ShortId]types.IMessage, len(pool))
+	for _, msg := range pool {
+		ids[NewShortId(msg.Hash())] = msg
+	}
+	msgs := make([]types.IMessage, c.Count())
+	for _, prefilled := range c.Prefilled {
+		if prefilled == nil || prefilled.Msg == nil || int(prefilled.Index) >= len(msgs) || msgs[prefilled.Index] != nil {
+			return nil, nil, fmt.Errorf("invalid prefilled message")
+		}
+		msgs[prefilled.Index] = prefilled.Msg.ToMessage()
+	}
+	missing := make([]uint32, 0)
+	next := 0
+	for index := range msgs {
+		if msgs[index] != nil {
+			continue
+		}
+		if msg, ok := ids[c.ShortIds[next]]; ok {
+			msgs[index] = msg
+		} else {
+			missing = append(missing, uint32(index))
+		}
+		next++
+	}
+	return msgs, missing, nil
+}
+
+func (c *CompactBlock) ToBlock(msgs []types.IMessage) types.IBlock {
+	return &Block{
+		Header: c.Header,
+		Body:   &Body{msgs},
+	}
+}
@@ -2,8 +2,8 @@ package types
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
+	"github.com/aiot-network/aiotchain/common/config"
 	"github.com/aiot-network/aiotchain/tools/amount"
 	"github.com/aiot-network/aiotchain/tools/arry"
 	"github.com/aiot-network/aiotchain/tools/crypto/ecc/secp256k1"
@@ -43,6 +43,10 @@ func (m *Message) Time() uint64 {
 	return m.Header.Time
 }
 
+func (m *Message) ValidUntil() uint64 {
+	return m.Header.ValidUntil
+}
+
 func (m *Message) IsCoinBase() bool {
 	return m.Header.From.IsEqual(CoinBase)
 }
@@ -76,7 +80,7 @@ func (m *Message) ToRlp() types.IRlpMessage {
 
 func (m *Message) Check() error {
 	if m.Header == nil || m.Body == nil {
-		return errors.New("incomplete message")
+		return NewMsgError(ErrBadMessage, nil, "incomplete message")
 	}
 
 	if err := m.checkHash(); err != nil {
@@ -108,12 +112,10 @@ func (m *Message) CheckCoinBase(fee uint64, coinbase uint64) error {
 }
 
 func (m *Message) checkFees() error {
-	if m.Header.Type == Work {
-		return nil
-	}
-	fees := uint64(minFees * len(m.MsgTo().ReceiverList()))
+	fees := MinFee(m.Header.Type, FeeUnits(m.Body))
 	if m.Header.Fee < fees {
-		return fmt.Errorf("fees %.8f is less than the minimum poundage allowed %.8f", amount.Amount(m.Header.Fee).ToCoin(), amount.Amount(fees).ToCoin())
+		return NewMsgError(ErrFeeTooLow, ErrDetails{"fee": m.Header.Fee, "minfee": fees},
+			"fees %.8f is less than the minimum poundage allowed %.8f", amount.Amount(m.Header.Fee).ToCoin(), amount.Amount(fees).ToCoin())
 	}
 	return nil
 }
@@ -127,7 +129,7 @@ func (m *Message) checkHash() error {
 	if newMsg.Hash().IsEqual(m.Hash()) {
 		return nil
 	}
-	return errors.New("error messages hash")
+	return NewMsgError(ErrBadMessage, nil, "error messages hash")
 }
 
 func (m *Message) Bytes() []byte {
@@ -169,14 +171,47 @@ func (m *Message) SignMessage(key *secp256k1.PrivateKey) error {
 func (m *Message) copy() *Message {
 	return &Message{
 		Header: &MsgHeader{
-			Hash:      m.Header.Hash,
-			Type:      m.Header.Type,
-			From:      m.Header.From,
-			Nonce:     m.Header.Nonce,
-			Fee:       m.Header.Fee,
-			Time:      m.Header.Time,
-			Signature: m.Header.Signature,
+			Hash:       m.Header.Hash,
+			Type:       m.Header.Type,
+			From:       m.Header.From,
+			Nonce:      m.Header.Nonce,
+			Fee:        m.Header.Fee,
+			Time:       m.Header.Time,
+			Signature:  m.Header.Signature,
+			ValidUntil: m.Header.ValidUntil,
 		},
 		Body: m.Body,
 	}
 }
+
+// Bodies that are charged by units other than their receivers
+type feeUnitBody interface {
+	FeeUnits() int
+}
+
+// The number of fee units of the message body, each unit costs the
+// minimum fee. A body is charged by its receivers by default.
+func FeeUnits(body types.IMessageBody) int {
+	if b, ok := body.(feeUnitBody); ok {
+		return b.FeeUnits()
+	}
+	return len(body.MsgTo().ReceiverList())
+}
+
+// The minimum fee allowed for a message with the number of fee units
+func MinFee(msgType MessageType, receivers int) uint64 {
+	if msgType == Work {
+		return 0
+	}
+	return uint64(minFees * receivers)
+}
+
+// The minimum fee for a message to replace a pending message
+// of the same nonce, the fee must be raised by ReplaceFeeBump percent
+func ReplaceFee(oldFee uint64) uint64 {
+	bump := oldFee * uint64(config.Param.ReplaceFeeBump) / 100
+	if bump == 0 {
+		bump = 1
+	}
+	return oldFee + bump
+}
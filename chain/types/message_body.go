@@ -1,20 +1,28 @@
 package types
 
 import (
-	"errors"
 	"fmt"
 	"github.com/aiot-network/aiotchain/chain/common/kit"
 	"github.com/aiot-network/aiotchain/common/config"
 	"github.com/aiot-network/aiotchain/tools/amount"
 	"github.com/aiot-network/aiotchain/tools/arry"
 	"github.com/aiot-network/aiotchain/tools/math"
+	"github.com/aiot-network/aiotchain/tools/rlp"
 	"github.com/aiot-network/aiotchain/types"
+	"io"
 	"time"
 )
 
 const (
-	PeerLength = 53
-	MaxName    = 50
+	PeerLength       = 53
+	MaxName          = 50
+	MaxPolicyAddress = 100
+	MaxPolicyList    = 1000
+	MaxDecimals      = 8
+	DefaultDecimals  = MaxDecimals
+	MaxURI           = 256
+	MaxDescription   = 256
+	MaxBatchItems    = 20
 )
 
 type Peer [PeerLength]byte
@@ -52,7 +60,7 @@ func (r *Receivers) Add(address arry.Address, amount uint64) {
 func (r *Receivers) CheckAddress() error {
 	for _, re := range r.List {
 		if !kit.CheckAddress(config.Param.Name, re.Address.String()) {
-			return fmt.Errorf("receive address %s verification failed", re.Address.String())
+			return NewMsgError(ErrBadAddress, ErrDetails{"address": re.Address.String()}, "receive address %s verification failed", re.Address.String())
 		}
 	}
 	return nil
@@ -62,14 +70,17 @@ func (r *Receivers) CheckAmount() error {
 	var sum uint64
 	for _, re := range r.List {
 		if re.Amount < config.Param.MinimumTransfer {
-			return fmt.Errorf("the minimum allowed transfer is %d", config.Param.MinimumTransfer)
+			return NewMsgError(ErrBadAmount, ErrDetails{"amount": re.Amount, "minimum": config.Param.MinimumTransfer},
+				"the minimum allowed transfer is %d", config.Param.MinimumTransfer)
 		}
 		if re.Amount > config.Param.MaximumTransfer {
-			return fmt.Errorf("the maximum allowed transfer is %d", config.Param.MaximumTransfer)
+			return NewMsgError(ErrBadAmount, ErrDetails{"amount": re.Amount, "maximum": config.Param.MaximumTransfer},
+				"the maximum allowed transfer is %d", config.Param.MaximumTransfer)
 		}
 		sum += re.Amount
 		if sum > config.Param.MaximumTransfer {
-			return fmt.Errorf("the maximum allowed transfer is %d", config.Param.MaximumTransfer)
+			return NewMsgError(ErrBadAmount, ErrDetails{"amount": sum, "maximum": config.Param.MaximumTransfer},
+				"the maximum allowed transfer is %d", config.Param.MaximumTransfer)
 		}
 	}
 	return nil
@@ -85,25 +96,39 @@ func (t *TransactionBody) MsgTo() types.IReceiver {
 
 func (t *TransactionBody) CheckBody(from arry.Address) error {
 	if len(t.Receivers.List) > config.Param.MaximumReceiver {
-		return fmt.Errorf("the maximum number of receive addresses is %d", config.Param.MaximumReceiver)
+		return NewMsgError(ErrBadMessage, ErrDetails{"count": len(t.Receivers.List), "maximum": config.Param.MaximumReceiver},
+			"the maximum number of receive addresses is %d", config.Param.MaximumReceiver)
 	}
 	if len(t.Receivers.List) == 0 {
-		return fmt.Errorf("no receivers")
+		return NewMsgError(ErrBadMessage, nil, "no receivers")
 	}
 	if err := t.Receivers.CheckAddress(); err != nil {
 		return err
 	}
 	if !t.TokenAddress.IsEqual(config.Param.MainToken) {
 		if !kit.CheckTokenAddress(config.Param.Name, t.TokenAddress.String()) {
-			return errors.New("token address verification failed")
+			return NewMsgError(ErrBadAddress, nil, "token address verification failed")
 		}
 	}
+	if t.IsCancel(from) {
+		return nil
+	}
 	if err := t.Receivers.CheckAmount(); err != nil {
 		return err
 	}
 	return nil
 }
 
+// A zero-value transfer of the main token to the sender itself,
+// used to replace and cancel a pending message of the same nonce
+func (t *TransactionBody) IsCancel(from arry.Address) bool {
+	if len(t.Receivers.List) != 1 || !t.TokenAddress.IsEqual(config.Param.MainToken) {
+		return false
+	}
+	re := t.Receivers.List[0]
+	return re.Amount == 0 && re.Address.IsEqual(from)
+}
+
 func (t *TransactionBody) MsgAmount() uint64 {
 	var sum uint64
 	for _, re := range t.Receivers.List {
@@ -123,6 +148,8 @@ type TokenBody struct {
 	Shorthand      string
 	IncreaseIssues bool
 	Amount         uint64
+	// Tokens issued before the metadata was introduced have none
+	Meta *TokenMeta `rlp:"nil,optional"`
 }
 
 func (t *TokenBody) MsgTo() types.IReceiver {
@@ -133,30 +160,33 @@ func (t *TokenBody) MsgTo() types.IReceiver {
 
 func (t *TokenBody) CheckBody(from arry.Address) error {
 	if !kit.CheckAddress(config.Param.Name, t.Receiver.String()) {
-		return errors.New("receive address verification failed")
+		return NewMsgError(ErrBadAddress, nil, "receive address verification failed")
 	}
 	if !kit.CheckTokenAddress(config.Param.Name, t.TokenAddress.String()) {
-		return errors.New("token address verification failed")
+		return NewMsgError(ErrBadAddress, nil, "token address verification failed")
 	}
 	toKenAddr, err := kit.GenerateTokenAddress(config.Param.Name, t.Shorthand)
 	if err != nil {
-		return errors.New("token address verification failed")
+		return NewMsgError(ErrBadAddress, nil, "token address verification failed")
 	}
 	if toKenAddr != t.TokenAddress.String() {
-		return errors.New("token address verification failed")
+		return NewMsgError(ErrBadAddress, nil, "token address verification failed")
 	}
 	if err := kit.CheckShorthand(t.Shorthand); err != nil {
-		return fmt.Errorf("shorthand verification failed, %s", err.Error())
+		return NewMsgError(ErrBadMessage, nil, "shorthand verification failed, %s", err.Error())
 	}
 	if len(t.Name) > MaxName {
-		return fmt.Errorf("the maximum length of the token name is %d", MaxName)
+		return NewMsgError(ErrBadMessage, nil, "the maximum length of the token name is %d", MaxName)
+	}
+	if err := checkTokenMeta(t.Meta); err != nil {
+		return err
 	}
 	if t.Amount > math.MaxInt64 {
-		return fmt.Errorf("amount cannot be greater than %.8f", amount.Amount(math.MaxInt64).ToCoin())
+		return NewMsgError(ErrBadAmount, nil, "amount cannot be greater than %.8f", amount.Amount(math.MaxInt64).ToCoin())
 	}
 	fAmount := amount.Amount(t.Amount).ToCoin()
 	if fAmount < config.Param.MinCoinCount || fAmount > config.Param.MaxCoinCount {
-		return fmt.Errorf("the quantity of coins must be between %.8f and %.8f", config.Param.MinCoinCount, config.Param.MaxCoinCount)
+		return NewMsgError(ErrBadAmount, nil, "the quantity of coins must be between %.8f and %.8f", config.Param.MinCoinCount, config.Param.MaxCoinCount)
 	}
 	return nil
 }
@@ -169,6 +199,26 @@ func (t *TokenBody) MsgToken() arry.Address {
 	return t.TokenAddress
 }
 
+func checkTokenMeta(meta *TokenMeta) error {
+	if meta == nil {
+		return nil
+	}
+	if meta.Decimals > MaxDecimals {
+		return NewMsgError(ErrBadMessage, nil, "the maximum decimals of the token is %d", MaxDecimals)
+	}
+	return checkTokenURI(meta.URI, meta.Description)
+}
+
+func checkTokenURI(uri, description string) error {
+	if len(uri) > MaxURI {
+		return NewMsgError(ErrBadMessage, nil, "the maximum length of the token uri is %d", MaxURI)
+	}
+	if len(description) > MaxDescription {
+		return NewMsgError(ErrBadMessage, nil, "the maximum length of the token description is %d", MaxDescription)
+	}
+	return nil
+}
+
 type CandidateBody struct {
 	Peer Peer
 }
@@ -220,7 +270,7 @@ func (v *VoteBody) MsgTo() types.IReceiver {
 
 func (v *VoteBody) CheckBody(from arry.Address) error {
 	if !kit.CheckAddress(config.Param.Name, v.To.String()) {
-		return errors.New("wrong to address")
+		return NewMsgError(ErrBadAddress, nil, "wrong to address")
 	}
 	return nil
 }
@@ -252,21 +302,21 @@ func (w *WorkBody) MsgTo() types.IReceiver {
 
 func (w *WorkBody) CheckBody(from arry.Address) error {
 	if len(w.List) > config.Param.SuperSize {
-		return fmt.Errorf("it cannot exceed the maximum number of supernodes %d", config.Param.SuperSize)
+		return NewMsgError(ErrBadMessage, nil, "it cannot exceed the maximum number of supernodes %d", config.Param.SuperSize)
 	}
 	if len(w.List) == 0 {
-		return fmt.Errorf("no wokrs")
+		return NewMsgError(ErrBadMessage, nil, "no wokrs")
 	}
 	for _, work := range w.List {
 		if !kit.CheckAddress(config.Param.Name, work.Address.String()) {
-			return errors.New("wrong to address")
+			return NewMsgError(ErrBadAddress, nil, "wrong to address")
 		}
 	}
 	if w.EndTime > uint64(time.Now().Unix()) {
-		return errors.New("end time error")
+		return NewMsgError(ErrBadTime, nil, "end time error")
 	}
 	if w.StartTime >= w.EndTime {
-		return errors.New("start time error")
+		return NewMsgError(ErrBadTime, nil, "start time error")
 	}
 	return nil
 }
@@ -294,6 +344,7 @@ type TokenV2Body struct {
 	Shorthand    string
 	Amount       uint64
 	PledgeRate   PledgeRate
+	Meta         *TokenMeta `rlp:"nil,optional"`
 }
 
 func (t *TokenV2Body) MsgTo() types.IReceiver {
@@ -304,30 +355,33 @@ func (t *TokenV2Body) MsgTo() types.IReceiver {
 
 func (t *TokenV2Body) CheckBody(from arry.Address) error {
 	if !kit.CheckAddress(config.Param.Name, t.Receiver.String()) {
-		return errors.New("receive address verification failed")
+		return NewMsgError(ErrBadAddress, nil, "receive address verification failed")
 	}
 	if !kit.CheckTokenAddress(config.Param.Name, t.TokenAddress.String()) {
-		return errors.New("token address verification failed")
+		return NewMsgError(ErrBadAddress, nil, "token address verification failed")
 	}
 	toKenAddr, err := kit.GenerateTokenAddress(config.Param.Name, t.Shorthand)
 	if err != nil {
-		return errors.New("token address verification failed")
+		return NewMsgError(ErrBadAddress, nil, "token address verification failed")
 	}
 	if toKenAddr != t.TokenAddress.String() {
-		return errors.New("token address verification failed")
+		return NewMsgError(ErrBadAddress, nil, "token address verification failed")
 	}
 	if err := kit.CheckShorthand(t.Shorthand); err != nil {
-		return fmt.Errorf("shorthand verification failed, %s", err.Error())
+		return NewMsgError(ErrBadMessage, nil, "shorthand verification failed, %s", err.Error())
 	}
 	if len(t.Name) > MaxName {
-		return fmt.Errorf("the maximum length of the token name is %d", MaxName)
+		return NewMsgError(ErrBadMessage, nil, "the maximum length of the token name is %d", MaxName)
+	}
+	if err := checkTokenMeta(t.Meta); err != nil {
+		return err
 	}
 	if t.Amount > math.MaxInt64 {
-		return fmt.Errorf("amount cannot be greater than %.8f", amount.Amount(math.MaxInt64).ToCoin())
+		return NewMsgError(ErrBadAmount, nil, "amount cannot be greater than %.8f", amount.Amount(math.MaxInt64).ToCoin())
 	}
 	fAmount := amount.Amount(t.Amount).ToCoin()
 	if fAmount < config.Param.MinCoinCount || fAmount > config.Param.MaxCoinCount {
-		return fmt.Errorf("the quantity of coins must be between %.8f and %.8f", config.Param.MinCoinCount, config.Param.MaxCoinCount)
+		return NewMsgError(ErrBadAmount, nil, "the quantity of coins must be between %.8f and %.8f", config.Param.MinCoinCount, config.Param.MaxCoinCount)
 	}
 	switch t.PledgeRate {
 	case Hundred:
@@ -337,7 +391,7 @@ func (t *TokenV2Body) CheckBody(from arry.Address) error {
 	case TenThousand:
 		return nil
 	default:
-		return fmt.Errorf("the pledge rate must be %d, %d or %d", Hundred, Thousand, TenThousand)
+		return NewMsgError(ErrBadMessage, ErrDetails{"pledge_rate": t.PledgeRate}, "the pledge rate must be %d, %d or %d", Hundred, Thousand, TenThousand)
 	}
 	return nil
 }
@@ -366,14 +420,14 @@ func (r *RedemptionBody) MsgTo() types.IReceiver {
 
 func (r *RedemptionBody) CheckBody(from arry.Address) error {
 	if !kit.CheckTokenAddress(config.Param.Name, r.TokenAddress.String()) {
-		return errors.New("token address verification failed")
+		return NewMsgError(ErrBadAddress, nil, "token address verification failed")
 	}
 	if r.Amount > math.MaxInt64 {
-		return fmt.Errorf("amount cannot be greater than %.8f", amount.Amount(math.MaxInt64).ToCoin())
+		return NewMsgError(ErrBadAmount, nil, "amount cannot be greater than %.8f", amount.Amount(math.MaxInt64).ToCoin())
 	}
 	fAmount := amount.Amount(r.Amount).ToCoin()
 	if fAmount < config.Param.MinCoinCount || fAmount > config.Param.MaxCoinCount {
-		return fmt.Errorf("the quantity of coins must be between %.8f and %.8f", config.Param.MinCoinCount, config.Param.MaxCoinCount)
+		return NewMsgError(ErrBadAmount, nil, "the quantity of coins must be between %.8f and %.8f", config.Param.MinCoinCount, config.Param.MaxCoinCount)
 	}
 	return nil
 }
@@ -389,3 +443,339 @@ func (r *RedemptionBody) MsgToken() arry.Address {
 func (r *RedemptionBody) RedemptionAmount() uint64 {
 	return r.Amount / uint64(r.PledgeRate) * config.Param.RedemptionRate / 100
 }
+
+type TokenAdminAction uint8
+
+const (
+	// Hand the token over to a new owner
+	TransferOwner TokenAdminAction = iota
+	// Give up the right to increase issuance, it cannot be restored
+	RenounceMint
+	// Set a hard cap on the total issuance of the token
+	SetMaxSupply
+	// Update the metadata uri and description of the token
+	SetMetadata
+)
+
+type TokenAdminBody struct {
+	TokenAddress arry.Address
+	Action       TokenAdminAction
+	NewOwner     arry.Address
+	MaxSupply    uint64
+	URI          string
+	Description  string
+}
+
+func (t *TokenAdminBody) MsgTo() types.IReceiver {
+	return NewReceivers()
+}
+
+func (t *TokenAdminBody) CheckBody(from arry.Address) error {
+	if !kit.CheckTokenAddress(config.Param.Name, t.TokenAddress.String()) {
+		return NewMsgError(ErrBadAddress, nil, "token address verification failed")
+	}
+	switch t.Action {
+	case TransferOwner:
+		if !kit.CheckAddress(config.Param.Name, t.NewOwner.String()) {
+			return NewMsgError(ErrBadAddress, ErrDetails{"address": t.NewOwner.String()}, "new owner address verification failed")
+		}
+		if t.NewOwner.IsEqual(from) {
+			return NewMsgError(ErrBadMessage, nil, "the new owner is the same as the sender")
+		}
+	case RenounceMint:
+		return nil
+	case SetMaxSupply:
+		if t.MaxSupply == 0 {
+			return NewMsgError(ErrBadAmount, nil, "max supply cannot be zero")
+		}
+		if t.MaxSupply > math.MaxInt64 {
+			return NewMsgError(ErrBadAmount, nil, "max supply cannot be greater than %.8f", amount.Amount(math.MaxInt64).ToCoin())
+		}
+		if amount.Amount(t.MaxSupply).ToCoin() > config.Param.MaxCoinCount {
+			return NewMsgError(ErrBadAmount, nil, "max supply cannot be greater than %.8f", config.Param.MaxCoinCount)
+		}
+	case SetMetadata:
+		return checkTokenURI(t.URI, t.Description)
+	default:
+		return NewMsgError(ErrBadMessage, ErrDetails{"action": t.Action}, "there is no token admin action %d", t.Action)
+	}
+	return nil
+}
+
+// The message has no receivers, it is charged as one receiver
+func (t *TokenAdminBody) FeeUnits() int {
+	return 1
+}
+
+func (t *TokenAdminBody) MsgAmount() uint64 {
+	return 0
+}
+
+func (t *TokenAdminBody) MsgToken() arry.Address {
+	return config.Param.MainToken
+}
+
+type TokenPolicyAction uint8
+
+const (
+	// Frozen addresses can neither send nor receive the token
+	FreezeAddress TokenPolicyAction = iota
+	UnfreezeAddress
+	// Allowed addresses can transfer the token when the allowlist is enabled
+	AllowAddress
+	DisallowAddress
+	EnableAllowlist
+	DisableAllowlist
+	// Suspend all transfers of the token
+	PauseToken
+	UnpauseToken
+)
+
+type TokenPolicyBody struct {
+	TokenAddress arry.Address
+	Action       TokenPolicyAction
+	Addresses    []arry.Address
+}
+
+func (t *TokenPolicyBody) MsgTo() types.IReceiver {
+	return NewReceivers()
+}
+
+func (t *TokenPolicyBody) CheckBody(from arry.Address) error {
+	if !kit.CheckTokenAddress(config.Param.Name, t.TokenAddress.String()) {
+		return NewMsgError(ErrBadAddress, nil, "token address verification failed")
+	}
+	switch t.Action {
+	case FreezeAddress, UnfreezeAddress, AllowAddress, DisallowAddress:
+		if len(t.Addresses) == 0 {
+			return NewMsgError(ErrBadMessage, nil, "no addresses")
+		}
+		if len(t.Addresses) > MaxPolicyAddress {
+			return NewMsgError(ErrBadMessage, ErrDetails{"count": len(t.Addresses), "maximum": MaxPolicyAddress},
+				"the maximum number of addresses is %d", MaxPolicyAddress)
+		}
+		exist := make(map[arry.Address]bool, len(t.Addresses))
+		for _, addr := range t.Addresses {
+			if !kit.CheckAddress(config.Param.Name, addr.String()) {
+				return NewMsgError(ErrBadAddress, ErrDetails{"address": addr.String()}, "address %s verification failed", addr.String())
+			}
+			if exist[addr] {
+				return NewMsgError(ErrBadAddress, ErrDetails{"address": addr.String()}, "duplicate address %s", addr.String())
+			}
+			exist[addr] = true
+		}
+	case EnableAllowlist, DisableAllowlist, PauseToken, UnpauseToken:
+		if len(t.Addresses) != 0 {
+			return NewMsgError(ErrBadMessage, nil, "the action does not require addresses")
+		}
+	default:
+		return NewMsgError(ErrBadMessage, ErrDetails{"action": t.Action}, "there is no token policy action %d", t.Action)
+	}
+	return nil
+}
+
+// The policy list changed by the action
+func (t *TokenPolicyBody) List() (PolicyList, bool) {
+	switch t.Action {
+	case FreezeAddress, UnfreezeAddress:
+		return FrozenList, true
+	case AllowAddress, DisallowAddress:
+		return AllowList, true
+	}
+	return 0, false
+}
+
+// Each address written to the policy list is charged as a receiver
+func (t *TokenPolicyBody) FeeUnits() int {
+	if len(t.Addresses) == 0 {
+		return 1
+	}
+	return len(t.Addresses)
+}
+
+func (t *TokenPolicyBody) MsgAmount() uint64 {
+	return 0
+}
+
+func (t *TokenPolicyBody) MsgToken() arry.Address {
+	return config.Param.MainToken
+}
+
+// An operation in the batch, the body must be the body of the type
+type BatchItem struct {
+	Type MessageType
+	Body types.IMessageBody
+}
+
+type rlpBatchItem struct {
+	Type MessageType
+	Body []byte
+}
+
+func (b *BatchItem) EncodeRLP(w io.Writer) error {
+	bytes, err := rlp.EncodeToBytes(b.Body)
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, &rlpBatchItem{Type: b.Type, Body: bytes})
+}
+
+func (b *BatchItem) DecodeRLP(s *rlp.Stream) error {
+	var item rlpBatchItem
+	if err := s.Decode(&item); err != nil {
+		return err
+	}
+	body, err := decodeMsgBody(item.Type, item.Body)
+	if err != nil {
+		return err
+	}
+	b.Type = item.Type
+	b.Body = body
+	return nil
+}
+
+// Multiple operations executed under one nonce, fee and signature.
+// Either all operations are applied or the message is rejected.
+type BatchBody struct {
+	Items []*BatchItem
+}
+
+// All receivers of the operations
+func (b *BatchBody) MsgTo() types.IReceiver {
+	recis := NewReceivers()
+	for _, item := range b.Items {
+		for _, re := range item.Body.MsgTo().ReceiverList() {
+			recis.Add(re.Address, re.Amount)
+		}
+	}
+	return recis
+}
+
+func (b *BatchBody) CheckBody(from arry.Address) error {
+	if len(b.Items) == 0 {
+		return NewMsgError(ErrBadMessage, nil, "no batch operations")
+	}
+	if len(b.Items) > MaxBatchItems {
+		return NewMsgError(ErrBadMessage, ErrDetails{"count": len(b.Items), "maximum": MaxBatchItems}, "the maximum number of batch operations is %d", MaxBatchItems)
+	}
+	for i, item := range b.Items {
+		if item == nil || item.Body == nil {
+			return NewMsgError(ErrBadMessage, ErrDetails{"index": i}, "batch operation %d is empty", i)
+		}
+		if err := checkBatchType(item.Type); err != nil {
+			return fmt.Errorf("batch operation %d: %w", i, err)
+		}
+		if body, ok := item.Body.(*TransactionBody); ok && body.IsCancel(from) {
+			return NewMsgError(ErrBadMessage, ErrDetails{"index": i}, "batch operation %d cannot cancel a message", i)
+		}
+		if err := item.Body.CheckBody(from); err != nil {
+			return fmt.Errorf("batch operation %d: %w", i, err)
+		}
+	}
+	return b.checkConflicts()
+}
+
+// The operations are validated against the state before the batch, so
+// operations that depend on the changes of each other are rejected. A
+// token can be managed by one operation and is not transferred in the
+// same batch, and the candidate can only be registered or cancelled once.
+func (b *BatchBody) checkConflicts() error {
+	managed := make(map[arry.Address]bool)
+	transferred := make(map[arry.Address]bool)
+	candidate := false
+	for i, item := range b.Items {
+		var token arry.Address
+		switch body := item.Body.(type) {
+		case *TokenAdminBody:
+			token = body.TokenAddress
+		case *TokenPolicyBody:
+			token = body.TokenAddress
+		case *TransactionBody:
+			token = body.TokenAddress
+			if managed[token] {
+				return NewMsgError(ErrBadMessage, ErrDetails{"index": i, "token": token.String()},
+					"batch operation %d transfers token %s managed in the batch", i, token.String())
+			}
+			transferred[token] = true
+			continue
+		case *CandidateBody, *CancelBody:
+			if candidate {
+				return NewMsgError(ErrBadMessage, ErrDetails{"index": i},
+					"batch operation %d changes the candidate status again", i)
+			}
+			candidate = true
+			continue
+		default:
+			continue
+		}
+		if managed[token] || transferred[token] {
+			return NewMsgError(ErrBadMessage, ErrDetails{"index": i, "token": token.String()},
+				"batch operation %d manages token %s used in the batch", i, token.String())
+		}
+		managed[token] = true
+	}
+	return nil
+}
+
+// Each operation is charged as a message, and at least one unit
+func (b *BatchBody) FeeUnits() int {
+	units := 0
+	for _, item := range b.Items {
+		if n := FeeUnits(item.Body); n > 1 {
+			units += n
+		} else {
+			units++
+		}
+	}
+	return units
+}
+
+// The amounts of the batch are carried by the operations
+func (b *BatchBody) MsgAmount() uint64 {
+	return 0
+}
+
+func (b *BatchBody) MsgToken() arry.Address {
+	return config.Param.MainToken
+}
+
+// The amount of each token transferred by the batch
+func (b *BatchBody) Amounts() map[string]uint64 {
+	amounts := make(map[string]uint64)
+	for _, item := range b.Items {
+		if item.Type == Transaction {
+			amounts[item.Body.MsgToken().String()] += item.Body.MsgAmount()
+		}
+	}
+	return amounts
+}
+
+// Split the batch into messages of the operations. The messages share
+// the header of the batch, and the fee is only charged by the batch.
+func (b *BatchBody) Messages(msg types.IMessage) []types.IMessage {
+	msgs := make([]types.IMessage, len(b.Items))
+	for i, item := range b.Items {
+		msgs[i] = &Message{
+			Header: &MsgHeader{
+				Type:      item.Type,
+				Hash:      msg.Hash(),
+				From:      msg.From(),
+				Nonce:     msg.Nonce(),
+				Fee:       0,
+				Time:      msg.Time(),
+				Signature: &Signature{},
+			},
+			Body: item.Body,
+		}
+	}
+	return msgs
+}
+
+// Only operations that do not lock pledges can be batched
+func checkBatchType(msgType MessageType) error {
+	switch msgType {
+	case Transaction, TokenAdmin, TokenPolicy, Candidate, Cancel, Vote:
+		return checkMsgType(msgType)
+	}
+	return NewMsgError(ErrBadMessage, nil, "messages of type %d cannot be batched", msgType)
+}
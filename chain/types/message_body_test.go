@@ -0,0 +1,110 @@
+package types
+
+import (
+	"fmt"
+	"github.com/aiot-network/aiotchain/chain/common/kit"
+	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/common/param"
+	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/tools/crypto/ecc/secp256k1"
+	"testing"
+)
+
+func testAddress(t *testing.T, i int) arry.Address {
+	key, err := secp256k1.PrivKeyFromString(fmt.Sprintf("%064x", i+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := kit.GenerateAddress(param.TestNet, key.PubKey().SerializeCompressedString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return arry.StringToAddress(addr)
+}
+
+func testTokenAddress(t *testing.T, shorthand string) arry.Address {
+	addr, err := kit.GenerateTokenAddress(param.TestNet, shorthand)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return arry.StringToAddress(addr)
+}
+
+func transferItem(token arry.Address, to ...arry.Address) *BatchItem {
+	receivers := NewReceivers()
+	for _, addr := range to {
+		receivers.Add(addr, 1e8)
+	}
+	return &BatchItem{Type: Transaction, Body: &TransactionBody{TokenAddress: token, Receivers: receivers}}
+}
+
+func policyItem(token arry.Address, addrs ...arry.Address) *BatchItem {
+	return &BatchItem{Type: TokenPolicy, Body: &TokenPolicyBody{TokenAddress: token, Action: FreezeAddress, Addresses: addrs}}
+}
+
+func adminItem(token, owner arry.Address) *BatchItem {
+	return &BatchItem{Type: TokenAdmin, Body: &TokenAdminBody{TokenAddress: token, Action: TransferOwner, NewOwner: owner}}
+}
+
+func TestBatchBody_FeeUnits(t *testing.T) {
+	config.Param = param.TestNetParam
+	token := testTokenAddress(t, "ABC")
+	body := &BatchBody{Items: []*BatchItem{
+		transferItem(config.Param.MainToken, testAddress(t, 1), testAddress(t, 2)),
+		policyItem(token, testAddress(t, 3), testAddress(t, 4), testAddress(t, 5)),
+		adminItem(testTokenAddress(t, "DEF"), testAddress(t, 6)),
+	}}
+	if units := FeeUnits(body); units != 6 {
+		t.Fatalf("the batch should be charged 6 units, got %d", units)
+	}
+}
+
+func TestBatchBody_Conflicts(t *testing.T) {
+	config.Param = param.TestNetParam
+	from := testAddress(t, 0)
+	token := testTokenAddress(t, "ABC")
+	other := testTokenAddress(t, "DEF")
+
+	tests := []struct {
+		name  string
+		items []*BatchItem
+		ok    bool
+	}{
+		{"independent", []*BatchItem{
+			transferItem(config.Param.MainToken, testAddress(t, 1)),
+			transferItem(token, testAddress(t, 1)),
+			policyItem(other, testAddress(t, 2)),
+		}, true},
+		{"transfers of the same token", []*BatchItem{
+			transferItem(token, testAddress(t, 1)),
+			transferItem(token, testAddress(t, 2)),
+		}, true},
+		{"transfer after a policy", []*BatchItem{
+			policyItem(token, testAddress(t, 1)),
+			transferItem(token, testAddress(t, 1)),
+		}, false},
+		{"policy after a transfer", []*BatchItem{
+			transferItem(token, testAddress(t, 1)),
+			policyItem(token, testAddress(t, 1)),
+		}, false},
+		{"two policies", []*BatchItem{
+			policyItem(token, testAddress(t, 1)),
+			policyItem(token, testAddress(t, 2)),
+		}, false},
+		{"policy after an owner transfer", []*BatchItem{
+			adminItem(token, testAddress(t, 1)),
+			policyItem(token, testAddress(t, 2)),
+		}, false},
+	}
+	for _, test := range tests {
+		err := (&BatchBody{Items: test.items}).CheckBody(from)
+		if test.ok && err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if !test.ok {
+			if msgErr, ok := AsMsgError(err); !ok || msgErr.Code != ErrBadMessage {
+				t.Fatalf("%s: the batch should be rejected, got %v", test.name, err)
+			}
+		}
+	}
+}
@@ -1,11 +1,10 @@
 package types
 
 import (
-	"errors"
-	"fmt"
 	"github.com/aiot-network/aiotchain/chain/common/kit"
 	"github.com/aiot-network/aiotchain/common/config"
 	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/types"
 )
 
 type MessageType uint8
@@ -19,6 +18,9 @@ const (
 	Work
 	TokenV2
 	Redemption
+	TokenAdmin
+	TokenPolicy
+	Batch
 )
 
 const (
@@ -26,6 +28,9 @@ const (
 	maxFees = 1e9
 )
 
+// The ValidUntil values below the threshold are block heights, otherwise unix times
+const validUntilTimeThreshold = 500000000
+
 type MsgHeader struct {
 	Type      MessageType
 	Hash      arry.Hash
@@ -34,6 +39,8 @@ type MsgHeader struct {
 	Fee       uint64
 	Time      uint64
 	Signature *Signature
+	// The last block height or time the message can be packaged in, 0 is unlimited
+	ValidUntil uint64 `rlp:"optional"`
 }
 
 func (m *MsgHeader) Check() error {
@@ -52,7 +59,11 @@ func (m *MsgHeader) Check() error {
 }
 
 func (m *MsgHeader) checkType() error {
-	switch m.Type {
+	return checkMsgType(m.Type)
+}
+
+func checkMsgType(msgType MessageType) error {
+	switch msgType {
 	case Transaction:
 		return nil
 		//case Token:
@@ -65,30 +76,103 @@ func (m *MsgHeader) checkType() error {
 		return nil
 	case Redemption:
 		return nil
+	case TokenAdmin:
+		return nil
+	case TokenPolicy:
+		return nil
+	case Batch:
+		return nil
 	}
-	return fmt.Errorf("there are no messages of type %d", m.Type)
+	return NewMsgError(ErrBadMessage, ErrDetails{"type": msgType}, "there are no messages of type %d", msgType)
 }
 
 func (m *MsgHeader) checkFrom() error {
 	if !kit.CheckAddress(config.Param.Name, m.From.String()) {
-		return fmt.Errorf("%s address illegal", m.From.String())
+		return NewMsgError(ErrBadAddress, ErrDetails{"address": m.From.String()}, "%s address illegal", m.From.String())
 	}
 	return nil
 }
 
 func (m *MsgHeader) checkSinger() error {
 	if !Verify(m.Hash, m.Signature) {
-		return errors.New("signature verification failed")
+		return NewMsgError(ErrBadSignature, nil, "signature verification failed")
 	}
 
 	if !VerifySigner(config.Param.Name, m.From, m.Signature.PubKey) {
-		return errors.New("signer and sender do not match")
+		return NewMsgError(ErrBadSignature, nil, "signer and sender do not match")
 	}
 
 	if m.Type == Work {
 		if m.From.String() != config.Param.WorkProofAddress {
-			return errors.New("incorrect signature address")
+			return NewMsgError(ErrBadSignature, nil, "incorrect signature address")
+		}
+	}
+	return nil
+}
+
+// Whether the message valid until the height or time has expired
+// for the block of the height and time
+func IsExpired(validUntil, height, blockTime uint64) bool {
+	if validUntil == 0 {
+		return false
+	}
+	if validUntil < validUntilTimeThreshold {
+		return height > validUntil
+	}
+	return blockTime > validUntil
+}
+
+// The ValidUntil is only enforced from the ValidUntilHeight, before
+// it the field is ignored as it was before the fork
+func CheckExpired(msg types.IMessage, height, blockTime uint64) error {
+	if height >= config.Param.ValidUntilHeight && IsExpired(msg.ValidUntil(), height, blockTime) {
+		return NewMsgError(ErrExpired, ErrDetails{"validuntil": msg.ValidUntil(), "height": height, "time": blockTime},
+			"the message is only valid until %d", msg.ValidUntil())
+	}
+	return nil
+}
+
+// Messages of the types added by a fork are only valid from its height,
+// before it they are rejected as they were before the fork
+func CheckActivation(msg types.IMessage, height uint64) error {
+	if err := checkTypeHeight(MessageType(msg.Type()), height); err != nil {
+		return err
+	}
+	// The operations of a batch are activated with their message types
+	if body, ok := msg.MsgBody().(*BatchBody); ok {
+		for _, item := range body.Items {
+			if err := checkTypeHeight(item.Type, height); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
+
+func checkTypeHeight(msgType MessageType, height uint64) error {
+	var activation uint64
+	switch msgType {
+	case TokenAdmin:
+		activation = config.Param.TokenAdminHeight
+	case TokenPolicy:
+		activation = config.Param.TokenPolicyHeight
+	case Batch:
+		activation = config.Param.BatchHeight
+	}
+	if height < activation {
+		return NewMsgError(ErrBadMessage, ErrDetails{"type": msgType, "height": height, "activation": activation},
+			"messages of type %d are valid from height %d", msgType, activation)
+	}
+	return nil
+}
+
+// Cancel messages are only valid from the CancelHeight, before it the
+// zero-value transfer is rejected as it was before the fork
+func CheckCancel(msg types.IMessage, height uint64) error {
+	body, ok := msg.MsgBody().(*TransactionBody)
+	if ok && height < config.Param.CancelHeight && body.IsCancel(msg.From()) {
+		return NewMsgError(ErrBadAmount, ErrDetails{"height": height, "cancelheight": config.Param.CancelHeight},
+			"cancel messages are valid from height %d", config.Param.CancelHeight)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/common/param"
+	"testing"
+)
+
+func TestCheckActivation(t *testing.T) {
+	testParam := *param.TestNetParam
+	tokenParam := *testParam.TokenParam
+	tokenParam.TokenAdminHeight = 10
+	tokenParam.TokenPolicyHeight = 20
+	tokenParam.BatchHeight = 5
+	testParam.TokenParam = &tokenParam
+	config.Param = &testParam
+	defer func() { config.Param = param.TestNetParam }()
+
+	tests := []struct {
+		name   string
+		msg    *Message
+		height uint64
+		ok     bool
+	}{
+		{"transaction", &Message{Header: &MsgHeader{Type: Transaction}, Body: &TransactionBody{}}, 1, true},
+		{"token admin before the fork", &Message{Header: &MsgHeader{Type: TokenAdmin}, Body: &TokenAdminBody{}}, 9, false},
+		{"token admin at the fork", &Message{Header: &MsgHeader{Type: TokenAdmin}, Body: &TokenAdminBody{}}, 10, true},
+		{"token policy before the fork", &Message{Header: &MsgHeader{Type: TokenPolicy}, Body: &TokenPolicyBody{}}, 19, false},
+		{"token policy at the fork", &Message{Header: &MsgHeader{Type: TokenPolicy}, Body: &TokenPolicyBody{}}, 20, true},
+		{"batch before the fork", &Message{Header: &MsgHeader{Type: Batch}, Body: &BatchBody{}}, 4, false},
+		{"batch at the fork", &Message{Header: &MsgHeader{Type: Batch}, Body: &BatchBody{}}, 5, true},
+		{"batch of a policy before its fork", &Message{Header: &MsgHeader{Type: Batch}, Body: &BatchBody{Items: []*BatchItem{
+			{Type: TokenPolicy, Body: &TokenPolicyBody{}},
+		}}}, 19, false},
+		{"batch of a policy at its fork", &Message{Header: &MsgHeader{Type: Batch}, Body: &BatchBody{Items: []*BatchItem{
+			{Type: TokenPolicy, Body: &TokenPolicyBody{}},
+		}}}, 20, true},
+	}
+	for _, test := range tests {
+		err := CheckActivation(test.msg, test.height)
+		if test.ok && err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if !test.ok {
+			if msgErr, ok := AsMsgError(err); !ok || msgErr.Code != ErrBadMessage {
+				t.Fatalf("%s: the message should be rejected, got %v", test.name, err)
+			}
+		}
+	}
+}
+
+func TestCheckExpired(t *testing.T) {
+	testParam := *param.TestNetParam
+	tokenParam := *testParam.TokenParam
+	tokenParam.ValidUntilHeight = 10
+	testParam.TokenParam = &tokenParam
+	config.Param = &testParam
+	defer func() { config.Param = param.TestNetParam }()
+
+	tests := []struct {
+		name       string
+		validUntil uint64
+		height     uint64
+		ok         bool
+	}{
+		{"unlimited", 0, 100, true},
+		{"expired before the fork", 5, 9, true},
+		{"expired at the fork", 5, 10, false},
+		{"valid at the height", 10, 10, true},
+		{"expired time", validUntilTimeThreshold, 20, false},
+	}
+	for _, test := range tests {
+		msg := &Message{Header: &MsgHeader{Type: Transaction, ValidUntil: test.validUntil}, Body: &TransactionBody{}}
+		err := CheckExpired(msg, test.height, validUntilTimeThreshold+1)
+		if test.ok && err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if !test.ok {
+			if msgErr, ok := AsMsgError(err); !ok || msgErr.Code != ErrExpired {
+				t.Fatalf("%s: the message should be expired, got %v", test.name, err)
+			}
+		}
+	}
+}
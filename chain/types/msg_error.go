@@ -0,0 +1,89 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The code of the message error, it is returned as the
+// response code of rpc, so that clients can recognize the error.
+type ErrCode int32
+
+const (
+	ErrInsufficientBalance ErrCode = iota + 100
+	ErrNonceTooLow
+	ErrNonceTooHigh
+	ErrFeeTooLow
+	ErrBadAddress
+	ErrBadAmount
+	ErrBadSignature
+	ErrBadTime
+	ErrTokenNotFound
+	ErrTokenRestricted
+	ErrPermissionDenied
+	ErrMsgExists
+	ErrPoolFull
+	ErrAddressLimit
+	ErrPeerLimit
+	ErrMsgRejected
+	ErrExpired
+	ErrBadMessage
+	ErrPolicyLimit
+	ErrTokenConflict
+)
+
+var errCodeNames = map[ErrCode]string{
+	ErrInsufficientBalance: "insufficient_balance",
+	ErrNonceTooLow:         "nonce_too_low",
+	ErrNonceTooHigh:        "nonce_too_high",
+	ErrFeeTooLow:           "fee_too_low",
+	ErrBadAddress:          "bad_address",
+	ErrBadAmount:           "bad_amount",
+	ErrBadSignature:        "bad_signature",
+	ErrBadTime:             "bad_time",
+	ErrTokenNotFound:       "token_not_found",
+	ErrTokenRestricted:     "token_restricted",
+	ErrPermissionDenied:    "permission_denied",
+	ErrMsgExists:           "message_exists",
+	ErrPoolFull:            "pool_full",
+	ErrAddressLimit:        "address_limit",
+	ErrPeerLimit:           "peer_limit",
+	ErrMsgRejected:         "message_rejected",
+	ErrExpired:             "expired",
+	ErrBadMessage:          "bad_message",
+	ErrPolicyLimit:         "policy_limit",
+	ErrTokenConflict:       "token_conflict",
+}
+
+func (e ErrCode) String() string {
+	if name, ok := errCodeNames[e]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Machine readable information of the error
+type ErrDetails map[string]interface{}
+
+type MsgError struct {
+	Code    ErrCode
+	Details ErrDetails
+	msg     string
+}
+
+func NewMsgError(code ErrCode, details ErrDetails, format string, a ...interface{}) *MsgError {
+	return &MsgError{Code: code, Details: details, msg: fmt.Sprintf(format, a...)}
+}
+
+func (m *MsgError) Error() string {
+	return m.msg
+}
+
+// Find the message error in the chain of wrapped errors
+func AsMsgError(err error) (*MsgError, bool) {
+	var msgErr *MsgError
+	if errors.As(err, &msgErr) {
+		return msgErr, true
+	}
+	return nil, false
+}
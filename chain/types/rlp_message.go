@@ -1,6 +1,7 @@
 package types
 
 import (
+	"fmt"
 	"github.com/aiot-network/aiotchain/tools/rlp"
 	"github.com/aiot-network/aiotchain/types"
 )
@@ -13,41 +14,58 @@ type RlpMessage struct {
 func (r *RlpMessage) ToMessage() types.IMessage {
 	msg := &Message{}
 	msg.Header = r.MsgHeader
-	switch r.MsgHeader.Type {
+	msg.Body, _ = decodeMsgBody(r.MsgHeader.Type, r.MsgBody)
+	return msg
+}
+
+func decodeMsgBody(msgType MessageType, bytes []byte) (types.IMessageBody, error) {
+	switch msgType {
 	case Transaction:
 		var body *TransactionBody
-		rlp.DecodeBytes(r.MsgBody, &body)
-		msg.Body = body
+		err := rlp.DecodeBytes(bytes, &body)
+		return body, err
 	case Token:
 		var body *TokenBody
-		rlp.DecodeBytes(r.MsgBody, &body)
-		msg.Body = body
+		err := rlp.DecodeBytes(bytes, &body)
+		return body, err
 	case TokenV2:
 		var body *TokenV2Body
-		rlp.DecodeBytes(r.MsgBody, &body)
-		msg.Body = body
+		err := rlp.DecodeBytes(bytes, &body)
+		return body, err
 	case Redemption:
 		var body *RedemptionBody
-		rlp.DecodeBytes(r.MsgBody, &body)
-		msg.Body = body
+		err := rlp.DecodeBytes(bytes, &body)
+		return body, err
+	case TokenAdmin:
+		var body *TokenAdminBody
+		err := rlp.DecodeBytes(bytes, &body)
+		return body, err
+	case TokenPolicy:
+		var body *TokenPolicyBody
+		err := rlp.DecodeBytes(bytes, &body)
+		return body, err
 	case Candidate:
 		var body *CandidateBody
-		rlp.DecodeBytes(r.MsgBody, &body)
-		msg.Body = body
+		err := rlp.DecodeBytes(bytes, &body)
+		return body, err
 	case Cancel:
 		var body *CancelBody
-		rlp.DecodeBytes(r.MsgBody, &body)
-		msg.Body = body
+		err := rlp.DecodeBytes(bytes, &body)
+		return body, err
 	case Vote:
 		var body *VoteBody
-		rlp.DecodeBytes(r.MsgBody, &body)
-		msg.Body = body
+		err := rlp.DecodeBytes(bytes, &body)
+		return body, err
 	case Work:
 		var body *WorkBody
-		rlp.DecodeBytes(r.MsgBody, &body)
-		msg.Body = body
+		err := rlp.DecodeBytes(bytes, &body)
+		return body, err
+	case Batch:
+		var body *BatchBody
+		err := rlp.DecodeBytes(bytes, &body)
+		return body, err
 	}
-	return msg
+	return nil, fmt.Errorf("there are no messages of type %d", msgType)
 }
 
 func (r *RlpMessage) Bytes() []byte {
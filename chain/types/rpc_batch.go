@@ -0,0 +1,10 @@
+package types
+
+type RpcBatchItem struct {
+	Type MessageType     `json:"type"`
+	Body IRpcMessageBody `json:"body"`
+}
+
+type RpcBatchBody struct {
+	Items []*RpcBatchItem `json:"items"`
+}
@@ -20,6 +20,8 @@ type RpcMessageHeader struct {
 	Fee       uint64        `json:"fee"`
 	Time      uint64        `json:"time"`
 	Signature *RpcSignature `json:"signscript"`
+	// Omitted when it is 0, so the hash of messages without it does not change
+	ValidUntil uint64 `json:"validuntil,omitempty"`
 }
 
 type RpcMessage struct {
@@ -48,11 +50,60 @@ func RpcMsgToMsg(rpcMsg *RpcMessage) (*Message, error) {
 	if err != nil {
 		return nil, err
 	}
-	var msgBody types.IMessageBody
-	switch rpcMsg.MsgHeader.Type {
+	msgBody, err := RpcMsgBodyToBody(rpcMsg.MsgHeader.Type, rpcMsg.MsgBody)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := arry.StringToHash(rpcMsg.MsgHeader.MsgHash)
+	if err != nil {
+		return nil, fmt.Errorf("wrong message hash %s", rpcMsg.MsgHeader.MsgHash)
+	}
+	tx := &Message{
+		Header: &MsgHeader{
+			Hash:       hash,
+			Type:       rpcMsg.MsgHeader.Type,
+			From:       arry.StringToAddress(rpcMsg.MsgHeader.From),
+			Nonce:      rpcMsg.MsgHeader.Nonce,
+			Fee:        rpcMsg.MsgHeader.Fee,
+			Time:       rpcMsg.MsgHeader.Time,
+			Signature:  signScript,
+			ValidUntil: rpcMsg.MsgHeader.ValidUntil,
+		},
+		Body: msgBody,
+	}
+	return tx, nil
+}
+
+func MsgToRpcMsg(msg types.IMessage) (*RpcMessage, error) {
+	rpcMsg := &RpcMessage{
+		MsgHeader: &RpcMessageHeader{
+			MsgHash: msg.Hash().String(),
+			Type:    MessageType(msg.Type()),
+			From:    addressToString(msg.From()),
+			Nonce:   msg.Nonce(),
+			Fee:     msg.Fee(),
+			Time:    msg.Time(),
+			Signature: &RpcSignature{
+				Signature: msg.Signature(),
+				PubKey:    msg.PublicKey(),
+			},
+			ValidUntil: msg.ValidUntil(),
+		},
+		MsgBody: nil,
+	}
+	body, err := MsgBodyToRpcBody(MessageType(msg.Type()), msg.MsgBody())
+	if err != nil {
+		return nil, err
+	}
+	rpcMsg.MsgBody = body
+	return rpcMsg, nil
+}
+
+func RpcMsgBodyToBody(msgType MessageType, rpcBody IRpcMessageBody) (types.IMessageBody, error) {
+	switch msgType {
 	case Transaction:
 		body := &RpcTransactionBody{}
-		bytes, err := json.Marshal(rpcMsg.MsgBody)
+		bytes, err := json.Marshal(rpcBody)
 		if err != nil {
 			return nil, err
 		}
@@ -60,10 +111,10 @@ func RpcMsgToMsg(rpcMsg *RpcMessage) (*Message, error) {
 		if err != nil {
 			return nil, err
 		}
-		msgBody, err = RpcTransactionBodyToBody(body)
+		return RpcTransactionBodyToBody(body)
 	case Token:
 		body := &RpcTokenBody{}
-		bytes, err := json.Marshal(rpcMsg.MsgBody)
+		bytes, err := json.Marshal(rpcBody)
 		if err != nil {
 			return nil, err
 		}
@@ -71,10 +122,10 @@ func RpcMsgToMsg(rpcMsg *RpcMessage) (*Message, error) {
 		if err != nil {
 			return nil, err
 		}
-		msgBody, err = RpcTokenBodyToBody(body)
+		return RpcTokenBodyToBody(body)
 	case TokenV2:
 		body := &RpcTokenBody{}
-		bytes, err := json.Marshal(rpcMsg.MsgBody)
+		bytes, err := json.Marshal(rpcBody)
 		if err != nil {
 			return nil, err
 		}
@@ -82,10 +133,32 @@ func RpcMsgToMsg(rpcMsg *RpcMessage) (*Message, error) {
 		if err != nil {
 			return nil, err
 		}
-		msgBody, err = RpcTokenBodyToV2Body(body)
+		return RpcTokenBodyToV2Body(body)
 	case Redemption:
 		body := &RpcRedemptionBody{}
-		bytes, err := json.Marshal(rpcMsg.MsgBody)
+		bytes, err := json.Marshal(rpcBody)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(bytes, body)
+		if err != nil {
+			return nil, err
+		}
+		return RpcRedemptionBodyToBody(body)
+	case TokenAdmin:
+		body := &RpcTokenAdminBody{}
+		bytes, err := json.Marshal(rpcBody)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(bytes, body)
+		if err != nil {
+			return nil, err
+		}
+		return RpcTokenAdminBodyToBody(body)
+	case TokenPolicy:
+		body := &RpcTokenPolicyBody{}
+		bytes, err := json.Marshal(rpcBody)
 		if err != nil {
 			return nil, err
 		}
@@ -93,10 +166,10 @@ func RpcMsgToMsg(rpcMsg *RpcMessage) (*Message, error) {
 		if err != nil {
 			return nil, err
 		}
-		msgBody, err = RpcRedemptionBodyToBody(body)
+		return RpcTokenPolicyBodyToBody(body)
 	case Candidate:
 		body := &RpcCandidateBody{}
-		bytes, err := json.Marshal(rpcMsg.MsgBody)
+		bytes, err := json.Marshal(rpcBody)
 		if err != nil {
 			return nil, err
 		}
@@ -104,12 +177,12 @@ func RpcMsgToMsg(rpcMsg *RpcMessage) (*Message, error) {
 		if err != nil {
 			return nil, err
 		}
-		msgBody, err = RpcCandidateBodyToBody(body)
+		return RpcCandidateBodyToBody(body)
 	case Cancel:
-		msgBody = &CancelBody{}
+		return &CancelBody{}, nil
 	case Vote:
 		body := &RpcVoteBody{}
-		bytes, err := json.Marshal(rpcMsg.MsgBody)
+		bytes, err := json.Marshal(rpcBody)
 		if err != nil {
 			return nil, err
 		}
@@ -117,10 +190,21 @@ func RpcMsgToMsg(rpcMsg *RpcMessage) (*Message, error) {
 		if err != nil {
 			return nil, err
 		}
-		msgBody, err = RpcVoteBodyToBody(body)
+		return RpcVoteBodyToBody(body)
+	case Batch:
+		body := &RpcBatchBody{}
+		bytes, err := json.Marshal(rpcBody)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(bytes, body)
+		if err != nil {
+			return nil, err
+		}
+		return RpcBatchBodyToBody(body)
 	case Work:
 		body := &RpcWorkBody{}
-		bytes, err := json.Marshal(rpcMsg.MsgBody)
+		bytes, err := json.Marshal(rpcBody)
 		if err != nil {
 			return nil, err
 		}
@@ -128,112 +212,131 @@ func RpcMsgToMsg(rpcMsg *RpcMessage) (*Message, error) {
 		if err != nil {
 			return nil, err
 		}
-		msgBody, err = RpcWorkBodyToBody(body)
-	}
-	if err != nil {
-		return nil, err
-	}
-	hash, err := arry.StringToHash(rpcMsg.MsgHeader.MsgHash)
-	if err != nil {
-		return nil, fmt.Errorf("wrong message hash %s", rpcMsg.MsgHeader.MsgHash)
+		return RpcWorkBodyToBody(body)
 	}
-	tx := &Message{
-		Header: &MsgHeader{
-			Hash:      hash,
-			Type:      rpcMsg.MsgHeader.Type,
-			From:      arry.StringToAddress(rpcMsg.MsgHeader.From),
-			Nonce:     rpcMsg.MsgHeader.Nonce,
-			Fee:       rpcMsg.MsgHeader.Fee,
-			Time:      rpcMsg.MsgHeader.Time,
-			Signature: signScript,
-		},
-		Body: msgBody,
-	}
-	return tx, nil
+	return nil, fmt.Errorf("there are no messages of type %d", msgType)
 }
 
-func MsgToRpcMsg(msg types.IMessage) (*RpcMessage, error) {
-	rpcMsg := &RpcMessage{
-		MsgHeader: &RpcMessageHeader{
-			MsgHash: msg.Hash().String(),
-			Type:    MessageType(msg.Type()),
-			From:    addressToString(msg.From()),
-			Nonce:   msg.Nonce(),
-			Fee:     msg.Fee(),
-			Time:    msg.Time(),
-			Signature: &RpcSignature{
-				Signature: msg.Signature(),
-				PubKey:    msg.PublicKey(),
-			}},
-		MsgBody: nil,
-	}
-	switch MessageType(msg.Type()) {
+func MsgBodyToRpcBody(msgType MessageType, msgBody types.IMessageBody) (IRpcMessageBody, error) {
+	switch msgType {
 	case Transaction:
 		rpcRecis := []RpcReceiver{}
-		for _, re := range msg.MsgBody().MsgTo().ReceiverList() {
+		for _, re := range msgBody.MsgTo().ReceiverList() {
 			rpcRecis = append(rpcRecis, RpcReceiver{
 				Address: re.Address.String(),
 				Amount:  re.Amount,
 			})
 		}
-		rpcMsg.MsgBody = RpcTransactionBody{
-			Token:     msg.MsgBody().MsgToken().String(),
+		return RpcTransactionBody{
+			Token:     msgBody.MsgToken().String(),
 			Receivers: rpcRecis,
-		}
+		}, nil
 	case Token:
-		body, ok := msg.MsgBody().(*TokenBody)
+		body, ok := msgBody.(*TokenBody)
 		if !ok {
 			return nil, errors.New("message type error")
 		}
 
-		rpcMsg.MsgBody = &RpcTokenBody{
-			Address:        msg.MsgBody().MsgToken().String(),
-			Receiver:       msg.MsgBody().MsgTo().ReceiverList()[0].Address.String(),
+		rpcBody := &RpcTokenBody{
+			Address:        msgBody.MsgToken().String(),
+			Receiver:       msgBody.MsgTo().ReceiverList()[0].Address.String(),
 			Name:           body.Name,
 			Shorthand:      body.Shorthand,
 			IncreaseIssues: body.IncreaseIssues,
-			Amount:         msg.MsgBody().MsgAmount(),
+			Amount:         msgBody.MsgAmount(),
 		}
+		rpcBody.setMeta(body.Meta)
+		return rpcBody, nil
 	case TokenV2:
-		body, ok := msg.MsgBody().(*TokenV2Body)
+		body, ok := msgBody.(*TokenV2Body)
 		if !ok {
 			return nil, errors.New("message type error")
 		}
 
-		rpcMsg.MsgBody = &RpcTokenBody{
-			Address:        msg.MsgBody().MsgToken().String(),
-			Receiver:       msg.MsgBody().MsgTo().ReceiverList()[0].Address.String(),
+		rpcBody := &RpcTokenBody{
+			Address:        msgBody.MsgToken().String(),
+			Receiver:       msgBody.MsgTo().ReceiverList()[0].Address.String(),
 			Name:           body.Name,
 			Shorthand:      body.Shorthand,
-			Amount:         msg.MsgBody().MsgAmount(),
+			Amount:         msgBody.MsgAmount(),
 			PledgeRate:     int(body.PledgeRate),
 			IncreaseIssues: false,
 		}
+		rpcBody.setMeta(body.Meta)
+		return rpcBody, nil
 	case Redemption:
-		body, ok := msg.MsgBody().(*RedemptionBody)
+		body, ok := msgBody.(*RedemptionBody)
 		if !ok {
 			return nil, errors.New("message type error")
 		}
 
-		rpcMsg.MsgBody = &RpcRedemptionBody{
-			Address:    msg.MsgBody().MsgToken().String(),
+		return &RpcRedemptionBody{
+			Address:    msgBody.MsgToken().String(),
 			PledgeRate: int(body.PledgeRate),
 			Amount:     body.Amount,
+		}, nil
+	case TokenAdmin:
+		body, ok := msgBody.(*TokenAdminBody)
+		if !ok {
+			return nil, errors.New("message type error")
+		}
+
+		return &RpcTokenAdminBody{
+			Address:     body.TokenAddress.String(),
+			Action:      int(body.Action),
+			NewOwner:    body.NewOwner.String(),
+			MaxSupply:   body.MaxSupply,
+			URI:         body.URI,
+			Description: body.Description,
+		}, nil
+	case TokenPolicy:
+		body, ok := msgBody.(*TokenPolicyBody)
+		if !ok {
+			return nil, errors.New("message type error")
 		}
+		addrs := []string{}
+		for _, addr := range body.Addresses {
+			addrs = append(addrs, addr.String())
+		}
+		return &RpcTokenPolicyBody{
+			Address:   body.TokenAddress.String(),
+			Action:    int(body.Action),
+			Addresses: addrs,
+		}, nil
 	case Candidate:
-		body, ok := msg.MsgBody().(*CandidateBody)
+		body, ok := msgBody.(*CandidateBody)
 		if !ok {
 			return nil, errors.New("message type error")
 		}
-		rpcMsg.MsgBody = &RpcCandidateBody{
+		return &RpcCandidateBody{
 			PeerId: body.Peer.String(),
-		}
+		}, nil
 	case Cancel:
-		rpcMsg.MsgBody = &RpcCancelBody{}
+		return &RpcCancelBody{}, nil
 	case Vote:
-		rpcMsg.MsgBody = &RpcVoteBody{To: msg.MsgBody().MsgTo().ReceiverList()[0].Address.String()}
+		return &RpcVoteBody{To: msgBody.MsgTo().ReceiverList()[0].Address.String()}, nil
+	case Batch:
+		body, ok := msgBody.(*BatchBody)
+		if !ok {
+			return nil, errors.New("message type error")
+		}
+		items := []*RpcBatchItem{}
+		for _, item := range body.Items {
+			if item == nil || item.Body == nil {
+				return nil, errors.New("incomplete batch operation")
+			}
+			rpcBody, err := MsgBodyToRpcBody(item.Type, item.Body)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, &RpcBatchItem{
+				Type: item.Type,
+				Body: rpcBody,
+			})
+		}
+		return &RpcBatchBody{Items: items}, nil
 	case Work:
-		body, ok := msg.MsgBody().(*WorkBody)
+		body, ok := msgBody.(*WorkBody)
 		if !ok {
 			return nil, errors.New("message type error")
 		}
@@ -245,14 +348,13 @@ func MsgToRpcMsg(msg types.IMessage) (*RpcMessage, error) {
 				EndTime:  w.EndTime,
 			})
 		}
-		rpcMsg.MsgBody = &RpcWorkBody{
+		return &RpcWorkBody{
 			StartTime: body.StartTime,
 			EndTime:   body.EndTime,
 			List:      list,
-		}
+		}, nil
 	}
-
-	return rpcMsg, nil
+	return nil, nil
 }
 
 func RpcSignatureToSignature(rpcSignScript *RpcSignature) (*Signature, error) {
@@ -294,6 +396,10 @@ func RpcTokenBodyToBody(rpcBody *RpcTokenBody) (*TokenBody, error) {
 	if rpcBody == nil {
 		return nil, errors.New("wrong token body")
 	}
+	meta, err := rpcBody.meta()
+	if err != nil {
+		return nil, err
+	}
 	return &TokenBody{
 		TokenAddress:   arry.StringToAddress(rpcBody.Address),
 		Receiver:       arry.StringToAddress(rpcBody.Receiver),
@@ -301,6 +407,7 @@ func RpcTokenBodyToBody(rpcBody *RpcTokenBody) (*TokenBody, error) {
 		Shorthand:      rpcBody.Shorthand,
 		IncreaseIssues: rpcBody.IncreaseIssues,
 		Amount:         rpcBody.Amount,
+		Meta:           meta,
 	}, nil
 }
 
@@ -308,6 +415,10 @@ func RpcTokenBodyToV2Body(rpcBody *RpcTokenBody) (*TokenV2Body, error) {
 	if rpcBody == nil {
 		return nil, errors.New("wrong token body")
 	}
+	meta, err := rpcBody.meta()
+	if err != nil {
+		return nil, err
+	}
 	return &TokenV2Body{
 		TokenAddress: arry.StringToAddress(rpcBody.Address),
 		Receiver:     arry.StringToAddress(rpcBody.Receiver),
@@ -315,6 +426,7 @@ func RpcTokenBodyToV2Body(rpcBody *RpcTokenBody) (*TokenV2Body, error) {
 		Shorthand:    rpcBody.Shorthand,
 		Amount:       rpcBody.Amount,
 		PledgeRate:   PledgeRate(rpcBody.PledgeRate),
+		Meta:         meta,
 	}, nil
 }
 
@@ -329,6 +441,59 @@ func RpcRedemptionBodyToBody(rpcBody *RpcRedemptionBody) (*RedemptionBody, error
 	}, nil
 }
 
+func RpcTokenAdminBodyToBody(rpcBody *RpcTokenAdminBody) (*TokenAdminBody, error) {
+	if rpcBody == nil {
+		return nil, errors.New("wrong token admin body")
+	}
+	return &TokenAdminBody{
+		TokenAddress: arry.StringToAddress(rpcBody.Address),
+		Action:       TokenAdminAction(rpcBody.Action),
+		NewOwner:     arry.StringToAddress(rpcBody.NewOwner),
+		MaxSupply:    rpcBody.MaxSupply,
+		URI:          rpcBody.URI,
+		Description:  rpcBody.Description,
+	}, nil
+}
+
+func RpcTokenPolicyBodyToBody(rpcBody *RpcTokenPolicyBody) (*TokenPolicyBody, error) {
+	if rpcBody == nil {
+		return nil, errors.New("wrong token policy body")
+	}
+	addrs := []arry.Address{}
+	for _, addr := range rpcBody.Addresses {
+		addrs = append(addrs, arry.StringToAddress(addr))
+	}
+	return &TokenPolicyBody{
+		TokenAddress: arry.StringToAddress(rpcBody.Address),
+		Action:       TokenPolicyAction(rpcBody.Action),
+		Addresses:    addrs,
+	}, nil
+}
+
+func RpcBatchBodyToBody(rpcBody *RpcBatchBody) (*BatchBody, error) {
+	if rpcBody == nil {
+		return nil, errors.New("wrong batch body")
+	}
+	items := []*BatchItem{}
+	for _, rpcItem := range rpcBody.Items {
+		if rpcItem == nil {
+			return nil, errors.New("wrong batch body")
+		}
+		if rpcItem.Type == Batch {
+			return nil, errors.New("batch cannot be nested")
+		}
+		body, err := RpcMsgBodyToBody(rpcItem.Type, rpcItem.Body)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, &BatchItem{
+			Type: rpcItem.Type,
+			Body: body,
+		})
+	}
+	return &BatchBody{Items: items}, nil
+}
+
 func RpcCandidateBodyToBody(rpcBody *RpcCandidateBody) (*CandidateBody, error) {
 	if rpcBody == nil {
 		return nil, errors.New("wrong candidate body")
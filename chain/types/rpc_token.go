@@ -1,5 +1,7 @@
 package types
 
+import "errors"
+
 type RpcTokenBody struct {
 	Address        string `json:"address"`
 	Receiver       string `json:"receiver"`
@@ -8,4 +10,30 @@ type RpcTokenBody struct {
 	Amount         uint64 `json:"amount"`
 	IncreaseIssues bool   `json:"allowedincrease"`
 	PledgeRate     int    `json:"pledgerate"`
+	// The metadata is omitted for tokens issued before it was introduced
+	Decimals    *uint8 `json:"decimals,omitempty"`
+	URI         string `json:"uri,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func (r *RpcTokenBody) setMeta(meta *TokenMeta) {
+	if meta == nil {
+		return
+	}
+	decimals := meta.Decimals
+	r.Decimals = &decimals
+	r.URI = meta.URI
+	r.Description = meta.Description
+}
+
+// The metadata requires the decimals, so that the body
+// converts back to the same rpc body
+func (r *RpcTokenBody) meta() (*TokenMeta, error) {
+	if r.Decimals == nil {
+		if r.URI != "" || r.Description != "" {
+			return nil, errors.New("the decimals of the token metadata is required")
+		}
+		return nil, nil
+	}
+	return &TokenMeta{Decimals: *r.Decimals, URI: r.URI, Description: r.Description}, nil
 }
@@ -0,0 +1,10 @@
+package types
+
+type RpcTokenAdminBody struct {
+	Address     string `json:"address"`
+	Action      int    `json:"action"`
+	NewOwner    string `json:"newowner"`
+	MaxSupply   uint64 `json:"maxsupply"`
+	URI         string `json:"uri"`
+	Description string `json:"description"`
+}
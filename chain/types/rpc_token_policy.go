@@ -0,0 +1,7 @@
+package types
+
+type RpcTokenPolicyBody struct {
+	Address   string   `json:"address"`
+	Action    int      `json:"action"`
+	Addresses []string `json:"addresses"`
+}
@@ -1,8 +1,6 @@
 package types
 
 import (
-	"errors"
-	"fmt"
 	"github.com/aiot-network/aiotchain/common/config"
 	"github.com/aiot-network/aiotchain/tools/amount"
 	"github.com/aiot-network/aiotchain/tools/arry"
@@ -21,6 +19,41 @@ type TokenRecord struct {
 	PledgeRate     PledgeRate
 	PledgeAmount   uint64
 	Records        *RecordList
+	// Fields added after the token records are optional,
+	// so that the tokens already in the trie keep decoding
+	Owner     arry.Address    `rlp:"optional"`
+	MaxSupply uint64          `rlp:"optional"`
+	Policy    *TransferPolicy `rlp:"nil,optional"`
+	Meta      *TokenMeta      `rlp:"nil,optional"`
+}
+
+// Metadata of the token. Tokens issued before the metadata was
+// introduced have none, and their amounts have DefaultDecimals.
+type TokenMeta struct {
+	Decimals    uint8
+	URI         string
+	Description string
+}
+
+func (m *TokenMeta) GetDecimals() uint8 {
+	if m == nil {
+		return DefaultDecimals
+	}
+	return m.Decimals
+}
+
+func (m *TokenMeta) GetURI() string {
+	if m == nil {
+		return ""
+	}
+	return m.URI
+}
+
+func (m *TokenMeta) GetDescription() string {
+	if m == nil {
+		return ""
+	}
+	return m.Description
 }
 
 func NewToken() *TokenRecord {
@@ -49,87 +82,231 @@ func (t *TokenRecord) IsExist(msgHash arry.Hash) bool {
 	return false
 }
 
+// The owner of the token, records issued before ownership
+// was introduced are owned by the sender who created them
+func (t *TokenRecord) OwnerAddress() arry.Address {
+	if arry.EmptyAddress(t.Owner) {
+		return t.Sender
+	}
+	return t.Owner
+}
+
 func (t *TokenRecord) CheckToken(msg types.IMessage) error {
 	body := msg.MsgBody().(*TokenBody)
-	if !t.Sender.IsEqual(msg.From()) {
-		return errors.New("the token already exists")
+	if !t.OwnerAddress().IsEqual(msg.From()) {
+		return NewMsgError(ErrTokenConflict, nil, "the token already exists")
 	}
 	if !t.IncreaseIssues {
-		return errors.New("token does not allow increase issuance")
+		return NewMsgError(ErrPermissionDenied, nil, "token does not allow increase issuance")
 	}
 	if t.Shorthand != body.Shorthand {
-		return errors.New("token shorthand is not consistent")
+		return NewMsgError(ErrTokenConflict, nil, "token shorthand is not consistent")
 	}
 	if t.Name != body.Name {
-		return errors.New("token name is not consistent")
+		return NewMsgError(ErrTokenConflict, nil, "token name is not consistent")
+	}
+	if t.Meta.GetDecimals() != body.Meta.GetDecimals() {
+		return NewMsgError(ErrTokenConflict, nil, "token decimals is not consistent")
 	}
 	if !t.Address.IsEqual(body.TokenAddress) {
-		return errors.New("token address is not consistent")
+		return NewMsgError(ErrTokenConflict, nil, "token address is not consistent")
 	}
 	if t.IsExist(msg.Hash()) {
-		return errors.New("duplicate message hash")
+		return NewMsgError(ErrTokenConflict, nil, "duplicate message hash")
 	}
 	fAmount := amount.Amount(t.amount() + body.Amount).ToCoin()
 	if fAmount < 0 {
-		return fmt.Errorf("the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
+		return NewMsgError(ErrBadAmount, nil, "the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
 	}
 	if fAmount > config.Param.MaxCoinCount {
-		return fmt.Errorf("the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
+		return NewMsgError(ErrBadAmount, nil, "the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
 	}
-	return nil
+	return t.checkMaxSupply(body.Amount)
 }
 
 func (t *TokenRecord) CheckTokenV2(msg types.IMessage) error {
 	body := msg.MsgBody().(*TokenV2Body)
-	if !t.Sender.IsEqual(msg.From()) {
-		return errors.New("the token already exists")
+	if !t.OwnerAddress().IsEqual(msg.From()) {
+		return NewMsgError(ErrTokenConflict, nil, "the token already exists")
 	}
 	if !t.IncreaseIssues {
-		return errors.New("token does not allow increase issuance")
+		return NewMsgError(ErrPermissionDenied, nil, "token does not allow increase issuance")
 	}
 	if t.Shorthand != body.Shorthand {
-		return errors.New("token shorthand is not consistent")
+		return NewMsgError(ErrTokenConflict, nil, "token shorthand is not consistent")
 	}
 	if t.Name != body.Name {
-		return errors.New("token name is not consistent")
+		return NewMsgError(ErrTokenConflict, nil, "token name is not consistent")
+	}
+	if t.Meta.GetDecimals() != body.Meta.GetDecimals() {
+		return NewMsgError(ErrTokenConflict, nil, "token decimals is not consistent")
 	}
 	if !t.Address.IsEqual(body.TokenAddress) {
-		return errors.New("token address is not consistent")
+		return NewMsgError(ErrTokenConflict, nil, "token address is not consistent")
 	}
 	if t.IsExist(msg.Hash()) {
-		return errors.New("duplicate message hash")
+		return NewMsgError(ErrTokenConflict, nil, "duplicate message hash")
 	}
 	fAmount := amount.Amount(t.amount() + body.Amount).ToCoin()
 	if fAmount < 0 {
-		return fmt.Errorf("the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
+		return NewMsgError(ErrBadAmount, nil, "the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
 	}
 	if fAmount > config.Param.MaxCoinCount {
-		return fmt.Errorf("the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
+		return NewMsgError(ErrBadAmount, nil, "the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
 	}
-	return nil
+	return t.checkMaxSupply(body.Amount)
 }
 
 func (t *TokenRecord) CheckRedemption(msg types.IMessage) error {
 	body := msg.MsgBody().(*RedemptionBody)
 	if !t.Address.IsEqual(body.TokenAddress) {
-		return errors.New("token address is not consistent")
+		return NewMsgError(ErrTokenConflict, nil, "token address is not consistent")
 	}
 	if t.IsExist(msg.Hash()) {
-		return errors.New("duplicate message hash")
+		return NewMsgError(ErrTokenConflict, nil, "duplicate message hash")
 	}
 	fAmount := amount.Amount(t.amount() + body.Amount).ToCoin()
 	if fAmount < 0 {
-		return fmt.Errorf("the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
+		return NewMsgError(ErrBadAmount, nil, "the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
 	}
 	if fAmount > config.Param.MaxCoinCount {
-		return fmt.Errorf("the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
+		return NewMsgError(ErrBadAmount, nil, "the total number of coins must not exceed %.8f", config.Param.MaxCoinCount)
 	}
 	if body.PledgeRate != t.PledgeRate {
-		return fmt.Errorf("the redemption ratio of %d is not the same as the pledge ratio of %d", body.PledgeRate, t.PledgeRate)
+		return NewMsgError(ErrBadMessage, nil, "the redemption ratio of %d is not the same as the pledge ratio of %d", body.PledgeRate, t.PledgeRate)
+	}
+	return nil
+}
+
+func (t *TokenRecord) CheckTokenAdmin(msg types.IMessage) error {
+	body := msg.MsgBody().(*TokenAdminBody)
+	if !t.Address.IsEqual(body.TokenAddress) {
+		return NewMsgError(ErrTokenConflict, nil, "token address is not consistent")
+	}
+	if !t.OwnerAddress().IsEqual(msg.From()) {
+		return NewMsgError(ErrPermissionDenied, ErrDetails{"owner": t.OwnerAddress().String()}, "only the token owner can manage the token")
+	}
+	switch body.Action {
+	case TransferOwner:
+		if t.OwnerAddress().IsEqual(body.NewOwner) {
+			return NewMsgError(ErrBadMessage, nil, "the new owner is the same as the current owner")
+		}
+	case RenounceMint:
+		if !t.IncreaseIssues {
+			return NewMsgError(ErrPermissionDenied, nil, "token does not allow increase issuance")
+		}
+	case SetMaxSupply:
+		if t.MaxSupply != 0 && body.MaxSupply > t.MaxSupply {
+			return NewMsgError(ErrBadAmount, nil, "max supply can only be lowered, the current max supply is %v", amount.Amount(t.MaxSupply).ToDecimals(t.Meta.GetDecimals()))
+		}
+		if body.MaxSupply < t.amount() {
+			return NewMsgError(ErrBadAmount, nil, "max supply cannot be less than the issued amount %v", amount.Amount(t.amount()).ToDecimals(t.Meta.GetDecimals()))
+		}
+	case SetMetadata:
+		return nil
+	default:
+		return NewMsgError(ErrBadMessage, ErrDetails{"action": body.Action}, "there is no token admin action %d", body.Action)
+	}
+	return nil
+}
+
+// Change the token management information
+func (t *TokenRecord) UpdateAdmin(body *TokenAdminBody) {
+	switch body.Action {
+	case TransferOwner:
+		t.Owner = body.NewOwner
+	case RenounceMint:
+		t.IncreaseIssues = false
+	case SetMaxSupply:
+		t.MaxSupply = body.MaxSupply
+	case SetMetadata:
+		if t.Meta == nil {
+			t.Meta = &TokenMeta{Decimals: DefaultDecimals}
+		}
+		t.Meta.URI = body.URI
+		t.Meta.Description = body.Description
+	}
+}
+
+// Verify the policy message, the number of addresses
+// in a policy list cannot exceed MaxPolicyList
+func (t *TokenRecord) CheckTokenPolicy(msg types.IMessage, inList PolicyLookup) error {
+	body := msg.MsgBody().(*TokenPolicyBody)
+	if !t.Address.IsEqual(body.TokenAddress) {
+		return NewMsgError(ErrBadAddress, ErrDetails{"token": t.Address.String()}, "token address is not consistent")
+	}
+	if !t.OwnerAddress().IsEqual(msg.From()) {
+		return NewMsgError(ErrPermissionDenied, ErrDetails{"owner": t.OwnerAddress().String()}, "only the token owner can manage the token")
+	}
+	list, ok := body.List()
+	if !ok || (body.Action != FreezeAddress && body.Action != AllowAddress) {
+		return nil
+	}
+	count := t.Policy.Count(list)
+	for _, addr := range body.Addresses {
+		if !inList(list, addr) {
+			count++
+		}
+	}
+	if count > MaxPolicyList {
+		return NewMsgError(ErrPolicyLimit, ErrDetails{"token": t.Address.String(), "count": count, "maximum": MaxPolicyList},
+			"the maximum number of addresses in a policy list is %d", MaxPolicyList)
+	}
+	return nil
+}
+
+// Verify that the transfer of the token is allowed by its policy. The
+// policy is only set by TokenPolicy messages, which are valid from the
+// TokenPolicyHeight, so no transfer is restricted before the fork.
+func (t *TokenRecord) CheckTransfer(msg types.IMessage, inList PolicyLookup) error {
+	if t.Policy == nil {
+		return nil
+	}
+	if t.Policy.Paused {
+		return NewMsgError(ErrTokenRestricted, ErrDetails{"token": t.Address.String()}, "transfers of token %s are paused", t.Address.String())
+	}
+	owner := t.OwnerAddress()
+	addrs := []arry.Address{msg.From()}
+	for _, re := range msg.MsgBody().MsgTo().ReceiverList() {
+		addrs = append(addrs, re.Address)
+	}
+	for _, addr := range addrs {
+		if t.Policy.Frozen != 0 && inList(FrozenList, addr) {
+			return NewMsgError(ErrTokenRestricted, ErrDetails{"token": t.Address.String(), "address": addr.String()}, "address %s is frozen for token %s", addr.String(), t.Address.String())
+		}
+		if t.Policy.AllowlistOnly && !addr.IsEqual(owner) && !inList(AllowList, addr) {
+			return NewMsgError(ErrTokenRestricted, ErrDetails{"token": t.Address.String(), "address": addr.String()}, "address %s is not in the allowlist of token %s", addr.String(), t.Address.String())
+		}
 	}
 	return nil
 }
 
+// Change the token policy, changed is the number of
+// addresses added to or removed from the policy list
+func (t *TokenRecord) UpdatePolicy(body *TokenPolicyBody, changed uint64) {
+	if t.Policy == nil {
+		t.Policy = &TransferPolicy{}
+	}
+	switch body.Action {
+	case FreezeAddress:
+		t.Policy.Frozen += changed
+	case UnfreezeAddress:
+		t.Policy.Frozen -= changed
+	case AllowAddress:
+		t.Policy.Allowed += changed
+	case DisallowAddress:
+		t.Policy.Allowed -= changed
+	case EnableAllowlist:
+		t.Policy.AllowlistOnly = true
+	case DisableAllowlist:
+		t.Policy.AllowlistOnly = false
+	case PauseToken:
+		t.Policy.Paused = true
+	case UnpauseToken:
+		t.Policy.Paused = false
+	}
+}
+
 func (t *TokenRecord) IncreaseRecord(record *Record) {
 	t.Records.Set(record)
 }
@@ -143,6 +320,13 @@ func (t *TokenRecord) FallBack(height uint64) error {
 	return nil
 }
 
+func (t *TokenRecord) checkMaxSupply(increase uint64) error {
+	if t.MaxSupply != 0 && t.amount()+increase > t.MaxSupply {
+		return NewMsgError(ErrBadAmount, nil, "the total number of coins must not exceed the max supply %v", amount.Amount(t.MaxSupply).ToDecimals(t.Meta.GetDecimals()))
+	}
+	return nil
+}
+
 func (t *TokenRecord) amount() uint64 {
 	var sum uint64
 	for _, record := range *t.Records {
@@ -193,3 +377,39 @@ func (r *RecordList) Remove(height uint64) {
 func (r *RecordList) Len() int {
 	return len(*r)
 }
+
+// Transfer restrictions set by the token owner. The frozen and allowed
+// addresses are kept in the token trie, the policy records their numbers.
+type TransferPolicy struct {
+	Paused        bool
+	AllowlistOnly bool
+	Frozen        uint64
+	Allowed       uint64
+	// Addresses of the lists, they are only filled for display
+	FrozenAddrs  []arry.Address `rlp:"-"`
+	AllowedAddrs []arry.Address `rlp:"-"`
+}
+
+// The number of addresses in the policy list
+func (p *TransferPolicy) Count(list PolicyList) uint64 {
+	if p == nil {
+		return 0
+	}
+	switch list {
+	case FrozenList:
+		return p.Frozen
+	case AllowList:
+		return p.Allowed
+	}
+	return 0
+}
+
+type PolicyList byte
+
+const (
+	FrozenList PolicyList = iota + 1
+	AllowList
+)
+
+// Look up whether the address is in the policy list of the token
+type PolicyLookup func(list PolicyList, address arry.Address) bool
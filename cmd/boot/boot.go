@@ -7,6 +7,7 @@ import (
 	"github.com/aiot-network/aiotchain/chain/common/private"
 	"github.com/aiot-network/aiotchain/service/p2p"
 	"os"
+	"strings"
 	"sync"
 )
 
@@ -15,13 +16,15 @@ func main() {
 		port     = flag.String("port", "29564", "the port of start a bootstrap")
 		keyFile  = flag.String("k", "", "bootstrap node key file")
 		password = flag.String("p", "", "the decryption password for key file")
+		swarmKey = flag.String("swarmkey", "", "pre-shared key file, only the private network is served if it is set")
+		allow    = flag.String("allow", "", "comma separated peer ids allowed to connect in the private network")
 	)
 	flag.Parse()
 
-	StartBootStrap(*port, *keyFile, *password)
+	StartBootStrap(*port, *keyFile, *password, *swarmKey, *allow)
 }
 
-func StartBootStrap(port, keyFile, password string) {
+func StartBootStrap(port, keyFile, password, swarmKey, allow string) {
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 
@@ -45,7 +48,25 @@ func StartBootStrap(port, keyFile, password string) {
 		return
 	}
 
-	server, err := p2p.NewBoot(port, "0.0.0.0", pri.PrivateKey())
+	psk, err := p2p.ReadSwarmKey(swarmKey)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	var ids []string
+	if allow != "" {
+		ids = strings.Split(allow, ",")
+	}
+	allowlist, err := p2p.NewAllowlist(ids)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	server, err := p2p.NewBoot(port, "0.0.0.0", pri.PrivateKey(), psk, allowlist)
+	if err != nil {
+		fmt.Printf("create p2p server failed! %v\n", err)
+		return
+	}
 
 	if err := server.StartBoot(); err != nil {
 		fmt.Printf("start p2p server failed! %v\n", err)
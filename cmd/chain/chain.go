@@ -10,6 +10,7 @@ import (
 	"github.com/aiot-network/aiotchain/chain/common/status/act_status"
 	"github.com/aiot-network/aiotchain/chain/common/status/dpos_status"
 	"github.com/aiot-network/aiotchain/chain/common/status/token_status"
+	"github.com/aiot-network/aiotchain/chain/db/ban_db"
 	"github.com/aiot-network/aiotchain/chain/node"
 	"github.com/aiot-network/aiotchain/chain/request"
 	"github.com/aiot-network/aiotchain/chain/rpc"
@@ -37,6 +38,9 @@ var interruptSignals = []os.Signal{
 	syscall.SIGTERM,
 }
 
+// The directory of the banned peers in the data directory
+const banDBName = "ban_db"
+
 func main() {
 	// Initialize the goroutine count,  Use all processor cores.
 	runtime.GOMAXPROCS(runtime.NumCPU())
@@ -108,7 +112,11 @@ func createNode() (*node.Node, error) {
 	}
 
 	reqHandler := request.NewRequestHandler(chain)
-	peersSv := peers.NewPeers(reqHandler)
+	banDB, err := ban_db.Open(config.Param.Data + "/" + banDBName)
+	if err != nil {
+		return nil, err
+	}
+	peersSv := peers.NewPeers(reqHandler, banDB)
 
 	p2pSv, err := p2p.NewP2p(peersSv, reqHandler)
 	if err != nil {
@@ -116,14 +124,14 @@ func createNode() (*node.Node, error) {
 	}
 
 	horn := horn.NewHorn(peersSv, gPool, reqHandler)
-	msgManage, err := msglist.NewMsgManagement(status, actStatus)
+	msgManage, err := msglist.NewMsgManagement(status, actStatus, chain.LastHeight)
 	if err != nil {
 		return nil, err
 	}
 	poolSv := pool.NewPool(horn, msgManage)
 
 	rpcSv := rpc.NewRpc(status, poolSv, chain, peersSv)
-	syncSv := sync_service.NewSync(peersSv, dPosStatus, reqHandler, chain)
+	syncSv := sync_service.NewSync(peersSv, dPosStatus, dPos, reqHandler, chain)
 	generateSv := generate.NewGenerate(chain, dPos, poolSv, horn)
 	node := node.NewNode()
 
@@ -131,10 +139,21 @@ func createNode() (*node.Node, error) {
 	reqHandler.RegisterLocalInfo(node.LocalInfo)
 
 	chain.RegisterMsgPoolDeleteFunc(poolSv.Delete)
-
-	// Register peer nodes to send blocks and message processing
-	reqHandler.RegisterReceiveMessage(poolSv.ReceiveMsgFromPeer)
-	reqHandler.RegisterReceiveBlock(syncSv.ReceivedBlockFromPeer)
+	chain.RegisterMsgPoolRecoverFunc(poolSv.Recover)
+
+	// Register peer nodes to send blocks and message processing,
+	// the horn deduplicates and relays them
+	horn.RegisterReceiveMessage(poolSv.ReceiveMsgFromPeer)
+	horn.RegisterReceiveBlock(syncSv.ReceivedBlockFromPeer)
+	reqHandler.RegisterReceiveMessage(horn.ReceiveMsg)
+	reqHandler.RegisterReceiveBlock(horn.ReceiveBlock)
+	reqHandler.RegisterReceiveInventory(horn.ReceiveInventory)
+	reqHandler.RegisterGetMessage(poolSv.GetMessage)
+	reqHandler.RegisterReceiveCompactBlock(horn.ReceiveCompactBlock)
+	horn.RegisterPoolMessages(poolSv.Messages)
+	poolSv.RegisterPeerPenalty(peersSv.Penalize)
+	reqHandler.RegisterPeerPenalty(peersSv.Penalize)
+	peersSv.RegisterSuperIds(dPos.SuperIds)
 
 	node.Register(syncSv)
 	node.Register(peersSv)
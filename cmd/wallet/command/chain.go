@@ -8,10 +8,12 @@ import (
 	"github.com/aiot-network/aiotchain/chain/common/kit"
 	"github.com/aiot-network/aiotchain/chain/common/kit/message"
 	"github.com/aiot-network/aiotchain/chain/rpc"
+	rpctypes "github.com/aiot-network/aiotchain/chain/rpc/types"
 	"github.com/aiot-network/aiotchain/chain/types"
 	amount2 "github.com/aiot-network/aiotchain/tools/amount"
 	"github.com/aiot-network/aiotchain/tools/crypto/ecc/secp256k1"
 	"github.com/spf13/cobra"
+	"io/ioutil"
 	"strconv"
 	"strings"
 	"time"
@@ -24,10 +26,15 @@ func init() {
 		GetMessageCmd,
 		SendMessageCmd,
 		SendDerivedTransactionCmd,
+		SendBatchCmd,
+		EstimateFeeCmd,
+		SpeedUpCmd,
+		CancelPendingCmd,
 	}
 
 	RootCmd.AddCommand(blockCmds...)
 	RootSubCmdGroups["chain"] = blockCmds
+	addValidUntilFlag(SendMessageCmd, SendDerivedTransactionCmd, SendBatchCmd, SpeedUpCmd, CancelPendingCmd)
 }
 
 var LastHeightCmd = &cobra.Command{
@@ -130,17 +137,19 @@ func GetBlockByHash(cmd *cobra.Command, args []string) {
 }
 
 var SendMessageCmd = &cobra.Command{
-	Use:     "SendTransaction {from} {token} {to:amount|{to:amount}} {fees} {password} {nonce}; Send a transaction;",
+	Use:     "SendTransaction {from} {token} {to:amount|{to:amount}} {fees|auto} {password} {nonce}; Send a transaction;",
 	Aliases: []string{"sendtransaction", "ST", "st"},
-	Short:   "SendTransaction {from} {token} {to:amount|to:amount} {fees} {password} {nonce}; Send a transaction;",
+	Short:   "SendTransaction {from} {token} {to:amount|to:amount} {fees|auto} {password} {nonce}; Send a transaction;",
 	Example: `
 	SendTransaction xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ FC xCE9boXz2TxSE9srVPDdfszyiXtfT3vduc8:10|xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ:10 0.1
 		OR
 	SendTransaction xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ FC xCE9boXz2TxSE9srVPDdfszyiXtfT3vduc8:10|xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ:10 0.1 123456
 		OR
 	SendTransaction xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ FC xCE9boXz2TxSE9srVPDdfszyiXtfT3vduc8:10 123456 1
+		OR
+	SendTransaction xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ FC xCE9boXz2TxSE9srVPDdfszyiXtfT3vduc8:10 auto 123456
 	`,
-	Args: cobra.MinimumNArgs(5),
+	Args: cobra.MinimumNArgs(3),
 	Run:  SendTransaction,
 }
 
@@ -173,8 +182,9 @@ func SendTransaction(cmd *cobra.Command, args []string) {
 		outputError(cmd.Use, err)
 		return
 	}
-	if tx.Header.Nonce == 0 {
-		tx.Header.Nonce = account.Nonce + 1
+	if err := fillHeader(cmd, tx, account); err != nil {
+		outputError(cmd.Use, err)
+		return
 	}
 	if err := signMsg(tx, privKey.Private); err != nil {
 		outputError(cmd.Use, errors.New("signature failure"))
@@ -199,15 +209,8 @@ func parseTransaction(args []string) (*types.Message, error) {
 	from = args[0]
 	token = args[1]
 	tos = args[2]
-	if fFees, err := strconv.ParseFloat(args[3], 64); err != nil {
-		return nil, errors.New("[fees] wrong")
-	} else {
-		if fFees < 0 {
-			return nil, errors.New("[fees] wrong")
-		}
-		if fee, err = amount2.NewAmount(fFees); err != nil {
-			return nil, errors.New("[fees] wrong")
-		}
+	if fee, err = parseFee(args, 3); err != nil {
+		return nil, err
 	}
 	if len(args) > 5 {
 		nonce, err = strconv.ParseUint(args[5], 10, 64)
@@ -215,14 +218,18 @@ func parseTransaction(args []string) (*types.Message, error) {
 			return nil, errors.New("[nonce] wrong")
 		}
 	}
-	toList, err := parseReceiver(tos)
+	decimals, err := tokenDecimals(token)
+	if err != nil {
+		return nil, err
+	}
+	toList, err := parseReceiver(tos, decimals)
 	if err != nil {
 		return nil, err
 	}
 	return message.NewTransaction(from, token, toList, fee, nonce, uint64(time.Now().Unix())), nil
 }
 
-func parseReceiver(toStr string) ([]map[string]uint64, error) {
+func parseReceiver(toStr string, decimals uint8) ([]map[string]uint64, error) {
 	toList := []map[string]uint64{}
 	receivers := strings.Split(toStr, "|")
 	if len(receivers) == 0 {
@@ -237,7 +244,7 @@ func parseReceiver(toStr string) ([]map[string]uint64, error) {
 		if err != nil {
 			return nil, fmt.Errorf("wrong receiver %s", receiver)
 		}
-		if amt, err := amount2.NewAmount(fAmt); err != nil {
+		if amt, err := amount2.NewAmountDecimals(fAmt, decimals); err != nil {
 			return nil, fmt.Errorf("wrong receiver %s", receiver)
 		} else {
 			toList = append(toList, map[string]uint64{strs[0]: amt})
@@ -246,10 +253,91 @@ func parseReceiver(toStr string) ([]map[string]uint64, error) {
 	return toList, nil
 }
 
+var SendBatchCmd = &cobra.Command{
+	Use:     "SendBatch {from} {batch file} {fees|auto} {password} {nonce}; Send several operations in one message;",
+	Aliases: []string{"sendbatch", "SB", "sb"},
+	Short:   "SendBatch {from} {batch file} {fees|auto} {password} {nonce}; Send several operations in one message;",
+	Example: `
+	SendBatch xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ batch.json 0.1
+		OR
+	SendBatch xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ batch.json 0.1 123456
+		OR
+	SendBatch xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ batch.json 0.1 123456 1
+
+	batch.json:
+	[
+		{"type": "transaction", "token": "FC", "to": "xCE9boXz2TxSE9srVPDdfszyiXtfT3vduc8:10|xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ:10"},
+		{"type": "vote", "to": "xCE9boXz2TxSE9srVPDdfszyiXtfT3vduc8"},
+		{"type": "candidate", "peerid": "16Uiu2HAm..."},
+		{"type": "cancel"}
+	]
+	`,
+	Args: cobra.MinimumNArgs(2),
+	Run:  SendBatch,
+}
+
+// An operation of the batch file
+type batchOperation struct {
+	Type   string `json:"type"`
+	Token  string `json:"token"`
+	To     string `json:"to"`
+	PeerId string `json:"peerid"`
+}
+
+func SendBatch(cmd *cobra.Command, args []string) {
+	fee, nonce, err := parseFeesAndNonce(args, 2, 4)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	msgs, err := parseBatchFile(args[0], args[1])
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	batchMsg := message.NewBatch(args[0], msgs, fee, nonce, uint64(time.Now().Unix()))
+	signAndSend(cmd, batchMsg, args, 3)
+}
+
+func parseBatchFile(from, path string) ([]*types.Message, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read batch file failed! %s", err.Error())
+	}
+	var operations []*batchOperation
+	if err := json.Unmarshal(bytes, &operations); err != nil {
+		return nil, fmt.Errorf("wrong batch file! %s", err.Error())
+	}
+	msgs := make([]*types.Message, 0, len(operations))
+	for i, op := range operations {
+		switch strings.ToLower(op.Type) {
+		case "transaction":
+			decimals, err := tokenDecimals(op.Token)
+			if err != nil {
+				return nil, err
+			}
+			toList, err := parseReceiver(op.To, decimals)
+			if err != nil {
+				return nil, fmt.Errorf("operation %d: %s", i, err.Error())
+			}
+			msgs = append(msgs, message.NewTransaction(from, op.Token, toList, 0, 0, 0))
+		case "vote":
+			msgs = append(msgs, message.NewVote(from, op.To, 0, 0, 0))
+		case "candidate":
+			msgs = append(msgs, message.NewCandidate(from, op.PeerId, 0, 0, 0))
+		case "cancel":
+			msgs = append(msgs, message.NewCancel(from, 0, 0, 0))
+		default:
+			return nil, fmt.Errorf("operation %d: unknown type %s", i, op.Type)
+		}
+	}
+	return msgs, nil
+}
+
 var SendDerivedTransactionCmd = &cobra.Command{
-	Use:     "SendDerivedTransaction {from} {index} {token} {to:amount|{to:amount}} {fees} {password} {nonce}; Send a transaction;",
+	Use:     "SendDerivedTransaction {from} {index} {token} {to:amount|{to:amount}} {fees|auto} {password} {nonce}; Send a transaction;",
 	Aliases: []string{"sendderivedtransaction", "SDT", "sdt"},
-	Short:   "SendDerivedTransaction {from} {index} {token} {to:amount|to:amount} {fees} {password} {nonce}; Send a transaction;",
+	Short:   "SendDerivedTransaction {from} {index} {token} {to:amount|to:amount} {fees|auto} {password} {nonce}; Send a transaction;",
 	Example: `
 	SendDerivedTransaction xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ 1 FC xCE9boXz2TxSE9srVPDdfszyiXtfT3vduc8:10|xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ:10 0.1
 		OR
@@ -292,8 +380,9 @@ func SendDerivedTransaction(cmd *cobra.Command, args []string) {
 		outputError(cmd.Use, err)
 		return
 	}
-	if tx.Header.Nonce == 0 {
-		tx.Header.Nonce = account.Nonce + 1
+	if err := fillHeader(cmd, tx, account); err != nil {
+		outputError(cmd.Use, err)
+		return
 	}
 	if err := signMsg(tx, privStr); err != nil {
 		outputError(cmd.Use, errors.New("signature failure"))
@@ -341,15 +430,8 @@ func parseHDTransaction(args []string, entropy string) (*types.Message, string,
 	}
 	token = args[2]
 	tos = args[3]
-	if fFees, err := strconv.ParseFloat(args[4], 64); err != nil {
-		return nil, "", errors.New("[fees] wrong")
-	} else {
-		if fFees < 0 {
-			return nil, "", errors.New("[fees] wrong")
-		}
-		if fee, err = amount2.NewAmount(fFees); err != nil {
-			return nil, "", errors.New("[fees] wrong")
-		}
+	if fee, err = parseFee(args, 4); err != nil {
+		return nil, "", err
 	}
 	if len(args) > 6 {
 		nonce, err = strconv.ParseUint(args[6], 10, 64)
@@ -357,7 +439,11 @@ func parseHDTransaction(args []string, entropy string) (*types.Message, string,
 			return nil, "", errors.New("[nonce] wrong")
 		}
 	}
-	toList, err := parseReceiver(tos)
+	decimals, err := tokenDecimals(token)
+	if err != nil {
+		return nil, "", err
+	}
+	toList, err := parseReceiver(tos, decimals)
 	if err != nil {
 		return nil, "", err
 	}
@@ -403,6 +489,75 @@ func sendMsg(msg *types.Message) (*rpc.Response, error) {
 
 }
 
+var EstimateFeeCmd = &cobra.Command{
+	Use:     "EstimateFee {type} {receivers}; Estimate the fee of a message;",
+	Aliases: []string{"estimatefee", "ef", "EF"},
+	Short:   "EstimateFee {type} {receivers}; Estimate the fee of a message;",
+	Example: `
+	EstimateFee
+		OR
+	EstimateFee 0 2
+	`,
+	Args: cobra.MinimumNArgs(0),
+	Run:  EstimateFee,
+}
+
+func EstimateFee(cmd *cobra.Command, args []string) {
+	var msgType, receivers uint64 = 0, 1
+	var err error
+	if len(args) > 0 {
+		if msgType, err = strconv.ParseUint(args[0], 10, 8); err != nil {
+			outputError(cmd.Use, errors.New("[type] wrong"))
+			return
+		}
+	}
+	if len(args) > 1 {
+		if receivers, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+			outputError(cmd.Use, errors.New("[receivers] wrong"))
+			return
+		}
+	}
+	resp, err := estimateFeeRpc(types.MessageType(msgType), int(receivers))
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if resp.Code == 0 {
+		output(string(resp.Result))
+		return
+	}
+	outputRespError(cmd.Use, resp)
+}
+
+func estimateFeeRpc(msgType types.MessageType, receivers int) (*rpc.Response, error) {
+	client, err := NewRpcClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second*20)
+	defer cancel()
+
+	return client.Gc.EstimateFee(ctx, &rpc.EstimateFeeReq{Type: uint32(msgType), Receivers: uint64(receivers)})
+}
+
+// Get the recommended fee of the message from the node
+func estimateFee(msgType types.MessageType, receivers int) (uint64, error) {
+	resp, err := estimateFeeRpc(msgType, receivers)
+	if err != nil {
+		return 0, err
+	}
+	if resp.Code != 0 {
+		return 0, fmt.Errorf("err code :%d, message :%s", resp.Code, resp.Err)
+	}
+	var estimate *rpctypes.FeeEstimate
+	if err := json.Unmarshal(resp.Result, &estimate); err != nil {
+		return 0, err
+	}
+	return estimate.Recommended, nil
+}
+
 var GetMessageCmd = &cobra.Command{
 	Use:     "GetMessage {msghash}; Get Message by hash;",
 	Aliases: []string{"getmessage", "GM", "gm"},
@@ -440,3 +595,98 @@ func GetMessageRpc(hashStr string) (*rpc.Response, error) {
 	resp, err := client.Gc.GetMessage(ctx, &rpc.HashReq{Hash: hashStr})
 	return resp, err
 }
+
+var SpeedUpCmd = &cobra.Command{
+	Use:     "SpeedUp {msghash} {fees|auto} {password}; Resend a pending message with a higher fee;",
+	Aliases: []string{"speedup", "SU", "su"},
+	Short:   "SpeedUp {msghash} {fees|auto} {password}; Resend a pending message with a higher fee;",
+	Example: `
+	SpeedUp 0xef7b92e552dca02c97c9d596d1bf69d0044d95dec4cee0e6a20153e62bce893b
+		OR
+	SpeedUp 0xef7b92e552dca02c97c9d596d1bf69d0044d95dec4cee0e6a20153e62bce893b 0.2
+		OR
+	SpeedUp 0xef7b92e552dca02c97c9d596d1bf69d0044d95dec4cee0e6a20153e62bce893b auto 123456
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  SpeedUp,
+}
+
+func SpeedUp(cmd *cobra.Command, args []string) {
+	poolMsg, err := getPoolMessage(args[0])
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	msg, err := types.RpcMsgToMsg(poolMsg.Message)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	fee, err := parseReplaceFee(args, 1, poolMsg.ReplaceFee)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	msg.Header.Fee = fee
+	msg.Header.Time = uint64(time.Now().Unix())
+	signAndSend(cmd, msg, args, 2)
+}
+
+var CancelPendingCmd = &cobra.Command{
+	Use:     "CancelPending {from} {nonce} {fees|auto} {password}; Cancel the pending message of the nonce;",
+	Aliases: []string{"cancelpending", "CP", "cp"},
+	Short:   "CancelPending {from} {nonce} {fees|auto} {password}; Cancel the pending message of the nonce;",
+	Example: `
+	CancelPending xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ 5
+		OR
+	CancelPending xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ 5 0.2
+		OR
+	CancelPending xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ 5 auto 123456
+	`,
+	Args: cobra.MinimumNArgs(2),
+	Run:  CancelPending,
+}
+
+func CancelPending(cmd *cobra.Command, args []string) {
+	nonce, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		outputError(cmd.Use, errors.New("[nonce] wrong"))
+		return
+	}
+	pool, err := getPoolByAddress(args[0])
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	poolMsg, ok := pool.Find(nonce)
+	if !ok {
+		outputError(cmd.Use, fmt.Errorf("no pending message of nonce %d", nonce))
+		return
+	}
+	fee, err := parseReplaceFee(args, 2, poolMsg.ReplaceFee)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	msg := message.NewCancelPending(Net, args[0], fee, nonce, uint64(time.Now().Unix()))
+	signAndSend(cmd, msg, args, 3)
+}
+
+// The fee of the replacing message, the minimum replace fee is used if it is omitted
+func parseReplaceFee(args []string, feesIndex int, minFee uint64) (uint64, error) {
+	if len(args) <= feesIndex || args[feesIndex] == "auto" {
+		return minFee, nil
+	}
+	fFees, err := strconv.ParseFloat(args[feesIndex], 64)
+	if err != nil || fFees < 0 {
+		return 0, errors.New("[fees] wrong")
+	}
+	fee, err := amount2.NewAmount(fFees)
+	if err != nil {
+		return 0, errors.New("[fees] wrong")
+	}
+	if fee < minFee {
+		return 0, fmt.Errorf("[fees] at least %v is required to replace the message", amount2.Amount(minFee).ToCoin())
+	}
+	return fee, nil
+}
@@ -44,6 +44,9 @@ func output(dataStr string) {
 
 func outputRespError(cmdUser string, resp *rpc.Response) {
 	fmt.Printf("ERR: "+cmdUser+" err code :%d, message :%s\n", resp.Code, resp.Err)
+	if resp.Subcode != 0 {
+		fmt.Printf("subcode :%d, details :%s\n", resp.Subcode, string(resp.Details))
+	}
 }
 
 func outputError(cmdUser string, err error) {
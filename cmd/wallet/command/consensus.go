@@ -9,7 +9,6 @@ import (
 	"github.com/aiot-network/aiotchain/chain/rpc"
 	"github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/service/p2p"
-	"github.com/aiot-network/aiotchain/tools/amount"
 	"github.com/aiot-network/aiotchain/tools/crypto/ecc/secp256k1"
 	"github.com/spf13/cobra"
 	"strconv"
@@ -28,7 +27,7 @@ func init() {
 	}
 	RootCmd.AddCommand(txCmds...)
 	RootSubCmdGroups["consensus"] = txCmds
-
+	addValidUntilFlag(SenWorkCmd, SendCandidateCmd, SendCancelCmd, SendVoteCmd)
 }
 
 var SenWorkCmd = &cobra.Command{
@@ -80,8 +79,9 @@ func SendWork(cmd *cobra.Command, args []string) {
 		outputError(cmd.Use, err)
 		return
 	}
-	if workMsg.Header.Nonce == 0 {
-		workMsg.Header.Nonce = account.Nonce + 1
+	if err := fillHeader(cmd, workMsg, account); err != nil {
+		outputError(cmd.Use, err)
+		return
 	}
 	if err := signMsg(workMsg, privKey.String()); err != nil {
 		outputError(cmd.Use, errors.New("signature failure"))
@@ -128,9 +128,9 @@ func parseWork(args []string) (*types.Message, error) {
 }
 
 var SendCandidateCmd = &cobra.Command{
-	Use:     "SendCandidate {address} {fees} {password} {nonce}; Become candidate;",
+	Use:     "SendCandidate {address} {fees|auto} {password} {nonce}; Become candidate;",
 	Aliases: []string{"sendcandidate", "SC", "sc"},
-	Short:   "SendCandidate {address} {fees} {password} {nonce}; Become candidate;",
+	Short:   "SendCandidate {address} {fees|auto} {password} {nonce}; Become candidate;",
 	Example: `
 	SendCandidate xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ 0.001
 		OR
@@ -138,7 +138,7 @@ var SendCandidateCmd = &cobra.Command{
 		OR
 	SendCandidate xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ 0.001 123456 1
 `,
-	Args: cobra.MinimumNArgs(2),
+	Args: cobra.MinimumNArgs(1),
 	Run:  SendCandidate,
 }
 
@@ -177,8 +177,9 @@ func SendCandidate(cmd *cobra.Command, args []string) {
 		outputError(cmd.Use, err)
 		return
 	}
-	if candidateMsg.Header.Nonce == 0 {
-		candidateMsg.Header.Nonce = account.Nonce + 1
+	if err := fillHeader(cmd, candidateMsg, account); err != nil {
+		outputError(cmd.Use, err)
+		return
 	}
 	if err := signMsg(candidateMsg, privKey.String()); err != nil {
 		outputError(cmd.Use, errors.New("signature failure"))
@@ -202,15 +203,8 @@ func parseCandidate(cmd *cobra.Command, args []string, p2pid string) (*types.Mes
 	var fee, nonce uint64
 	from = args[0]
 
-	if fFees, err := strconv.ParseFloat(args[1], 64); err != nil {
-		return nil, errors.New("[fees] wrong")
-	} else {
-		if fFees < 0 {
-			return nil, errors.New("[fees] wrong")
-		}
-		if fee, err = amount.NewAmount(fFees); err != nil {
-			return nil, errors.New("[fees] wrong")
-		}
+	if fee, err = parseFee(args, 1); err != nil {
+		return nil, err
 	}
 	if len(args) > 3 {
 		nonce, err = strconv.ParseUint(args[3], 10, 64)
@@ -223,9 +217,9 @@ func parseCandidate(cmd *cobra.Command, args []string, p2pid string) (*types.Mes
 }
 
 var SendCancelCmd = &cobra.Command{
-	Use:     "SendCancel {address} {fees} {password} {nonce}; Cancel candidate;",
+	Use:     "SendCancel {address} {fees|auto} {password} {nonce}; Cancel candidate;",
 	Aliases: []string{"sendcancel", "SCL", "scl"},
-	Short:   "SendCancel {address} {fees} {password} {nonce}; Cancel candidate;",
+	Short:   "SendCancel {address} {fees|auto} {password} {nonce}; Cancel candidate;",
 	Example: `
 	SendCancel xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ 0.001
 		OR
@@ -233,7 +227,7 @@ var SendCancelCmd = &cobra.Command{
 		OR
 	SendCancel xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ 0.001 123456 1
 	`,
-	Args: cobra.MinimumNArgs(2),
+	Args: cobra.MinimumNArgs(1),
 	Run:  CancelCandidate,
 }
 
@@ -266,8 +260,9 @@ func CancelCandidate(cmd *cobra.Command, args []string) {
 		outputError(cmd.Use, err)
 		return
 	}
-	if cancel.Header.Nonce == 0 {
-		cancel.Header.Nonce = account.Nonce + 1
+	if err := fillHeader(cmd, cancel, account); err != nil {
+		outputError(cmd.Use, err)
+		return
 	}
 	if err := signMsg(cancel, privKey.Private); err != nil {
 		outputError(cmd.Use, errors.New("signature failure"))
@@ -290,15 +285,8 @@ func parseCancel(args []string) (*types.Message, error) {
 	var from string
 	var fee, nonce uint64
 	from = args[0]
-	if fFees, err := strconv.ParseFloat(args[1], 64); err != nil {
-		return nil, errors.New("[fees] wrong")
-	} else {
-		if fFees < 0 {
-			return nil, errors.New("[fees] wrong")
-		}
-		if fee, err = amount.NewAmount(fFees); err != nil {
-			return nil, errors.New("[fees] wrong")
-		}
+	if fee, err = parseFee(args, 1); err != nil {
+		return nil, err
 	}
 	if len(args) > 3 {
 		nonce, err = strconv.ParseUint(args[3], 10, 64)
@@ -310,9 +298,9 @@ func parseCancel(args []string) (*types.Message, error) {
 }
 
 var SendVoteCmd = &cobra.Command{
-	Use:     "SendVote {from} {to} {fees} {password} {nonce}；Vote for a candidate;",
+	Use:     "SendVote {from} {to} {fees|auto} {password} {nonce}；Vote for a candidate;",
 	Aliases: []string{"sendvote", "SV", "sv"},
-	Short:   "SendVote {from} {to} {fees} {password} {nonce}; Vote for a candidate;",
+	Short:   "SendVote {from} {to} {fees|auto} {password} {nonce}; Vote for a candidate;",
 	Example: `
 	SendVote xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ xCE9boXz2TxSE9srVPDdfszyiXtfT3vduc8 0.001
 		OR
@@ -320,7 +308,7 @@ var SendVoteCmd = &cobra.Command{
 		OR
 	SendVote xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ xCE9boXz2TxSE9srVPDdfszyiXtfT3vduc8 0.001 123456 1
 `,
-	Args: cobra.MinimumNArgs(3),
+	Args: cobra.MinimumNArgs(2),
 	Run:  Vote,
 }
 
@@ -354,8 +342,9 @@ func Vote(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	if vote.Header.Nonce == 0 {
-		vote.Header.Nonce = account.Nonce + 1
+	if err := fillHeader(cmd, vote, account); err != nil {
+		outputError(cmd.Use, err)
+		return
 	}
 	if err := signMsg(vote, privKey.Private); err != nil {
 		outputError(cmd.Use, errors.New("signature failure"))
@@ -378,15 +367,8 @@ func parseVote(args []string) (*types.Message, error) {
 	var fee, nonce uint64
 	from = args[0]
 	to = args[1]
-	if fFees, err := strconv.ParseFloat(args[2], 64); err != nil {
-		return nil, errors.New("[fees] wrong")
-	} else {
-		if fFees < 0 {
-			return nil, errors.New("[fees] wrong")
-		}
-		if fee, err = amount.NewAmount(fFees); err != nil {
-			return nil, errors.New("[fees] wrong")
-		}
+	if fee, err = parseFee(args, 2); err != nil {
+		return nil, err
 	}
 	if len(args) > 4 {
 		nonce, err = strconv.ParseUint(args[4], 10, 64)
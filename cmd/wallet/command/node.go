@@ -2,16 +2,26 @@ package command
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/aiot-network/aiotchain/chain/rpc"
+	rpctypes "github.com/aiot-network/aiotchain/chain/rpc/types"
 	"github.com/spf13/cobra"
+	"strconv"
 	"time"
 )
 
 func init() {
 	nodeCmds := []*cobra.Command{
 		MsgPoolCmd,
+		GetPoolMessageCmd,
+		GetPoolByAddressCmd,
 		LocalInfoCmd,
 		PeerInfoCmd,
+		ListBansCmd,
+		BanPeerCmd,
+		UnbanPeerCmd,
 	}
 	RootCmd.AddCommand(nodeCmds...)
 	RootSubCmdGroups["node"] = nodeCmds
@@ -53,6 +63,110 @@ func MsgPool(cmd *cobra.Command, args []string) {
 	outputRespError(cmd.Use, resp)
 }
 
+var GetPoolMessageCmd = &cobra.Command{
+	Use:     "GetPoolMessage {msghash}; Get a message in the message pool and the fee to replace it;",
+	Short:   "GetPoolMessage {msghash}; Get a message in the message pool and the fee to replace it;",
+	Aliases: []string{"getpoolmessage", "GPM", "gpm"},
+	Example: `
+	GetPoolMessage 0xef7b92e552dca02c97c9d596d1bf69d0044d95dec4cee0e6a20153e62bce893b
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  GetPoolMessage,
+}
+
+func GetPoolMessage(cmd *cobra.Command, args []string) {
+	resp, err := getPoolMessageRpc(args[0])
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if resp.Code == 0 {
+		output(string(resp.Result))
+		return
+	}
+	outputRespError(cmd.Use, resp)
+}
+
+func getPoolMessageRpc(hash string) (*rpc.Response, error) {
+	client, err := NewRpcClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second*20)
+	defer cancel()
+
+	return client.Gc.GetPoolMessage(ctx, &rpc.HashReq{Hash: hash})
+}
+
+func getPoolMessage(hash string) (*rpctypes.PoolMessage, error) {
+	resp, err := getPoolMessageRpc(hash)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code != 0 {
+		return nil, fmt.Errorf("err code :%d, message :%s", resp.Code, resp.Err)
+	}
+	var poolMsg *rpctypes.PoolMessage
+	if err := json.Unmarshal(resp.Result, &poolMsg); err != nil {
+		return nil, err
+	}
+	return poolMsg, nil
+}
+
+var GetPoolByAddressCmd = &cobra.Command{
+	Use:     "GetPoolByAddress {address}; Get the messages of the address in the message pool;",
+	Short:   "GetPoolByAddress {address}; Get the messages of the address in the message pool;",
+	Aliases: []string{"getpoolbyaddress", "GPA", "gpa"},
+	Example: `
+	GetPoolByAddress xCHiGPLCzgnrdTqjKABXZteAGVJu3jXLjnQ
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  GetPoolByAddress,
+}
+
+func GetPoolByAddress(cmd *cobra.Command, args []string) {
+	resp, err := getPoolByAddressRpc(args[0])
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if resp.Code == 0 {
+		output(string(resp.Result))
+		return
+	}
+	outputRespError(cmd.Use, resp)
+}
+
+func getPoolByAddressRpc(address string) (*rpc.Response, error) {
+	client, err := NewRpcClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second*20)
+	defer cancel()
+
+	return client.Gc.GetPoolByAddress(ctx, &rpc.AddressReq{Address: address})
+}
+
+func getPoolByAddress(address string) (*rpctypes.AddressPool, error) {
+	resp, err := getPoolByAddressRpc(address)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code != 0 {
+		return nil, fmt.Errorf("err code :%d, message :%s", resp.Code, resp.Err)
+	}
+	var pool *rpctypes.AddressPool
+	if err := json.Unmarshal(resp.Result, &pool); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
 var PeerInfoCmd = &cobra.Command{
 	Use:     "PeerInfo",
 	Short:   "PeerInfo; Get peer info;",
@@ -118,3 +232,116 @@ func LocalInfo(cmd *cobra.Command, args []string) {
 	}
 	outputRespError(cmd.Use, resp)
 }
+
+var ListBansCmd = &cobra.Command{
+	Use:     "ListBans",
+	Short:   "ListBans; Get the banned peers;",
+	Aliases: []string{"listbans", "LB", "lb"},
+	Example: `
+	ListBans
+	`,
+	Args: cobra.MinimumNArgs(0),
+	Run:  ListBans,
+}
+
+func ListBans(cmd *cobra.Command, args []string) {
+	client, err := NewRpcClient()
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second*20)
+	defer cancel()
+	resp, err := client.Gc.ListBans(ctx, &rpc.NullReq{})
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if resp.Code == 0 {
+		output(string(resp.Result))
+		return
+	}
+	outputRespError(cmd.Use, resp)
+}
+
+var BanPeerCmd = &cobra.Command{
+	Use:     "BanPeer {peer} {seconds} {reason}; Ban a peer, the ban is permanent if the seconds are 0 or omitted;",
+	Short:   "BanPeer {peer} {seconds} {reason}; Ban a peer, the ban is permanent if the seconds are 0 or omitted;",
+	Aliases: []string{"banpeer", "BP", "bp"},
+	Example: `
+	BanPeer 16Uiu2HAmJqwmN4Hzz5NqzrmJqjHpH2jRvMYhoTTzxVjfYbsXYS5f
+		OR
+	BanPeer 16Uiu2HAmJqwmN4Hzz5NqzrmJqjHpH2jRvMYhoTTzxVjfYbsXYS5f 3600 "invalid blocks"
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  BanPeer,
+}
+
+func BanPeer(cmd *cobra.Command, args []string) {
+	req := &rpc.BanReq{Peer: args[0]}
+	if len(args) > 1 {
+		seconds, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			outputError(cmd.Use, errors.New("[seconds] wrong"))
+			return
+		}
+		req.Seconds = seconds
+	}
+	if len(args) > 2 {
+		req.Reason = args[2]
+	}
+	client, err := NewRpcClient()
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second*20)
+	defer cancel()
+	resp, err := client.Gc.BanPeer(ctx, req)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if resp.Code == 0 {
+		output(string(resp.Result))
+		return
+	}
+	outputRespError(cmd.Use, resp)
+}
+
+var UnbanPeerCmd = &cobra.Command{
+	Use:     "UnbanPeer {peer}; Unban a peer;",
+	Short:   "UnbanPeer {peer}; Unban a peer;",
+	Aliases: []string{"unbanpeer", "UBP", "ubp"},
+	Example: `
+	UnbanPeer 16Uiu2HAmJqwmN4Hzz5NqzrmJqjHpH2jRvMYhoTTzxVjfYbsXYS5f
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  UnbanPeer,
+}
+
+func UnbanPeer(cmd *cobra.Command, args []string) {
+	client, err := NewRpcClient()
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second*20)
+	defer cancel()
+	resp, err := client.Gc.UnbanPeer(ctx, &rpc.PeerReq{Peer: args[0]})
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if resp.Code == 0 {
+		fmt.Println(string(resp.Result))
+		return
+	}
+	outputRespError(cmd.Use, resp)
+}
@@ -2,41 +2,61 @@ package command
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/aiot-network/aiotchain/chain/common/kit"
 	"github.com/aiot-network/aiotchain/chain/common/kit/message"
 	"github.com/aiot-network/aiotchain/chain/rpc"
+	rpctypes "github.com/aiot-network/aiotchain/chain/rpc/types"
 	"github.com/aiot-network/aiotchain/chain/types"
 	amount2 "github.com/aiot-network/aiotchain/tools/amount"
 	"github.com/spf13/cobra"
 	"strconv"
+	"strings"
 	"time"
 )
 
 func init() {
 	contractCmds := []*cobra.Command{
 		TokenCmd,
+		ListTokensCmd,
+		TokenHoldersCmd,
+		RichListCmd,
 		SendCreateTokenCmd,
 		SendRedemptionCmd,
+		SendTokenOwnerCmd,
+		SendRenounceMintCmd,
+		SendMaxSupplyCmd,
+		SendTokenAddressPolicyCmd,
+		SendTokenPolicyCmd,
+		SendTokenMetadataCmd,
 	}
 	RootCmd.AddCommand(contractCmds...)
 	RootSubCmdGroups["token"] = contractCmds
 
+	SendCreateTokenCmd.Flags().Uint8("decimals", types.MaxDecimals, "Decimals of token")
+	SendCreateTokenCmd.Flags().String("uri", "", "URI of token metadata")
+	SendCreateTokenCmd.Flags().String("description", "", "Description of token")
+	addValidUntilFlag(SendCreateTokenCmd, SendRedemptionCmd, SendTokenOwnerCmd, SendRenounceMintCmd, SendMaxSupplyCmd,
+		SendTokenAddressPolicyCmd, SendTokenPolicyCmd, SendTokenMetadataCmd)
+
 }
 
 var SendCreateTokenCmd = &cobra.Command{
-	Use:     "SendCreateToken {from} {to} {name} {shorthand} {pledge rate:100|1000|10000} {amount} {fees} {password} {nonce}; Send and create token;",
+	Use:     "SendCreateToken {from} {to} {name} {shorthand} {pledge rate:100|1000|10000} {amount} {fees|auto} {password} {nonce}; Send and create token;",
 	Aliases: []string{"SendCreateToken", "sendcreatetoken", "sct", "SCT"},
-	Short:   "SendCreateToken {from} {to} {name} {shorthand} {pledge rate:100|1000|10000} {amount} {fees} {password} {nonce}; Send and create token;",
+	Short:   "SendCreateToken {from} {to} {name} {shorthand} {pledge rate:100|1000|10000} {amount} {fees|auto} {password} {nonce}; Send and create token;",
 	Example: `
 	SendCreateToken 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ 3ajNkh7yVYkETL9JKvGx3aL2YVNrqksjCUUE "M token" MT 100 1000 0.1
 		OR
 	SendCreateToken 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ 3ajNkh7yVYkETL9JKvGx3aL2YVNrqksjCUUE "M token" MT 100 1000 0.1 123456
 		OR
 	SendCreateToken 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ 3ajNkh7yVYkETL9JKvGx3aL2YVNrqksjCUUE "M token" MT 100 1000 0.1 123456 0
+		OR
+	SendCreateToken 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ 3ajNkh7yVYkETL9JKvGx3aL2YVNrqksjCUUE "M token" MT 100 1000 0.1 --decimals 2 --uri https://example.com/mt.json --description "M token"
 	`,
-	Args: cobra.MinimumNArgs(7),
+	Args: cobra.MinimumNArgs(6),
 	Run:  SendCreateToken,
 }
 
@@ -59,7 +79,10 @@ func SendCreateToken(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	tokenMsg, err := parseToken(args)
+	decimals, _ := cmd.Flags().GetUint8("decimals")
+	uri, _ := cmd.Flags().GetString("uri")
+	description, _ := cmd.Flags().GetString("description")
+	tokenMsg, err := parseToken(args, decimals, uri, description)
 	if err != nil {
 		outputError(cmd.Use, err)
 		return
@@ -69,8 +92,9 @@ func SendCreateToken(cmd *cobra.Command, args []string) {
 		outputError(cmd.Use, err)
 		return
 	}
-	if tokenMsg.Header.Nonce == 0 {
-		tokenMsg.Header.Nonce = account.Nonce + 1
+	if err := fillHeader(cmd, tokenMsg, account); err != nil {
+		outputError(cmd.Use, err)
+		return
 	}
 	if err := signMsg(tokenMsg, privKey.Private); err != nil {
 		outputError(cmd.Use, errors.New("signature failure"))
@@ -87,7 +111,7 @@ func SendCreateToken(cmd *cobra.Command, args []string) {
 	}
 }
 
-func parseToken(args []string) (*types.Message, error) {
+func parseToken(args []string, decimals uint8, uri, description string) (*types.Message, error) {
 	var err error
 	var from, to, tokenAddr string
 	var amount, fee, nonce uint64
@@ -115,7 +139,7 @@ func parseToken(args []string) (*types.Message, error) {
 		if fAmount < 0 {
 			return nil, errors.New("[amount] wrong")
 		}
-		if amount, err = amount2.NewAmount(fAmount); err != nil {
+		if amount, err = amount2.NewAmountDecimals(fAmount, decimals); err != nil {
 			return nil, errors.New("[amount] wrong")
 		}
 	}
@@ -126,15 +150,8 @@ func parseToken(args []string) (*types.Message, error) {
 	fmt.Println(kit.CheckTokenAddress(Net, tokenAddr))
 	fmt.Println("token address is ", tokenAddr)
 
-	if fFees, err := strconv.ParseFloat(args[6], 64); err != nil {
-		return nil, errors.New("[fees] wrong")
-	} else {
-		if fFees < 0 {
-			return nil, errors.New("[fees] wrong")
-		}
-		if fee, err = amount2.NewAmount(fFees); err != nil {
-			return nil, errors.New("[fees] wrong")
-		}
+	if fee, err = parseFee(args, 6); err != nil {
+		return nil, err
 	}
 	if len(args) > 8 {
 		nonce, err = strconv.ParseUint(args[8], 10, 64)
@@ -142,14 +159,14 @@ func parseToken(args []string) (*types.Message, error) {
 			return nil, errors.New("[nonce] wrong")
 		}
 	}
-	tokenMsg := message.NewTokenV2(from, to, tokenAddr, amount, fee, nonce, uint64(time.Now().Unix()), pledge, name, shorthand)
+	tokenMsg := message.NewTokenV2(from, to, tokenAddr, amount, fee, nonce, uint64(time.Now().Unix()), pledge, name, shorthand, decimals, uri, description)
 	return tokenMsg, nil
 }
 
 var SendRedemptionCmd = &cobra.Command{
-	Use:     "SendRedemption {from} {token} {pledge rate:100|1000|10000} {amount} {fees} {password} {nonce}; Send redemption of token;",
+	Use:     "SendRedemption {from} {token} {pledge rate:100|1000|10000} {amount} {fees|auto} {password} {nonce}; Send redemption of token;",
 	Aliases: []string{"SendRedemption", "sendredemption", "sr", "SR"},
-	Short:   "SendRedemption {from} {token} {pledge rate:100|1000|10000} {amount} {fees} {password} {nonce}; Send redemption of token;",
+	Short:   "SendRedemption {from} {token} {pledge rate:100|1000|10000} {amount} {fees|auto} {password} {nonce}; Send redemption of token;",
 	Example: `
 	SendRedemption 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ 100 1000 0.1
 		OR
@@ -157,7 +174,7 @@ var SendRedemptionCmd = &cobra.Command{
 		OR
 	SendRedemption 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ 100 1000 0.1 123456 0
 	`,
-	Args: cobra.MinimumNArgs(5),
+	Args: cobra.MinimumNArgs(4),
 	Run:  SendRedemption,
 }
 
@@ -190,8 +207,9 @@ func SendRedemption(cmd *cobra.Command, args []string) {
 		outputError(cmd.Use, err)
 		return
 	}
-	if reMsg.Header.Nonce == 0 {
-		reMsg.Header.Nonce = account.Nonce + 1
+	if err := fillHeader(cmd, reMsg, account); err != nil {
+		outputError(cmd.Use, err)
+		return
 	}
 	if err := signMsg(reMsg, privKey.Private); err != nil {
 		outputError(cmd.Use, errors.New("signature failure"))
@@ -239,15 +257,8 @@ func parseRedemption(args []string) (*types.Message, error) {
 		}
 	}
 
-	if fFees, err := strconv.ParseFloat(args[4], 64); err != nil {
-		return nil, errors.New("[fees] wrong")
-	} else {
-		if fFees < 0 {
-			return nil, errors.New("[fees] wrong")
-		}
-		if fee, err = amount2.NewAmount(fFees); err != nil {
-			return nil, errors.New("[fees] wrong")
-		}
+	if fee, err = parseFee(args, 4); err != nil {
+		return nil, err
 	}
 	if len(args) > 6 {
 		nonce, err = strconv.ParseUint(args[6], 10, 64)
@@ -259,6 +270,305 @@ func parseRedemption(args []string) (*types.Message, error) {
 	return reMsg, nil
 }
 
+var SendTokenOwnerCmd = &cobra.Command{
+	Use:     "SendTokenOwner {from} {token} {new owner} {fees|auto} {password} {nonce}; Transfer the ownership of token;",
+	Aliases: []string{"SendTokenOwner", "sendtokenowner", "sto", "STO"},
+	Short:   "SendTokenOwner {from} {token} {new owner} {fees|auto} {password} {nonce}; Transfer the ownership of token;",
+	Example: `
+	SendTokenOwner 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ 3ajNkh7yVYkETL9JKvGx3aL2YVNrqksjCUUE 0.1
+		OR
+	SendTokenOwner 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ 3ajNkh7yVYkETL9JKvGx3aL2YVNrqksjCUUE 0.1 123456
+		OR
+	SendTokenOwner 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ 3ajNkh7yVYkETL9JKvGx3aL2YVNrqksjCUUE 0.1 123456 0
+	`,
+	Args: cobra.MinimumNArgs(3),
+	Run:  SendTokenOwner,
+}
+
+func SendTokenOwner(cmd *cobra.Command, args []string) {
+	fee, nonce, err := parseFeesAndNonce(args, 3, 5)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	adminMsg := message.NewTokenAdmin(args[0], args[1], types.TransferOwner, args[2], 0, "", "", fee, nonce, uint64(time.Now().Unix()))
+	signAndSend(cmd, adminMsg, args, 4)
+}
+
+var SendRenounceMintCmd = &cobra.Command{
+	Use:     "SendRenounceMint {from} {token} {fees|auto} {password} {nonce}; Permanently give up the increase issuance of token;",
+	Aliases: []string{"SendRenounceMint", "sendrenouncemint", "srm", "SRM"},
+	Short:   "SendRenounceMint {from} {token} {fees|auto} {password} {nonce}; Permanently give up the increase issuance of token;",
+	Example: `
+	SendRenounceMint 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ 0.1
+		OR
+	SendRenounceMint 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ 0.1 123456
+		OR
+	SendRenounceMint 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ 0.1 123456 0
+	`,
+	Args: cobra.MinimumNArgs(2),
+	Run:  SendRenounceMint,
+}
+
+func SendRenounceMint(cmd *cobra.Command, args []string) {
+	fee, nonce, err := parseFeesAndNonce(args, 2, 4)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	adminMsg := message.NewTokenAdmin(args[0], args[1], types.RenounceMint, "", 0, "", "", fee, nonce, uint64(time.Now().Unix()))
+	signAndSend(cmd, adminMsg, args, 3)
+}
+
+var SendMaxSupplyCmd = &cobra.Command{
+	Use:     "SendMaxSupply {from} {token} {max supply} {fees|auto} {password} {nonce}; Set the max supply of token;",
+	Aliases: []string{"SendMaxSupply", "sendmaxsupply", "sms", "SMS"},
+	Short:   "SendMaxSupply {from} {token} {max supply} {fees|auto} {password} {nonce}; Set the max supply of token;",
+	Example: `
+	SendMaxSupply 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ 100000 0.1
+		OR
+	SendMaxSupply 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ 100000 0.1 123456
+		OR
+	SendMaxSupply 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ 100000 0.1 123456 0
+	`,
+	Args: cobra.MinimumNArgs(3),
+	Run:  SendMaxSupply,
+}
+
+func SendMaxSupply(cmd *cobra.Command, args []string) {
+	var maxSupply uint64
+	decimals, err := tokenDecimals(args[1])
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if fMax, err := strconv.ParseFloat(args[2], 64); err != nil {
+		outputError(cmd.Use, errors.New("[max supply] wrong"))
+		return
+	} else {
+		if fMax <= 0 {
+			outputError(cmd.Use, errors.New("[max supply] wrong"))
+			return
+		}
+		if maxSupply, err = amount2.NewAmountDecimals(fMax, decimals); err != nil {
+			outputError(cmd.Use, errors.New("[max supply] wrong"))
+			return
+		}
+	}
+	fee, nonce, err := parseFeesAndNonce(args, 3, 5)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	adminMsg := message.NewTokenAdmin(args[0], args[1], types.SetMaxSupply, "", maxSupply, "", "", fee, nonce, uint64(time.Now().Unix()))
+	signAndSend(cmd, adminMsg, args, 4)
+}
+
+var SendTokenAddressPolicyCmd = &cobra.Command{
+	Use:     "SendTokenAddressPolicy {from} {token} {action:freeze|unfreeze|allow|disallow} {address1,address2...} {fees|auto} {password} {nonce}; Freeze or allow addresses of token;",
+	Aliases: []string{"SendTokenAddressPolicy", "sendtokenaddresspolicy", "stap", "STAP"},
+	Short:   "SendTokenAddressPolicy {from} {token} {action:freeze|unfreeze|allow|disallow} {address1,address2...} {fees|auto} {password} {nonce}; Freeze or allow addresses of token;",
+	Example: `
+	SendTokenAddressPolicy 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ freeze 3ajNkh7yVYkETL9JKvGx3aL2YVNrqksjCUUE 0.1
+		OR
+	SendTokenAddressPolicy 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ allow 3ajNkh7yVYkETL9JKvGx3aL2YVNrqksjCUUE,3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ 0.1 123456
+		OR
+	SendTokenAddressPolicy 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ allow 3ajNkh7yVYkETL9JKvGx3aL2YVNrqksjCUUE 0.1 123456 0
+	`,
+	Args: cobra.MinimumNArgs(4),
+	Run:  SendTokenAddressPolicy,
+}
+
+func SendTokenAddressPolicy(cmd *cobra.Command, args []string) {
+	var action types.TokenPolicyAction
+	switch strings.ToLower(args[2]) {
+	case "freeze":
+		action = types.FreezeAddress
+	case "unfreeze":
+		action = types.UnfreezeAddress
+	case "allow":
+		action = types.AllowAddress
+	case "disallow":
+		action = types.DisallowAddress
+	default:
+		outputError(cmd.Use, errors.New("[action] wrong, must be freeze, unfreeze, allow or disallow"))
+		return
+	}
+	addrs := strings.Split(args[3], ",")
+	fee, nonce, err := parseFeesAndNonce(args, 4, 6)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	policyMsg := message.NewTokenPolicy(args[0], args[1], action, addrs, fee, nonce, uint64(time.Now().Unix()))
+	signAndSend(cmd, policyMsg, args, 5)
+}
+
+var SendTokenPolicyCmd = &cobra.Command{
+	Use:     "SendTokenPolicy {from} {token} {action:pause|unpause|allowlist|unallowlist} {fees|auto} {password} {nonce}; Pause token or switch the allowlist of token;",
+	Aliases: []string{"SendTokenPolicy", "sendtokenpolicy", "stp", "STP"},
+	Short:   "SendTokenPolicy {from} {token} {action:pause|unpause|allowlist|unallowlist} {fees|auto} {password} {nonce}; Pause token or switch the allowlist of token;",
+	Example: `
+	SendTokenPolicy 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ pause 0.1
+		OR
+	SendTokenPolicy 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ allowlist 0.1 123456
+		OR
+	SendTokenPolicy 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ unallowlist 0.1 123456 0
+	`,
+	Args: cobra.MinimumNArgs(3),
+	Run:  SendTokenPolicy,
+}
+
+func SendTokenPolicy(cmd *cobra.Command, args []string) {
+	var action types.TokenPolicyAction
+	switch strings.ToLower(args[2]) {
+	case "pause":
+		action = types.PauseToken
+	case "unpause":
+		action = types.UnpauseToken
+	case "allowlist":
+		action = types.EnableAllowlist
+	case "unallowlist":
+		action = types.DisableAllowlist
+	default:
+		outputError(cmd.Use, errors.New("[action] wrong, must be pause, unpause, allowlist or unallowlist"))
+		return
+	}
+	fee, nonce, err := parseFeesAndNonce(args, 3, 5)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	policyMsg := message.NewTokenPolicy(args[0], args[1], action, nil, fee, nonce, uint64(time.Now().Unix()))
+	signAndSend(cmd, policyMsg, args, 4)
+}
+
+func parseFeesAndNonce(args []string, feesIndex, nonceIndex int) (uint64, uint64, error) {
+	var nonce uint64
+	fee, err := parseFee(args, feesIndex)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(args) > nonceIndex {
+		nonce, err = strconv.ParseUint(args[nonceIndex], 10, 64)
+		if err != nil {
+			return 0, 0, errors.New("[nonce] wrong")
+		}
+	}
+	return fee, nonce, nil
+}
+
+// The fee is left 0 to be estimated by the node if it is omitted or auto
+func parseFee(args []string, feesIndex int) (uint64, error) {
+	if len(args) <= feesIndex || args[feesIndex] == "auto" {
+		return 0, nil
+	}
+	fFees, err := strconv.ParseFloat(args[feesIndex], 64)
+	if err != nil || fFees < 0 {
+		return 0, errors.New("[fees] wrong")
+	}
+	fee, err := amount2.NewAmount(fFees)
+	if err != nil {
+		return 0, errors.New("[fees] wrong")
+	}
+	return fee, nil
+}
+
+// Use the next nonce after the pending messages of the account and
+// the recommended fee of the node if they are not specified, and set
+// the expiry of the message from the validuntil flag
+func fillHeader(cmd *cobra.Command, msg *types.Message, account *rpctypes.Account) error {
+	if validUntil, _ := cmd.Flags().GetUint64("validuntil"); validUntil != 0 {
+		msg.Header.ValidUntil = validUntil
+	}
+	if msg.Header.Nonce == 0 {
+		msg.Header.Nonce = account.NextNonce()
+	}
+	receivers := types.FeeUnits(msg.Body)
+	if msg.Header.Fee == 0 && types.MinFee(msg.Header.Type, receivers) > 0 {
+		fee, err := estimateFee(msg.Header.Type, receivers)
+		if err != nil {
+			return err
+		}
+		msg.Header.Fee = fee
+	}
+	return nil
+}
+
+// Register the validuntil flag of the commands that send messages
+func addValidUntilFlag(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		cmd.Flags().Uint64("validuntil", 0, "The message is invalid after the block height, or the unix time if it is not less than 500000000")
+	}
+}
+
+func signAndSend(cmd *cobra.Command, msg *types.Message, args []string, passwdIndex int) {
+	var passwd []byte
+	var err error
+	if len(args) > passwdIndex {
+		passwd = []byte(args[passwdIndex])
+	} else {
+		fmt.Println("please input password：")
+		passwd, err = readPassWd()
+		if err != nil {
+			outputError(cmd.Use, fmt.Errorf("read password failed! %s", err.Error()))
+			return
+		}
+	}
+	privKey, err := loadPrivate(getAddJsonPath(msg.From().String()), passwd)
+	if err != nil {
+		outputError(cmd.Use, fmt.Errorf("wrong password"))
+		return
+	}
+	account, err := AccountByRpc(msg.From().String())
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if err := fillHeader(cmd, msg, account); err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if err := signMsg(msg, privKey.Private); err != nil {
+		outputError(cmd.Use, errors.New("signature failure"))
+		return
+	}
+
+	rs, err := sendMsg(msg)
+	if err != nil {
+		outputError(cmd.Use, err)
+	} else if rs.Code != 0 {
+		outputRespError(cmd.Use, rs)
+	} else {
+		output(string(rs.Result))
+	}
+}
+
+var SendTokenMetadataCmd = &cobra.Command{
+	Use:     "SendTokenMetadata {from} {token} {uri} {description} {fees|auto} {password} {nonce}; Update the metadata of token;",
+	Aliases: []string{"SendTokenMetadata", "sendtokenmetadata", "stm", "STM"},
+	Short:   "SendTokenMetadata {from} {token} {uri} {description} {fees|auto} {password} {nonce}; Update the metadata of token;",
+	Example: `
+	SendTokenMetadata 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ https://example.com/mt.json "M token" 0.1
+		OR
+	SendTokenMetadata 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ https://example.com/mt.json "M token" 0.1 123456
+		OR
+	SendTokenMetadata 3ajDJUnMYDyzXLwefRfNp7yLcdmg3ULb9ndQ Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ https://example.com/mt.json "M token" 0.1 123456 0
+	`,
+	Args: cobra.MinimumNArgs(4),
+	Run:  SendTokenMetadata,
+}
+
+func SendTokenMetadata(cmd *cobra.Command, args []string) {
+	fee, nonce, err := parseFeesAndNonce(args, 4, 6)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	adminMsg := message.NewTokenAdmin(args[0], args[1], types.SetMetadata, "", 0, args[2], args[3], fee, nonce, uint64(time.Now().Unix()))
+	signAndSend(cmd, adminMsg, args, 5)
+}
+
 var TokenCmd = &cobra.Command{
 	Use:     "Token {token address}; Get a token records;",
 	Aliases: []string{"token", "T", "t"},
@@ -284,6 +594,148 @@ func Token(cmd *cobra.Command, args []string) {
 	}
 }
 
+var ListTokensCmd = &cobra.Command{
+	Use:     "ListTokens {offset} {limit}; List tokens;",
+	Aliases: []string{"listtokens", "lt", "LT"},
+	Short:   "ListTokens {offset} {limit}; List tokens;",
+	Example: `
+	ListTokens
+		OR
+	ListTokens 0 20
+	`,
+	Args: cobra.MinimumNArgs(0),
+	Run:  ListTokens,
+}
+
+func ListTokens(cmd *cobra.Command, args []string) {
+	offset, limit, err := parseOffsetAndLimit(args, 0, 1)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	client, err := NewRpcClient()
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second*20)
+	defer cancel()
+
+	resp, err := client.Gc.ListTokens(ctx, &rpc.PageReq{Offset: offset, Limit: limit})
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if resp.Code == 0 {
+		output(string(resp.Result))
+		return
+	}
+	outputRespError(cmd.Use, resp)
+}
+
+var TokenHoldersCmd = &cobra.Command{
+	Use:     "TokenHolders {token address} {offset} {limit}; Get the holders of token;",
+	Aliases: []string{"tokenholders", "th", "TH"},
+	Short:   "TokenHolders {token address} {offset} {limit}; Get the holders of token;",
+	Example: `
+	TokenHolders Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ
+		OR
+	TokenHolders Tfb792w8YrJxqgWxBV8iqpHq5ntwDePkcbQ 0 20
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  TokenHolders,
+}
+
+func TokenHolders(cmd *cobra.Command, args []string) {
+	offset, limit, err := parseOffsetAndLimit(args, 1, 2)
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	client, err := NewRpcClient()
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second*20)
+	defer cancel()
+
+	resp, err := client.Gc.TokenHolders(ctx, &rpc.TokenHoldersReq{Token: args[0], Offset: offset, Limit: limit})
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if resp.Code == 0 {
+		output(string(resp.Result))
+		return
+	}
+	outputRespError(cmd.Use, resp)
+}
+
+var RichListCmd = &cobra.Command{
+	Use:     "RichList {limit}; Get the top holders of main token;",
+	Aliases: []string{"richlist", "rl", "RL"},
+	Short:   "RichList {limit}; Get the top holders of main token;",
+	Example: `
+	RichList
+		OR
+	RichList 20
+	`,
+	Args: cobra.MinimumNArgs(0),
+	Run:  RichList,
+}
+
+func RichList(cmd *cobra.Command, args []string) {
+	var limit uint64
+	var err error
+	if len(args) > 0 {
+		if limit, err = strconv.ParseUint(args[0], 10, 64); err != nil {
+			outputError(cmd.Use, errors.New("[limit] wrong"))
+			return
+		}
+	}
+	client, err := NewRpcClient()
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second*20)
+	defer cancel()
+
+	resp, err := client.Gc.RichList(ctx, &rpc.RichListReq{Limit: limit})
+	if err != nil {
+		outputError(cmd.Use, err)
+		return
+	}
+	if resp.Code == 0 {
+		output(string(resp.Result))
+		return
+	}
+	outputRespError(cmd.Use, resp)
+}
+
+func parseOffsetAndLimit(args []string, offsetIndex, limitIndex int) (uint64, uint64, error) {
+	var offset, limit uint64
+	var err error
+	if len(args) > offsetIndex {
+		if offset, err = strconv.ParseUint(args[offsetIndex], 10, 64); err != nil {
+			return 0, 0, errors.New("[offset] wrong")
+		}
+	}
+	if len(args) > limitIndex {
+		if limit, err = strconv.ParseUint(args[limitIndex], 10, 64); err != nil {
+			return 0, 0, errors.New("[limit] wrong")
+		}
+	}
+	return offset, limit, nil
+}
+
 func GetTokenByRpc(tokenAddr string) (*rpc.Response, error) {
 	client, err := NewRpcClient()
 	if err != nil {
@@ -297,3 +749,19 @@ func GetTokenByRpc(tokenAddr string) (*rpc.Response, error) {
 	return client.Gc.Token(ctx, re)
 
 }
+
+// Get the decimals of the token, tokens that do not exist use the default decimals
+func tokenDecimals(tokenAddr string) (uint8, error) {
+	resp, err := GetTokenByRpc(tokenAddr)
+	if err != nil {
+		return 0, err
+	}
+	if resp.Code != 0 {
+		return types.DefaultDecimals, nil
+	}
+	var token *rpctypes.RpcToken
+	if err := json.Unmarshal(resp.Result, &token); err != nil {
+		return 0, err
+	}
+	return token.Decimals, nil
+}
@@ -19,25 +19,31 @@ var Param *param.Param
 
 // Config is the node startup parameter
 type Config struct {
-	ConfigFile string `long:"config" description:"Start with a configuration file"`
-	Data       string `long:"data" description:"Path to application data directory"`
-	Logging    bool   `long:"logging" description:"Logging switch"`
-	ExternalIp string `long:"externalip" description:"External network IP address"`
-	Boot       string `long:"boot" description:"Custom boot"`
-	P2PPort    string `long:"p2pport" description:"Add an interface/port to listen for connections"`
-	RpcPort    string `long:"rpcport" description:"Add an interface/port to listen for RPC connections"`
-	RpcTLS     bool   `long:"rpctls" description:"Open TLS for the RPC server -- NOTE: This is only allowed if the RPC server is bound to localhost"`
-	RpcCert    string `long:"rpccert" description:"File containing the certificate file"`
-	RpcKey     string `long:"rpckey" description:"File containing the certificate key"`
-	RpcUser    string `long:"rpcuser" description:"Username for RPC connections"`
-	RpcPass    string `long:"rpcpass" description:"Password for RPC connections"`
-	HttpPort   string `long:"httpport" description:"Add an interface/port to listen for http connections"`
-	TestNet    bool   `long:"testnet" description:"Use the test network"`
-	KeyFile    string `long:"keyfile" description:"If you participate in mining, you need to configure the mining address key file"`
-	KeyPass    string `long:"keypass" description:"The decryption password for key file"`
-	RollBack   uint64 `long:"rollback" description:"Roll back to the previous height"`
-	Version    bool   `long:"version" description:"View Version number"`
-	Private    private.IPrivate
+	ConfigFile  string   `long:"config" description:"Start with a configuration file"`
+	Data        string   `long:"data" description:"Path to application data directory"`
+	Logging     bool     `long:"logging" description:"Logging switch"`
+	ExternalIp  string   `long:"externalip" description:"External network IP address"`
+	Boot        string   `long:"boot" description:"Custom boot"`
+	StaticPeer  []string `long:"staticpeer" description:"Add a trusted peer address that is always connected"`
+	MaxInbound  int      `long:"maxinbound" description:"Maximum number of inbound peer connections"`
+	MaxOutbound int      `long:"maxoutbound" description:"Maximum number of outbound peer connections"`
+	SwarmKey    string   `long:"swarmkey" description:"File containing the pre-shared key of the private network"`
+	AllowPeer   []string `long:"allowpeer" description:"Add a peer id allowed to connect in the private network"`
+	P2PPort     string   `long:"p2pport" description:"Add an interface/port to listen for connections"`
+	RpcPort     string   `long:"rpcport" description:"Add an interface/port to listen for RPC connections"`
+	RpcTLS      bool     `long:"rpctls" description:"Open TLS for the RPC server -- NOTE: This is only allowed if the RPC server is bound to localhost"`
+	RpcCert     string   `long:"rpccert" description:"File containing the certificate file"`
+	RpcKey      string   `long:"rpckey" description:"File containing the certificate key"`
+	RpcUser     string   `long:"rpcuser" description:"Username for RPC connections"`
+	RpcPass     string   `long:"rpcpass" description:"Password for RPC connections"`
+	HttpPort    string   `long:"httpport" description:"Add an interface/port to listen for http connections"`
+	TestNet     bool     `long:"testnet" description:"Use the test network"`
+	KeyFile     string   `long:"keyfile" description:"If you participate in mining, you need to configure the mining address key file"`
+	KeyPass     string   `long:"keypass" description:"The decryption password for key file"`
+	RollBack    uint64   `long:"rollback" description:"Roll back to the previous height"`
+	ForkQuorum  uint32   `long:"forkquorum" description:"Percentage of the current supers that must have a conflicting block to switch to it"`
+	Version     bool     `long:"version" description:"View Version number"`
+	Private     private.IPrivate
 }
 
 // LoadParam load the parse node startup parameter
@@ -87,6 +93,21 @@ func LoadParam(private private.IPrivate) error {
 	if cfg.Boot != "" {
 		Param.P2pParam.CustomBoot = cfg.Boot
 	}
+	if len(cfg.StaticPeer) != 0 {
+		Param.P2pParam.StaticPeers = cfg.StaticPeer
+	}
+	if cfg.MaxInbound > 0 {
+		Param.P2pParam.MaxInbound = cfg.MaxInbound
+	}
+	if cfg.MaxOutbound > 0 {
+		Param.P2pParam.MaxOutbound = cfg.MaxOutbound
+	}
+	if cfg.SwarmKey != "" {
+		Param.P2pParam.SwarmKey = cfg.SwarmKey
+	}
+	if len(cfg.AllowPeer) != 0 {
+		Param.P2pParam.AllowPeers = cfg.AllowPeer
+	}
 
 	// Set the default external IP. If the external IP is not set,
 	// other nodes can only know you but cannot send messages to you.
@@ -121,6 +142,12 @@ func LoadParam(private private.IPrivate) error {
 	if cfg.RollBack != 0 {
 		Param.RollBack = cfg.RollBack
 	}
+	if cfg.ForkQuorum != 0 {
+		if cfg.ForkQuorum >= 100 {
+			return fmt.Errorf("the fork quorum must be less than 100")
+		}
+		Param.DPosParam.ForkQuorum = cfg.ForkQuorum
+	}
 
 	if !utils.Exist(Param.Data) {
 		if err := os.Mkdir(Param.Data, os.ModePerm); err != nil {
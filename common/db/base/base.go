@@ -85,6 +85,32 @@ func (b *Base) Foreach(bucket string) map[string][]byte {
 	return rs
 }
 
+// Get the keys and values of the bucket in key order, the first offset
+// entries are skipped and at most limit entries are returned. The bucket
+// name is removed from the keys.
+func (b *Base) Range(bucket string, offset, limit uint64) ([][]byte, [][]byte) {
+	keys := make([][]byte, 0)
+	values := make([][]byte, 0)
+	prefix := Prefix(bucket)
+	iter := b.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	var index uint64
+	for iter.Next() && uint64(len(keys)) < limit {
+		index++
+		if index <= offset {
+			continue
+		}
+		key := make([]byte, len(iter.Key())-len(prefix))
+		copy(key, iter.Key()[len(prefix):])
+		value := make([]byte, len(iter.Value()))
+		copy(value, iter.Value())
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	return keys, values
+}
+
 func Key(bucket string, key []byte) []byte {
 	return bytes.Join([][]byte{
 		[]byte(bucket + "-"), key}, []byte{})
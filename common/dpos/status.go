@@ -26,4 +26,5 @@ type IDPosStatus interface {
 	AddAddressWork(cycle uint64, super arry.Address, works types.IWorks)
 	AddressWork(cycle uint64, super arry.Address) (types.IWorks, error)
 	Commit() (arry.Hash, error)
+	Copy() (IDPosStatus, error)
 }
@@ -0,0 +1,58 @@
+package horn
+
+import (
+	"container/list"
+	"sync"
+)
+
+// A bounded set of hashes, the oldest hash is dropped when it is full
+type hashCache struct {
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+	mutex    sync.Mutex
+}
+
+func newHashCache(capacity int) *hashCache {
+	return &hashCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Add the hash, returns false if it already exists
+func (c *hashCache) Add(hash string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.items[hash]; ok {
+		c.order.MoveToFront(elem)
+		return false
+	}
+	c.items[hash] = c.order.PushFront(hash)
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(string))
+	}
+	return true
+}
+
+func (c *hashCache) Has(hash string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_, ok := c.items[hash]
+	return ok
+}
+
+func (c *hashCache) Remove(hash string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.items[hash]; ok {
+		c.order.Remove(elem)
+		delete(c.items, hash)
+	}
+}
@@ -0,0 +1,38 @@
+package horn
+
+import "testing"
+
+func TestHashCache(t *testing.T) {
+	cache := newHashCache(2)
+	tests := []struct {
+		name   string
+		op     func() bool
+		ok     bool
+		has    []string
+		notHas []string
+	}{
+		{"add a", func() bool { return cache.Add("a") }, true, []string{"a"}, nil},
+		{"add a again", func() bool { return cache.Add("a") }, false, []string{"a"}, nil},
+		{"add b", func() bool { return cache.Add("b") }, true, []string{"a", "b"}, nil},
+		{"use a", func() bool { return cache.Add("a") }, false, []string{"a", "b"}, nil},
+		{"add c over the capacity", func() bool { return cache.Add("c") }, true, []string{"a", "c"}, []string{"b"}},
+		{"add b again", func() bool { return cache.Add("b") }, true, []string{"b", "c"}, []string{"a"}},
+		{"remove c", func() bool { cache.Remove("c"); return true }, true, []string{"b"}, []string{"a", "c"}},
+		{"add c after removing", func() bool { return cache.Add("c") }, true, []string{"b", "c"}, []string{"a"}},
+	}
+	for _, test := range tests {
+		if ok := test.op(); ok != test.ok {
+			t.Fatalf("%s: expect %v, got %v", test.name, test.ok, ok)
+		}
+		for _, hash := range test.has {
+			if !cache.Has(hash) {
+				t.Fatalf("%s: %s should be in the cache", test.name, hash)
+			}
+		}
+		for _, hash := range test.notHas {
+			if cache.Has(hash) {
+				t.Fatalf("%s: %s should not be in the cache", test.name, hash)
+			}
+		}
+	}
+}
@@ -1,20 +1,69 @@
 package horn
 
 import (
+	"errors"
+	"fmt"
+	chaintypes "github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/service/gorutinue"
 	"github.com/aiot-network/aiotchain/service/peers"
 	"github.com/aiot-network/aiotchain/service/request"
+	servicesync "github.com/aiot-network/aiotchain/service/sync"
 	log "github.com/aiot-network/aiotchain/tools/log/log15"
 	"github.com/aiot-network/aiotchain/types"
+	"math/rand"
+	"sync"
 )
 
-const module = "horn"
+const (
+	module = "horn"
+	// Number of peers that a received message or block is relayed to
+	relayFanout = 8
+	// Number of hashes remembered as seen
+	maxSeen = 100000
+	// Number of hashes remembered as known by each peer
+	maxKnown = 10000
+)
+
+// Messages rejected for the current state of the pool or the chain,
+// they may be accepted when they are received again
+var retryMsgErrs = map[chaintypes.ErrCode]bool{
+	chaintypes.ErrInsufficientBalance: true,
+	chaintypes.ErrNonceTooHigh:        true,
+	chaintypes.ErrFeeTooLow:           true,
+	chaintypes.ErrPoolFull:            true,
+	chaintypes.ErrAddressLimit:        true,
+	chaintypes.ErrPeerLimit:           true,
+}
+
+// The error of accepting a requested message or block, the accept
+// has already forgotten the hash if it may be received again
+type acceptError struct {
+	error
+}
+
+func (e acceptError) Unwrap() error {
+	return e.error
+}
 
+// Horn spreads messages and blocks by gossip. Peers that support inventories
+// are sent an announcement and request the content they have not seen,
+// other peers are sent the full content. Blocks are sent as compact blocks
+// to the peers that support them. A received message or block is
+// relayed to a fanout of peers after it is validated, never to the peers
+// that already know it.
 type Horn struct {
 	peers   *peers.Peers
 	request request.IRequestHandler
 	local   *types.Peer
 	gPool   *gorutinue.Pool
+	// Hashes of the messages and blocks that have been received or requested
+	seen *hashCache
+	// Hashes known by each peer, it has sent or been sent them
+	known          map[string]*hashCache
+	knownMutex     sync.Mutex
+	receiveMessage func(types.IMessage, string) error
+	receiveBlock   func(types.IBlock, string) error
+	poolMessages   func() []types.IMessage
 }
 
 func NewHorn(peers *peers.Peers, gPool *gorutinue.Pool, request request.IRequestHandler) *Horn {
@@ -23,37 +72,319 @@ func NewHorn(peers *peers.Peers, gPool *gorutinue.Pool, request request.IRequest
 		request: request,
 		local:   peers.Local(),
 		gPool:   gPool,
+		seen:    newHashCache(maxSeen),
+		known:   make(map[string]*hashCache),
 	}
 }
 
+// Register the function that validates the messages received from peers
+func (h *Horn) RegisterReceiveMessage(f func(types.IMessage, string) error) {
+	h.receiveMessage = f
+}
+
+// Register the function that validates the blocks received from peers
+func (h *Horn) RegisterReceiveBlock(f func(types.IBlock, string) error) {
+	h.receiveBlock = f
+}
+
+// Register the function that returns the messages of the pool, which
+// compact blocks are rebuilt from
+func (h *Horn) RegisterPoolMessages(f func() []types.IMessage) {
+	h.poolMessages = f
+}
+
+// Broadcast the local message to all peers
 func (h *Horn) BroadcastMsg(message types.IMessage) {
-	peers := h.peers.PeersMap()
-	for id, peer := range peers {
-		if h.local == nil || id != h.local.Address.ID.String() {
-			conn := peer.Conn
-			if err := h.gPool.AddTask(gorutinue.NewTask(
-				func() error {
-					return h.request.SendMsg(conn, message)
-				})); err != nil {
-				log.Warn("Adding the task to send the message failed", "module", module,
-					"hash", message.Hash().String(), "target", peer.Address.String())
+	h.seen.Add(message.Hash().String())
+	h.sendMsg(message, h.targets(message.Hash().String(), "", false))
+}
+
+// Broadcast the local block to all peers
+func (h *Horn) BroadcastBlock(block types.IBlock) {
+	h.seen.Add(block.GetHash().String())
+	h.sendBlock(block, h.targets(block.GetHash().String(), "", false))
+}
+
+// Receive the full message pushed by the peer
+func (h *Horn) ReceiveMsg(message types.IMessage, peerId string) error {
+	h.addKnown(peerId, message.Hash().String())
+	if !h.seen.Add(message.Hash().String()) {
+		return nil
+	}
+	return h.acceptMsg(message, peerId)
+}
+
+// Receive the full block pushed by the peer
+func (h *Horn) ReceiveBlock(block types.IBlock, peerId string) error {
+	h.addKnown(peerId, block.GetHash().String())
+	if !h.seen.Add(block.GetHash().String()) {
+		return nil
+	}
+	return h.acceptBlock(block, peerId)
+}
+
+// Rebuild the compact block sent by the peer
+func (h *Horn) ReceiveCompactBlock(compact types.ICompactBlock, peerId string) {
+	hash := compact.BlockHeader().GetHash().String()
+	h.addKnown(peerId, hash)
+	if !h.seen.Add(hash) {
+		return
+	}
+	if err := h.gPool.AddTask(gorutinue.NewTask(
+		func() error {
+			err := h.rebuild(compact, peerId)
+			if err != nil {
+				if !errors.As(err, &acceptError{}) {
+					h.seen.Remove(hash)
+				}
+				log.Debug("Failed to rebuild the compact block", "module", module,
+					"hash", hash, "peer", peerId, "error", err)
 			}
+			return err
+		})); err != nil {
+		h.seen.Remove(hash)
+	}
+}
+
+// Rebuild the block from the pool, the missing messages are requested from the
+// peer. The full block is requested if the rebuilt messages do not match the
+// message root because of short id collisions.
+func (h *Horn) rebuild(compact types.ICompactBlock, peerId string) error {
+	peer := h.peers.Peer(peerId)
+	if peer == nil {
+		return fmt.Errorf("peer %s is not connected", peerId)
+	}
+	var pool []types.IMessage
+	if h.poolMessages != nil {
+		pool = h.poolMessages()
+	}
+	hash := compact.BlockHeader().GetHash()
+	msgs, missing, err := compact.Fill(pool)
+	if err != nil {
+		h.peers.Penalize(peerId, request.PenaltyProtocol)
+		return err
+	}
+	if len(missing) != 0 {
+		found, err := h.request.GetBlockMsgs(peer.Conn, hash, missing)
+		if err != nil {
+			return err
+		}
+		for i, index := range missing {
+			msgs[index] = found[i]
 		}
 	}
+	block := compact.ToBlock(msgs)
+	if !block.CheckMsgRoot() {
+		log.Debug("The rebuilt block does not match, request the full block", "module", module,
+			"hash", hash.String(), "peer", peerId)
+		if block, err = h.request.GetBlockByHash(peer.Conn, hash); err != nil {
+			return err
+		}
+		if !block.GetHash().IsEqual(hash) {
+			h.peers.Penalize(peerId, request.PenaltyProtocol)
+			return fmt.Errorf("the block %s is not the requested %s", block.GetHash().String(), hash.String())
+		}
+	}
+	if err := h.acceptBlock(block, peerId); err != nil {
+		return acceptError{err}
+	}
+	return nil
 }
 
-func (h *Horn) BroadcastBlock(block types.IBlock) {
+// Request the announced messages and blocks that have not been seen from the peer
+func (h *Horn) ReceiveInventory(invs []*types.Inventory, peerId string) {
+	for _, inv := range invs {
+		hash := inv.Hash.String()
+		h.addKnown(peerId, hash)
+		if !h.seen.Add(hash) {
+			continue
+		}
+		inv := inv
+		if err := h.gPool.AddTask(gorutinue.NewTask(
+			func() error {
+				err := h.fetch(inv, peerId)
+				if err != nil {
+					// Allow the other peers that announce it to be requested
+					if !errors.As(err, &acceptError{}) {
+						h.seen.Remove(inv.Hash.String())
+					}
+					log.Debug("Failed to request the inventory", "module", module,
+						"hash", inv.Hash.String(), "peer", peerId, "error", err)
+				}
+				return err
+			})); err != nil {
+			h.seen.Remove(hash)
+		}
+	}
+}
+
+func (h *Horn) fetch(inv *types.Inventory, peerId string) error {
+	peer := h.peers.Peer(peerId)
+	if peer == nil {
+		return fmt.Errorf("peer %s is not connected", peerId)
+	}
+	switch inv.Kind {
+	case types.InvMsg:
+		message, err := h.request.GetMessage(peer.Conn, inv.Hash)
+		if err != nil {
+			return err
+		}
+		if !message.Hash().IsEqual(inv.Hash) {
+			h.peers.Penalize(peerId, request.PenaltyProtocol)
+			return fmt.Errorf("the message %s is not the announced %s", message.Hash().String(), inv.Hash.String())
+		}
+		if err := h.acceptMsg(message, peerId); err != nil {
+			return acceptError{err}
+		}
+	case types.InvBlock:
+		block, err := h.request.GetBlockByHash(peer.Conn, inv.Hash)
+		if err != nil {
+			return err
+		}
+		if !block.GetHash().IsEqual(inv.Hash) {
+			h.peers.Penalize(peerId, request.PenaltyProtocol)
+			return fmt.Errorf("the block %s is not the announced %s", block.GetHash().String(), inv.Hash.String())
+		}
+		if err := h.acceptBlock(block, peerId); err != nil {
+			return acceptError{err}
+		}
+	default:
+		h.peers.Penalize(peerId, request.PenaltyProtocol)
+		return fmt.Errorf("unknown inventory kind %d", inv.Kind)
+	}
+	return nil
+}
+
+// Validate the message and relay it if it is valid, the hash of a message
+// that is not invalid but rejected for now is forgotten
+func (h *Horn) acceptMsg(message types.IMessage, peerId string) error {
+	if h.receiveMessage == nil {
+		return nil
+	}
+	if err := h.receiveMessage(message, peerId); err != nil {
+		if msgErr, ok := chaintypes.AsMsgError(err); ok && retryMsgErrs[msgErr.Code] {
+			h.seen.Remove(message.Hash().String())
+		}
+		return err
+	}
+	h.sendMsg(message, h.targets(message.Hash().String(), peerId, true))
+	return nil
+}
+
+// Validate the block and relay it if it is inserted. The hash of a block
+// that is not inserted is forgotten unless it is invalid or already in the chain,
+// the block that is not the next one or on a side fork may be inserted later.
+func (h *Horn) acceptBlock(block types.IBlock, peerId string) error {
+	if h.receiveBlock == nil {
+		return nil
+	}
+	if err := h.receiveBlock(block, peerId); err != nil {
+		if !errors.Is(err, servicesync.Err_InvalidBlock) && !errors.Is(err, servicesync.Err_RepeatBlock) {
+			h.seen.Remove(block.GetHash().String())
+		}
+		return err
+	}
+	h.sendBlock(block, h.targets(block.GetHash().String(), peerId, true))
+	return nil
+}
+
+func (h *Horn) sendMsg(message types.IMessage, targets []*types.Peer) {
+	inv := &types.Inventory{Kind: types.InvMsg, Hash: message.Hash()}
+	for _, peer := range targets {
+		conn := peer.Conn
+		inventory := supports(peer, types.CapInventory)
+		h.addKnown(peer.Address.ID.String(), inv.Hash.String())
+		if err := h.gPool.AddTask(gorutinue.NewTask(
+			func() error {
+				if inventory {
+					return h.request.Announce(conn, []*types.Inventory{inv})
+				}
+				return h.request.SendMsg(conn, message)
+			})); err != nil {
+			log.Warn("Adding the task to send the message failed", "module", module,
+				"hash", message.Hash().String(), "target", peer.Address.String())
+		}
+	}
+}
+
+func (h *Horn) sendBlock(block types.IBlock, targets []*types.Peer) {
+	inv := &types.Inventory{Kind: types.InvBlock, Hash: block.GetHash(), Height: block.GetHeight()}
+	for _, peer := range targets {
+		conn := peer.Conn
+		compact := supports(peer, types.CapCompact)
+		inventory := supports(peer, types.CapInventory)
+		h.addKnown(peer.Address.ID.String(), inv.Hash.String())
+		if err := h.gPool.AddTask(gorutinue.NewTask(
+			func() error {
+				if compact {
+					return h.request.SendCompactBlock(conn, block)
+				} else if inventory {
+					return h.request.Announce(conn, []*types.Inventory{inv})
+				}
+				return h.request.SendBlock(conn, block)
+			})); err != nil {
+			log.Warn("Adding the task to send the block failed", "module", module,
+				"height", block.GetHash().String(), "target", peer.Address.String())
+		}
+	}
+}
+
+// The peers to send the hash to. Relays skip the source and the peers
+// that know the hash, and are limited to a random fanout of peers.
+func (h *Horn) targets(hash, from string, relay bool) []*types.Peer {
 	peers := h.peers.PeersMap()
+	h.pruneKnown(peers)
+	targets := make([]*types.Peer, 0)
 	for id, peer := range peers {
-		if h.local == nil || id != h.local.Address.ID.String() {
-			conn := peer.Conn
-			if err := h.gPool.AddTask(gorutinue.NewTask(
-				func() error {
-					return h.request.SendBlock(conn, block)
-				})); err != nil {
-				log.Warn("Adding the task to send the block failed", "module", module,
-					"height", block.GetHash().String(), "target", peer.Address.String())
-			}
+		if h.local != nil && id == h.local.Address.ID.String() {
+			continue
+		}
+		if relay && (id == from || h.isKnown(id, hash)) {
+			continue
+		}
+		targets = append(targets, peer)
+	}
+	if relay && len(targets) > relayFanout {
+		rand.Shuffle(len(targets), func(i, j int) {
+			targets[i], targets[j] = targets[j], targets[i]
+		})
+		targets = targets[:relayFanout]
+	}
+	return targets
+}
+
+func (h *Horn) addKnown(peerId, hash string) {
+	h.knownMutex.Lock()
+	known, ok := h.known[peerId]
+	if !ok {
+		known = newHashCache(maxKnown)
+		h.known[peerId] = known
+	}
+	h.knownMutex.Unlock()
+
+	known.Add(hash)
+}
+
+// Forget the hashes of the peers that are disconnected
+func (h *Horn) pruneKnown(peers map[string]*types.Peer) {
+	h.knownMutex.Lock()
+	defer h.knownMutex.Unlock()
+
+	for id := range h.known {
+		if _, ok := peers[id]; !ok {
+			delete(h.known, id)
 		}
 	}
 }
+
+func (h *Horn) isKnown(peerId, hash string) bool {
+	h.knownMutex.Lock()
+	known, ok := h.known[peerId]
+	h.knownMutex.Unlock()
+
+	return ok && known.Has(hash)
+}
+
+func supports(peer *types.Peer, capability uint64) bool {
+	return peer.Handshake != nil && peer.Handshake.Supports(capability)
+}
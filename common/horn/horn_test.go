@@ -0,0 +1,211 @@
+package horn
+
+import (
+	"errors"
+	"fmt"
+	chaintypes "github.com/aiot-network/aiotchain/chain/types"
+	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/common/param"
+	"github.com/aiot-network/aiotchain/service/gorutinue"
+	"github.com/aiot-network/aiotchain/service/peers"
+	"github.com/aiot-network/aiotchain/service/request"
+	servicesync "github.com/aiot-network/aiotchain/service/sync"
+	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/types"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"testing"
+)
+
+type testMsg struct {
+	types.IMessage
+	hash arry.Hash
+}
+
+func (m *testMsg) Hash() arry.Hash { return m.hash }
+
+type testBlock struct {
+	types.IBlock
+	hash arry.Hash
+}
+
+func (b *testBlock) GetHash() arry.Hash { return b.hash }
+func (b *testBlock) GetHeight() uint64  { return 1 }
+
+type testBanDB struct{}
+
+func (db *testBanDB) Read() []*types.Ban   { return nil }
+func (db *testBanDB) Save(ban *types.Ban)  {}
+func (db *testBanDB) Delete(peerId string) {}
+func (db *testBanDB) Close() error         { return nil }
+
+// A peer requester that answers the message requests with the message
+type testRequester struct {
+	request.IRequestHandler
+	message types.IMessage
+	err     error
+}
+
+func (r *testRequester) GetMessage(conn *types.Conn, hash arry.Hash) (types.IMessage, error) {
+	return r.message, r.err
+}
+
+func testId(i int) string {
+	return peer.ID(fmt.Sprintf("peer%d", i)).String()
+}
+
+// A horn connected to the count of peers, the sending tasks are queued
+// in a goroutine pool that is not started
+func newTestHorn(t *testing.T, requester *testRequester, count int) *Horn {
+	config.Param = param.TestNetParam
+	p := peers.NewPeers(requester, &testBanDB{})
+	for i := 0; i < count; i++ {
+		id := peer.ID(fmt.Sprintf("peer%d", i))
+		if err := p.AddPeer(&types.Peer{Address: &peer.AddrInfo{ID: id}, Conn: &types.Conn{PeerId: id}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewHorn(p, gorutinue.NewPool(), requester)
+}
+
+func TestHorn_Targets(t *testing.T) {
+	tests := []struct {
+		name    string
+		peers   int
+		relay   bool
+		count   int
+		exclude []string
+	}{
+		{"broadcast to all peers", 12, false, 12, nil},
+		{"relay to the fanout", 12, true, relayFanout, []string{testId(0), testId(1)}},
+		{"relay to the peers that do not know it", 4, true, 2, []string{testId(0), testId(1)}},
+	}
+	for _, test := range tests {
+		h := newTestHorn(t, &testRequester{}, test.peers)
+		h.addKnown(testId(1), "hash")
+		targets := h.targets("hash", testId(0), test.relay)
+		if len(targets) != test.count {
+			t.Fatalf("%s: expect %d targets, got %d", test.name, test.count, len(targets))
+		}
+		for _, target := range targets {
+			for _, id := range test.exclude {
+				if target.Address.ID.String() == id {
+					t.Fatalf("%s: %s should not be a target", test.name, id)
+				}
+			}
+		}
+	}
+}
+
+func TestHorn_ReceiveMsg(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		calls   int
+		seen    bool
+		relayed bool
+	}{
+		{"valid", nil, 1, true, true},
+		{"rejected for now", chaintypes.NewMsgError(chaintypes.ErrNonceTooHigh, nil, "nonce too high"), 2, false, false},
+		{"invalid", chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "bad message"), 1, true, false},
+	}
+	for _, test := range tests {
+		h := newTestHorn(t, &testRequester{}, 3)
+		calls := 0
+		h.RegisterReceiveMessage(func(message types.IMessage, peerId string) error {
+			calls++
+			return test.err
+		})
+		msg := &testMsg{hash: arry.BytesToHash([]byte("message"))}
+		hash := msg.Hash().String()
+		for i := 0; i < 2; i++ {
+			if err := h.ReceiveMsg(msg, testId(0)); i == 0 && err != test.err {
+				t.Fatalf("%s: expect error %v, got %v", test.name, test.err, err)
+			}
+		}
+		if calls != test.calls {
+			t.Fatalf("%s: the message should be validated %d times, got %d", test.name, test.calls, calls)
+		}
+		if h.seen.Has(hash) != test.seen {
+			t.Fatalf("%s: the message should be seen %v", test.name, test.seen)
+		}
+		if !h.isKnown(testId(0), hash) {
+			t.Fatalf("%s: the sender should know the message", test.name)
+		}
+		if h.isKnown(testId(1), hash) != test.relayed || h.isKnown(testId(2), hash) != test.relayed {
+			t.Fatalf("%s: the message should be relayed %v", test.name, test.relayed)
+		}
+	}
+}
+
+func TestHorn_ReceiveBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		calls   int
+		seen    bool
+		relayed bool
+	}{
+		{"inserted", nil, 1, true, true},
+		{"not the next block", errors.New("not the next block"), 2, false, false},
+		{"invalid", fmt.Errorf("%w: wrong signer", servicesync.Err_InvalidBlock), 1, true, false},
+		{"in the chain", servicesync.Err_RepeatBlock, 1, true, false},
+	}
+	for _, test := range tests {
+		h := newTestHorn(t, &testRequester{}, 3)
+		calls := 0
+		h.RegisterReceiveBlock(func(block types.IBlock, peerId string) error {
+			calls++
+			return test.err
+		})
+		block := &testBlock{hash: arry.BytesToHash([]byte("block"))}
+		hash := block.GetHash().String()
+		for i := 0; i < 2; i++ {
+			h.ReceiveBlock(block, testId(0))
+		}
+		if calls != test.calls {
+			t.Fatalf("%s: the block should be validated %d times, got %d", test.name, test.calls, calls)
+		}
+		if h.seen.Has(hash) != test.seen {
+			t.Fatalf("%s: the block should be seen %v", test.name, test.seen)
+		}
+		if h.isKnown(testId(1), hash) != test.relayed {
+			t.Fatalf("%s: the block should be relayed %v", test.name, test.relayed)
+		}
+	}
+}
+
+func TestHorn_Fetch(t *testing.T) {
+	hash := arry.BytesToHash([]byte("message"))
+	tests := []struct {
+		name    string
+		message types.IMessage
+		err     error
+		accept  error
+		fails   bool
+		penalty bool
+	}{
+		{"accepted", &testMsg{hash: hash}, nil, nil, false, false},
+		{"request failed", nil, request.Err_PeerClosed, nil, true, false},
+		{"not the announced", &testMsg{hash: arry.BytesToHash([]byte("other"))}, nil, nil, true, true},
+		{"invalid", &testMsg{hash: hash}, nil, chaintypes.NewMsgError(chaintypes.ErrBadMessage, nil, "bad message"), true, false},
+	}
+	for _, test := range tests {
+		h := newTestHorn(t, &testRequester{message: test.message, err: test.err}, 1)
+		h.RegisterReceiveMessage(func(message types.IMessage, peerId string) error {
+			return test.accept
+		})
+		err := h.fetch(&types.Inventory{Kind: types.InvMsg, Hash: hash}, testId(0))
+		if (err != nil) != test.fails {
+			t.Fatalf("%s: expect failure %v, got %v", test.name, test.fails, err)
+		}
+		// Only the accept errors keep the hash of an invalid message seen
+		if isAccept := errors.As(err, &acceptError{}); isAccept != (test.accept != nil) {
+			t.Fatalf("%s: the error %v should be an accept error %v", test.name, err, test.accept != nil)
+		} else if isAccept && !errors.Is(err, test.accept) {
+			t.Fatalf("%s: expect error %v, got %v", test.name, test.accept, err)
+		}
+		if penalized := h.peers.Score(testId(0)) < 0; penalized != test.penalty {
+			t.Fatalf("%s: the peer should be penalized %v", test.name, test.penalty)
+		}
+	}
+}
@@ -7,6 +7,7 @@ type IMsgList interface {
 	DeleteEnd(types.IMessage)
 	Delete(types.IMessage)
 	Read() error
+	Recover([]types.IMessage)
 	Close() error
 	Update()
 	Exist(types.IMessage) bool
@@ -15,5 +16,7 @@ type IMsgList interface {
 	StagnantMsgs() []types.IMessage
 	GetAll() ([]types.IMessage, []types.IMessage)
 	Get(string) (types.IMessage, bool)
+	GetByAddress(string) ([]types.IMessage, []types.IMessage)
 	Count() int
+	Capacity() int
 }
@@ -3,6 +3,7 @@ package param
 import (
 	"github.com/aiot-network/aiotchain/common/private"
 	"github.com/aiot-network/aiotchain/tools/arry"
+	"math"
 	"time"
 )
 
@@ -70,6 +71,21 @@ type TokenParam struct {
 	MainToken             arry.Address
 	EaterAddress          arry.Address
 	PreCirculations       []PreCirculation
+	// Height from which a zero-value transfer of the main token to the
+	// sender itself is valid, it cancels the pending message of the nonce
+	CancelHeight uint64
+	// Height from which TokenAdmin messages are valid
+	TokenAdminHeight uint64
+	// Height from which TokenPolicy messages are valid and
+	// transfers are restricted by the token policies
+	TokenPolicyHeight uint64
+	// Height from which Batch messages are valid
+	BatchHeight uint64
+	// Height from which a block can contain several messages
+	// of an address with consecutive nonce values
+	RepeatSenderHeight uint64
+	// Height from which the ValidUntil of messages is enforced
+	ValidUntilHeight uint64
 }
 
 type PrivateParam struct {
@@ -82,6 +98,18 @@ type P2pParam struct {
 	ExternalIp string
 	NetWork    string
 	CustomBoot string
+	// Maximum number of peers that connected to the local node
+	MaxInbound int
+	// Maximum number of peers that the local node connected to
+	MaxOutbound int
+	// Addresses of the trusted peers that are always connected
+	StaticPeers []string
+	// File of the pre-shared key of the private network, the node
+	// joins the public network if it is empty
+	SwarmKey string
+	// Ids of the peers allowed to connect in the private network,
+	// all peers holding the key are allowed if it is empty
+	AllowPeers []string
 }
 
 type RpcParam struct {
@@ -110,6 +138,9 @@ type DPosParam struct {
 	WorkProofAddress    string
 	GenesisSuperList    []AddressInfo
 	CoinBaseAddressList *CoinBaseAddress
+	// A conflicting block is switched to when more than this percentage
+	// of the supers of the current cycle have it
+	ForkQuorum uint32
 }
 
 type PoolParam struct {
@@ -117,8 +148,24 @@ type PoolParam struct {
 	MonitorMsgInterval time.Duration
 	MaxPoolMsg         int
 	MaxAddressMsg      uint64
+	// Message types packed first, such as consensus messages
+	PriorityMsgTypes []uint8
+	// Percentage of the block space reserved for priority messages
+	PriorityReserve uint32
+	// Maximum number of messages accepted from one peer in PeerMsgWindow seconds
+	MaxPeerMsg    int
+	PeerMsgWindow int64
+	// A message that failed verification this many times is rejected without verifying
+	MaxMsgFailures int
+	// Score deducted from a peer that relays an invalid message
+	InvalidMsgPenalty int
+	// Percentage by which the fee must be raised to replace a pending message
+	ReplaceFeeBump uint32
 }
 
+// Candidate, Cancel, Vote and Work messages
+var priorityMsgTypes = []uint8{2, 3, 4, 5}
+
 var TestNetParam = &Param{
 	Name:              TestNet,
 	Data:              "data",
@@ -151,13 +198,21 @@ var TestNetParam = &Param{
 			Address: "aiCSxRKuF8dYALbZ2av8gqcoVR34R4aecYX",
 			Amount:  160000 * AtomsPerCoin,
 		}},
+		CancelHeight:       0,
+		TokenAdminHeight:   0,
+		TokenPolicyHeight:  0,
+		BatchHeight:        0,
+		RepeatSenderHeight: 0,
+		ValidUntilHeight:   0,
 	},
 	P2pParam: &P2pParam{
 		NetWork:    TestNet + "AIOT_NETWORK",
 		P2pPort:    "13561",
 		ExternalIp: "0.0.0.0",
 		//aiTewnyK73P3chNgp7LgC8FoCHUhTe9cijZ
-		CustomBoot: "/ip4/103.68.63.163/tcp/6008/ipfs/16Uiu2HAmJRKJkBvTxFEoSpQmvPaHZuVRrHBYVVKKetCMMBZ938ty",
+		CustomBoot:  "/ip4/103.68.63.163/tcp/6008/ipfs/16Uiu2HAmJRKJkBvTxFEoSpQmvPaHZuVRrHBYVVKKetCMMBZ938ty",
+		MaxInbound:  50,
+		MaxOutbound: 25,
 	},
 	RpcParam: &RpcParam{
 		RpcIp:      "127.0.0.1",
@@ -174,6 +229,7 @@ var TestNetParam = &Param{
 		CycleInterval:    CycleInterval,
 		SuperSize:        SuperSize,
 		DPosSize:         DPosSize,
+		ForkQuorum:       50,
 		GenesisTime:      1592268410,
 		GenesisCycle:     1592268410 / CycleInterval,
 		WorkProofAddress: "aiCSxRKuF8dYALbZ2av8gqcoVR34R4aecYX",
@@ -259,6 +315,13 @@ var TestNetParam = &Param{
 		MsgExpiredTime:     60 * 60 * 3,
 		MonitorMsgInterval: 10,
 		MaxAddressMsg:      1000,
+		PriorityMsgTypes:   priorityMsgTypes,
+		PriorityReserve:    10,
+		MaxPeerMsg:         1000,
+		PeerMsgWindow:      60,
+		MaxMsgFailures:     3,
+		InvalidMsgPenalty:  10,
+		ReplaceFeeBump:     10,
 	},
 }
 
@@ -294,13 +357,22 @@ var MainNetParam = &Param{
 			Address: "AijJxc2QqB32QmF83WZrLT5TFz61web6bRw",
 			Amount:  160000 * AtomsPerCoin,
 		}},
+		// Not activated until the fork height is scheduled
+		CancelHeight:       math.MaxUint64,
+		TokenAdminHeight:   math.MaxUint64,
+		TokenPolicyHeight:  math.MaxUint64,
+		BatchHeight:        math.MaxUint64,
+		RepeatSenderHeight: math.MaxUint64,
+		ValidUntilHeight:   math.MaxUint64,
 	},
 	P2pParam: &P2pParam{
 		NetWork:    MainNet + "AIOT_NETWORK",
 		P2pPort:    "23561",
 		ExternalIp: "0.0.0.0",
 		//Aib7bDUym4KWu9painU1NHYi9w2dVAfe3ok
-		CustomBoot: "/ip4/180.188.198.241/tcp/29564/ipfs/16Uiu2HAmBA6XR8USSaN4SWPwaha5gYcADSBu2hfA7rWtVE9NN44m",
+		CustomBoot:  "/ip4/180.188.198.241/tcp/29564/ipfs/16Uiu2HAmBA6XR8USSaN4SWPwaha5gYcADSBu2hfA7rWtVE9NN44m",
+		MaxInbound:  50,
+		MaxOutbound: 25,
 	},
 	RpcParam: &RpcParam{
 		RpcIp:      "127.0.0.1",
@@ -317,6 +389,7 @@ var MainNetParam = &Param{
 		CycleInterval:    CycleInterval,
 		SuperSize:        SuperSize,
 		DPosSize:         DPosSize,
+		ForkQuorum:       50,
 		GenesisTime:      1624083180,
 		GenesisCycle:     1624083180 / CycleInterval,
 		WorkProofAddress: "AiZ3V77E7S5jA8afLVrS3eDYoNFKXQYSRo1",
@@ -402,6 +475,13 @@ var MainNetParam = &Param{
 		MsgExpiredTime:     60 * 60 * 3,
 		MonitorMsgInterval: 10,
 		MaxAddressMsg:      1000,
+		PriorityMsgTypes:   priorityMsgTypes,
+		PriorityReserve:    10,
+		MaxPeerMsg:         1000,
+		PeerMsgWindow:      60,
+		MaxMsgFailures:     3,
+		InvalidMsgPenalty:  10,
+		ReplaceFeeBump:     10,
 	},
 }
 
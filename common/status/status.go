@@ -11,8 +11,12 @@ type IStatus interface {
 	SetConfirmed(confirmed uint64)
 	CheckMsg(msg types.IMessage, strict bool) error
 	Change(msgs []types.IMessage, block types.IBlock) error
+	Copy() (IStatus, error)
 	Account(address arry.Address) types.IAccount
 	Token(address arry.Address) (types.IToken, error)
+	Tokens(offset, limit uint64) ([]types.IToken, uint64)
+	TokenHolders(token arry.Address, offset, limit uint64) ([]types.IAccount, uint64)
+	RichList(token arry.Address, limit uint64) []types.IAccount
 	Candidates() types.ICandidates
 	CycleSupers(cycle uint64) types.ICandidates
 	CycleReword(cycle uint64) []types.IReword
@@ -5,6 +5,7 @@ import (
 	"github.com/aiot-network/aiotchain/tools/crypto/ecc/secp256k1"
 	log "github.com/aiot-network/aiotchain/tools/log/log15"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/pnet"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
 	"github.com/multiformats/go-multiaddr"
 	"strings"
@@ -42,13 +43,16 @@ func IsBootPeers(id peer.ID) bool {
 	return false
 }
 
-func NewBoot(port, external string, private *secp256k1.PrivateKey) (*P2p, error) {
-	host, err := NewP2PHost(private, port, external)
+// NewBoot creates the boot node, the boot node only serves the
+// private swarm if the pre-shared key is set
+func NewBoot(port, external string, private *secp256k1.PrivateKey, psk pnet.PSK, allow Allowlist) (*P2p, error) {
+	host, err := NewP2PHost(private, port, external, psk)
 	if err != nil {
 		return nil, err
 	}
-	p2p := &P2p{host: host}
-	log.Info("Host created", "id", p2p.host.ID(), "address", p2p.host.Addrs())
+	p2p := &P2p{host: host, allow: allow, private: psk != nil}
+	p2p.filterConns()
+	log.Info("Host created", "id", p2p.host.ID(), "address", p2p.host.Addrs(), "private", p2p.private)
 	return p2p, nil
 }
 
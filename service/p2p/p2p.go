@@ -16,6 +16,8 @@ import (
 	crypto2 "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/pnet"
 	"github.com/libp2p/go-libp2p-core/protocol"
 	discovery "github.com/libp2p/go-libp2p-discovery"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
@@ -25,7 +27,11 @@ import (
 	"time"
 )
 
-const module = "p2p"
+const (
+	module = "p2p"
+	// Interval of reconnecting the static peers
+	staticInterval = 60
+)
 
 type P2p struct {
 	host       core.Host
@@ -33,8 +39,13 @@ type P2p struct {
 	dht        *dht.IpfsDHT
 	peers      *peers.Peers
 	reqHandler request.IRequestHandler
-	close      chan bool
-	closed     chan bool
+	// Trusted peers that are always connected
+	statics []*peer.AddrInfo
+	// Peers allowed to connect in the private network
+	allow   Allowlist
+	private bool
+	close   chan bool
+	closed  chan bool
 }
 
 func NewP2p(ps *peers.Peers, reqHandler request.IRequestHandler) (*P2p, error) {
@@ -52,24 +63,59 @@ func NewP2p(ps *peers.Peers, reqHandler request.IRequestHandler) (*P2p, error) {
 		}
 		CustomBootPeers = append(CustomBootPeers, ma)
 	}
+	for _, addr := range config.Param.P2pParam.StaticPeers {
+		ma, err := multiaddr.NewMultiaddr(addr)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect static peer address %s, %s", addr, err)
+		}
+		addrInfo, err := peer.AddrInfoFromP2pAddr(ma)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect static peer address %s, %s", addr, err)
+		}
+		ser.statics = append(ser.statics, addrInfo)
+	}
+	psk, err := ReadSwarmKey(config.Param.P2pParam.SwarmKey)
+	if err != nil {
+		return nil, err
+	}
+	ser.private = psk != nil
+	if ser.allow, err = NewAllowlist(config.Param.P2pParam.AllowPeers); err != nil {
+		return nil, err
+	}
+	// The configured boot and static peers are trusted
+	for _, ma := range CustomBootPeers {
+		if addrInfo, err := peer.AddrInfoFromP2pAddr(ma); err == nil {
+			ser.allow.Add(addrInfo.ID)
+		}
+	}
+	for _, addrInfo := range ser.statics {
+		ser.allow.Add(addrInfo.ID)
+	}
 
 	host, err := NewP2PHost(config.Param.IPrivate.PrivateKey(),
-		config.Param.P2pPort, config.Param.ExternalIp)
+		config.Param.P2pPort, config.Param.ExternalIp, psk)
 	if err != nil {
 		return nil, err
 	}
 	ser.host = host
+	ser.filterConns()
+	for _, addrInfo := range ser.statics {
+		host.Peerstore().AddAddrs(addrInfo.ID, addrInfo.Addrs, peerstore.PermanentAddrTTL)
+	}
 	ser.local = types.NewPeer(config.Param.IPrivate.PrivateKey(),
 		&peer.AddrInfo{
 			ID:    host.ID(),
-			Addrs: host.Addrs()}, nil, nil)
+			Addrs: host.Addrs()}, nil)
 	ser.initP2pHandle()
 	ps.SetLocal(ser.local)
-	log.Info("P2p host created", "module", module, "id", host.ID(), "address", host.Addrs())
+	reqHandler.RegisterInboundPeer(ser.addInboundPeer)
+	log.Info("P2p host created", "module", module, "id", host.ID(), "address", host.Addrs(), "private", ser.private)
 	return ser, nil
 }
 
-func NewP2PHost(private *secp256k1.PrivateKey, port, external string) (core.Host, error) {
+// NewP2PHost creates the libp2p host, the host only connects
+// the peers holding the same key if the pre-shared key is set
+func NewP2PHost(private *secp256k1.PrivateKey, port, external string, psk pnet.PSK) (core.Host, error) {
 	ips := utils.GetLocalIp()
 	ips = append(ips, external)
 	f := newFactory(ips, port)
@@ -85,6 +131,9 @@ func NewP2PHost(private *secp256k1.PrivateKey, port, external string) (core.Host
 		libp2p.EnableRelay(),
 		libp2p.AddrsFactory(f),
 	}
+	if psk != nil {
+		opts = append(opts, libp2p.PrivateNetwork(psk))
+	}
 	return libp2p.New(context.Background(), opts...)
 }
 
@@ -111,7 +160,12 @@ func (p *P2p) Start() error {
 		return err
 	}
 
-	go p.peerDiscovery()
+	if p.private {
+		go p.privateDiscovery()
+	} else {
+		go p.peerDiscovery()
+	}
+	go p.staticPeers()
 	log.Info("P2P started successfully", "module", module)
 	return nil
 }
@@ -150,7 +204,17 @@ func (p *P2p) newStream(id peer.ID) (network.Stream, error) {
 }
 
 func (p *P2p) initP2pHandle() {
-	p.host.SetStreamHandler(protocol.ID(config.Param.P2pParam.NetWork), p.reqHandler.SendToReady)
+	p.host.SetStreamHandler(protocol.ID(config.Param.P2pParam.NetWork), p.handleStream)
+}
+
+// Streams of banned or not allowed peers are refused
+func (p *P2p) handleStream(stream network.Stream) {
+	remote := stream.Conn().RemotePeer()
+	if !p.allow.Allowed(remote) || p.peers.IsBanned(remote.String()) {
+		stream.Reset()
+		return
+	}
+	p.reqHandler.SendToReady(stream)
 }
 
 func (p *P2p) connectBootNode() error {
@@ -168,6 +232,9 @@ func (p *P2p) connectBootNode() error {
 	boots := DefaultBootPeers
 	if len(CustomBootPeers) > 0 {
 		boots = CustomBootPeers
+	} else if p.private {
+		// The public boot nodes are not in the private network
+		boots = nil
 	}
 	var wg sync.WaitGroup
 	for _, address := range boots {
@@ -219,14 +286,15 @@ func (p *P2p) readAddrInfo(addrCh <-chan peer.AddrInfo) {
 				if addrInfo.ID == p.local.Address.ID || IsBootPeers(addrInfo.ID) {
 					continue
 				}
+				if !p.allow.Allowed(addrInfo.ID) || p.peers.IsBanned(addrInfo.ID.String()) {
+					continue
+				}
+				// Stop dialing when the outbound connections are full
+				if !p.peers.HasRoom(addrInfo.ID.String(), false) {
+					continue
+				}
 				if !p.peers.AddressExist(&addrInfo) {
-					if stream, err := p.connect(addrInfo.ID); err != nil {
-						//log.Warn("connect failed", "addr", addrInfo.String())
-						p.peers.RemovePeer(addrInfo.ID.String())
-						continue
-					} else {
-						p.peers.AddPeer(types.NewPeer(nil, cpAddrInfo(&addrInfo), p.newStream, stream))
-					}
+					p.addPeer(types.NewPeer(nil, cpAddrInfo(&addrInfo), p.newStream))
 				}
 			} else {
 				return
@@ -235,14 +303,74 @@ func (p *P2p) readAddrInfo(addrCh <-chan peer.AddrInfo) {
 	}
 }
 
-func (p *P2p) connect(id peer.ID) (network.Stream, error) {
-	stream, err := p.newStream(id)
+// The peer is added only if it is connected and on the same chain,
+// peers that predate the handshake are added in legacy mode
+func (p *P2p) addPeer(peer *types.Peer) {
+	handshake, err := p.reqHandler.Handshake(peer.Conn)
 	if err != nil {
-		return nil, err
+		log.Warn("Handshake failed", "module", module, "id", peer.Address.ID.String(), "error", err)
+		p.peers.RemovePeer(peer.Address.ID.String())
+		return
+	}
+	if handshake.IsLegacy() {
+		log.Debug("No handshake answered, legacy mode", "module", module, "id", peer.Address.ID.String())
+	}
+	peer.Handshake = handshake
+	if err := p.peers.AddPeer(peer); err != nil {
+		log.Debug("Failed to add the peer", "module", module, "id", peer.Address.ID.String(), "error", err)
+	}
+}
+
+// Add the peer that handshakes with the local node. The remote address of
+// the connection is an ephemeral port, the peer is recorded with the listen
+// addresses it announced so that it can be dialed again.
+func (p *P2p) addInboundPeer(addr *peer.AddrInfo, handshake *types.Handshake) error {
+	if !p.allow.Allowed(addr.ID) {
+		return Err_NotAllowed
+	}
+	if p.peers.AddressExist(addr) {
+		return nil
+	}
+	addr = &peer.AddrInfo{ID: addr.ID, Addrs: p.host.Peerstore().Addrs(addr.ID)}
+	peer := types.NewPeer(nil, addr, p.newStream)
+	peer.Inbound = true
+	peer.Static = p.isStatic(addr.ID)
+	peer.Handshake = handshake
+	peer.Conn.Negotiate(handshake)
+	return p.peers.AddPeer(peer)
+}
+
+func (p *P2p) isStatic(id peer.ID) bool {
+	for _, addrInfo := range p.statics {
+		if addrInfo.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Connect the static peers that are not connected every minute
+func (p *P2p) staticPeers() {
+	if len(p.statics) == 0 {
+		return
+	}
+	t := time.NewTicker(time.Second * staticInterval)
+	defer t.Stop()
+	for {
+		for _, addrInfo := range p.statics {
+			if addrInfo.ID == p.local.Address.ID || p.peers.AddressExist(addrInfo) {
+				continue
+			}
+			peer := types.NewPeer(nil, cpAddrInfo(addrInfo), p.newStream)
+			peer.Static = true
+			p.addPeer(peer)
+		}
+		select {
+		case _, _ = <-p.close:
+			return
+		case _ = <-t.C:
+		}
 	}
-	stream.Reset()
-	stream.Close()
-	return stream, nil
 }
 
 func PrivateToP2pId(key private.IPrivate) (peer.ID, error) {
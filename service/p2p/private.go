@@ -0,0 +1,93 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	log "github.com/aiot-network/aiotchain/tools/log/log15"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/pnet"
+	"os"
+	"time"
+)
+
+var Err_NotAllowed = errors.New("the peer is not allowed in the private network")
+
+// Allowlist of the private network, all peers are allowed if it is empty
+type Allowlist map[peer.ID]bool
+
+// ReadSwarmKey reads the pre-shared key of the private network
+// from the libp2p swarm key file, nil is returned for an empty path
+func ReadSwarmKey(path string) (pnet.PSK, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the swarm key file %s, %s", path, err)
+	}
+	defer file.Close()
+	psk, err := pnet.DecodeV1PSK(file)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect swarm key file %s, %s", path, err)
+	}
+	return psk, nil
+}
+
+func NewAllowlist(ids []string) (Allowlist, error) {
+	allow := make(Allowlist)
+	for _, s := range ids {
+		id, err := peer.Decode(s)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect allowed peer id %s, %s", s, err)
+		}
+		allow[id] = true
+	}
+	return allow, nil
+}
+
+func (a Allowlist) Add(id peer.ID) {
+	if len(a) > 0 {
+		a[id] = true
+	}
+}
+
+func (a Allowlist) Allowed(id peer.ID) bool {
+	return len(a) == 0 || a[id]
+}
+
+// Close the connections of the peers that are not allowed
+func (p *P2p) filterConns() {
+	if len(p.allow) == 0 {
+		return
+	}
+	p.host.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(n network.Network, conn network.Conn) {
+			if !p.allow.Allowed(conn.RemotePeer()) {
+				log.Debug("Refuse the peer not allowed", "module", module, "id", conn.RemotePeer().String())
+				go conn.Close()
+			}
+		},
+	})
+}
+
+// In the private network the local node is not advertised, the
+// peers are found from the addresses learned in the private swarm
+func (p *P2p) privateDiscovery() {
+	for {
+		select {
+		case _, _ = <-p.close:
+			return
+		default:
+			addrCh := make(chan peer.AddrInfo)
+			go func() {
+				defer close(addrCh)
+				for _, id := range p.host.Peerstore().PeersWithAddrs() {
+					addrCh <- p.host.Peerstore().PeerInfo(id)
+				}
+			}()
+			p.readAddrInfo(addrCh)
+		}
+		time.Sleep(time.Second * 8)
+	}
+}
@@ -0,0 +1,137 @@
+package p2p
+
+import (
+	"context"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/test"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadSwarmKey(t *testing.T) {
+	key := strings.Repeat("0123456789abcdef", 4)
+	tests := []struct {
+		name    string
+		content string
+		psk     bool
+		fails   bool
+	}{
+		{"base16 key", "/key/swarm/psk/1.0.0/\n/base16/\n" + key, true, false},
+		{"bad header", "/key/swarm/psk/2.0.0/\n/base16/\n" + key, false, true},
+		{"bad encoding", "/key/swarm/psk/1.0.0/\n/base32/\n" + key, false, true},
+		{"bad length", "/key/swarm/psk/1.0.0/\n/base16/\n" + key[:32], false, true},
+	}
+	dir := t.TempDir()
+	for i, test := range tests {
+		path := filepath.Join(dir, string(rune('a'+i)))
+		if err := ioutil.WriteFile(path, []byte(test.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		psk, err := ReadSwarmKey(path)
+		if (err != nil) != test.fails {
+			t.Fatalf("%s: expect failure %v, got %v", test.name, test.fails, err)
+		}
+		if (len(psk) == 32) != test.psk {
+			t.Fatalf("%s: expect a key %v, got %x", test.name, test.psk, psk)
+		}
+	}
+	if psk, err := ReadSwarmKey(""); psk != nil || err != nil {
+		t.Fatalf("no key should be read without a path, got %x %v", psk, err)
+	}
+	if _, err := ReadSwarmKey(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("a missing file should fail")
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	allowed, other, added := test.RandPeerIDFatal(t), test.RandPeerIDFatal(t), test.RandPeerIDFatal(t)
+	if _, err := NewAllowlist([]string{"not a peer id"}); err == nil {
+		t.Fatal("an incorrect peer id should fail")
+	}
+	empty, err := NewAllowlist(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allow, err := NewAllowlist([]string{allowed.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Adding to an empty allowlist does not restrict it
+	empty.Add(added)
+	allow.Add(added)
+	tests := []struct {
+		name    string
+		allow   Allowlist
+		id      peer.ID
+		allowed bool
+	}{
+		{"empty allows all", empty, other, true},
+		{"empty stays empty", empty, added, true},
+		{"listed", allow, allowed, true},
+		{"added", allow, added, true},
+		{"not listed", allow, other, false},
+	}
+	for _, test := range tests {
+		if allowed := test.allow.Allowed(test.id); allowed != test.allowed {
+			t.Errorf("%s: expect allowed %v, got %v", test.name, test.allowed, allowed)
+		}
+	}
+	if len(empty) != 0 {
+		t.Errorf("the empty allowlist should stay empty, got %d peers", len(empty))
+	}
+}
+
+func newTestHost(t *testing.T) host.Host {
+	h, err := libp2p.New(context.Background(), libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+// Wait until the connectedness of the host to the peer is settled
+func connected(h host.Host, id peer.ID, expect bool) bool {
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		is := h.Network().Connectedness(id) == network.Connected
+		if is == expect || time.Now().After(deadline) {
+			return is
+		}
+		time.Sleep(time.Millisecond * 50)
+	}
+}
+
+func TestP2p_FilterConns(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow func(allowed peer.ID) Allowlist
+		other bool
+	}{
+		{"empty allowlist", func(allowed peer.ID) Allowlist { return Allowlist{} }, true},
+		{"allowlist", func(allowed peer.ID) Allowlist { return Allowlist{allowed: true} }, false},
+	}
+	for _, test := range tests {
+		local, allowed, other := newTestHost(t), newTestHost(t), newTestHost(t)
+		p := &P2p{host: local, allow: test.allow(allowed.ID())}
+		p.filterConns()
+		for _, h := range []host.Host{allowed, other} {
+			// The connection may be closed by the local host during the dial
+			h.Connect(context.Background(), peer.AddrInfo{ID: local.ID(), Addrs: local.Addrs()})
+		}
+		if !connected(local, allowed.ID(), true) {
+			t.Errorf("%s: the allowed peer should stay connected", test.name)
+		}
+		if is := connected(local, other.ID(), test.other); is != test.other {
+			t.Errorf("%s: the other peer should be connected %v, got %v", test.name, test.other, is)
+		}
+		for _, h := range []host.Host{local, allowed, other} {
+			h.Close()
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package peers
+
+import "github.com/aiot-network/aiotchain/types"
+
+type IBanDB interface {
+	Read() []*types.Ban
+	Save(ban *types.Ban)
+	Delete(peerId string)
+	Close() error
+}
@@ -1,48 +1,84 @@
 package peers
 
 import (
+	"errors"
+	"fmt"
+	"github.com/aiot-network/aiotchain/common/config"
 	request2 "github.com/aiot-network/aiotchain/service/request"
 	log "github.com/aiot-network/aiotchain/tools/log/log15"
+	"github.com/aiot-network/aiotchain/tools/utils"
 	"github.com/aiot-network/aiotchain/types"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"math/rand"
+	"sort"
 	"sync"
 	"time"
 )
 
 const (
 	module             = "peers"
-	maxPeers           = 1000000
 	monitoringInterval = 60 * 30
+	// Interval of reloading the supers of the current cycle
+	supersInterval = 60
+	// Peers with a score not higher than this are banned temporarily
+	minScore = -100
+	// Seconds of the temporary ban of peers with a low score
+	scoreBanTime = 60 * 60 * 6
+)
+
+var (
+	Err_Banned      = errors.New("peer is banned")
+	Err_PeerSetFull = errors.New("too many peers")
 )
 
 type Peers struct {
 	local      *types.Peer
 	cache      map[string]*types.Peer
 	remove     map[string]*types.Peer
+	scores     map[string]int
+	bans       map[string]*types.Ban
+	banDB      IBanDB
 	idList     []string
 	rwm        sync.RWMutex
 	close      chan bool
 	peerInfo   map[string]*types.Local
 	infoWm     sync.RWMutex
 	reqHandler request2.IRequestHandler
+	// P2p ids of the supers of the current cycle, they are preferred when the peer set is full
+	supers   map[string]bool
+	superIds func() []string
 }
 
-func NewPeers(reqHandler request2.IRequestHandler) *Peers {
-	return &Peers{
-		cache:      make(map[string]*types.Peer, maxPeers),
-		remove:     make(map[string]*types.Peer, maxPeers),
+func NewPeers(reqHandler request2.IRequestHandler, banDB IBanDB) *Peers {
+	p := &Peers{
+		cache:      make(map[string]*types.Peer),
+		remove:     make(map[string]*types.Peer),
+		scores:     make(map[string]int),
+		bans:       make(map[string]*types.Ban),
+		banDB:      banDB,
 		close:      make(chan bool),
 		peerInfo:   make(map[string]*types.Local),
 		reqHandler: reqHandler,
+		supers:     make(map[string]bool),
 	}
+	for _, ban := range banDB.Read() {
+		p.bans[ban.PeerId] = ban
+	}
+	p.removeExpiredBans()
+	return p
 }
 
 func (p *Peers) Name() string {
 	return module
 }
 
+// Register the function that returns the p2p ids of the current supers
+func (p *Peers) RegisterSuperIds(f func() []string) {
+	p.superIds = f
+}
+
 func (p *Peers) Start() error {
+	p.updateSupers()
 	log.Info("Peers started successfully", "module", module)
 	go p.monitoring()
 	go p.peerLocal()
@@ -50,13 +86,24 @@ func (p *Peers) Start() error {
 }
 
 func (p *Peers) Stop() error {
+	if err := p.banDB.Close(); err != nil {
+		return err
+	}
 	log.Info("Peers was stopped", "module", module)
 	return nil
 }
 
 func (p *Peers) Info() map[string]interface{} {
+	p.rwm.RLock()
+	inbound, outbound := p.count(true), p.count(false)
+	banned := len(p.bans)
+	p.rwm.RUnlock()
+
 	return map[string]interface{}{
 		"connections": p.Count(),
+		"inbound":     inbound,
+		"outbound":    outbound,
+		"banned":      banned,
 	}
 }
 
@@ -70,30 +117,103 @@ func (p *Peers) AddressExist(address *peer.AddrInfo) bool {
 	return true
 }
 
-func (p *Peers) AddPeer(peer *types.Peer) {
+// Add the peer to the peer set. If the connections of its direction are
+// full, the worst scoring peer is evicted for it when the new peer is a
+// super of the current cycle or has a better score. Static peers are not
+// limited and never evicted.
+func (p *Peers) AddPeer(peer *types.Peer) error {
 	p.rwm.Lock()
 	defer p.rwm.Unlock()
 
-	if len(p.cache) >= maxPeers {
-		return
+	id := peer.Address.ID.String()
+	if ban, ok := p.bans[id]; ok && !ban.IsExpired(uint64(utils.NowUnix())) {
+		return Err_Banned
+	}
+	if _, ok := p.cache[id]; !ok {
+		evict, err := p.admit(id, peer.Inbound, peer.Static)
+		if err != nil {
+			return err
+		}
+		if evict != "" {
+			log.Info("Evict a peer", "module", module, "id", evict, "score", p.scores[evict])
+			p.removePeer(evict)
+		}
+		p.idList = append(p.idList, id)
 	}
 	if peer.Speed == 0 {
 		peer.Speed = 500
 	}
-	p.cache[peer.Address.ID.String()] = peer
-	p.idList = append(p.idList, peer.Address.ID.String())
-	log.Info("Add a peer", "module", module, "id", peer.Address.ID.String(), "address", peer.Address.String())
+	p.cache[id] = peer
+	delete(p.remove, id)
+	log.Info("Add a peer", "module", module, "id", id, "address", peer.Address.String(), "inbound", peer.Inbound)
+	return nil
+}
+
+// Whether a new peer of the direction would be accepted
+func (p *Peers) HasRoom(id string, inbound bool) bool {
+	p.rwm.RLock()
+	defer p.rwm.RUnlock()
+
+	if _, ok := p.cache[id]; ok {
+		return true
+	}
+	_, err := p.admit(id, inbound, false)
+	return err == nil
+}
+
+// Check whether the peer can be added, returns the peer to be evicted for it
+func (p *Peers) admit(id string, inbound, static bool) (string, error) {
+	if static || p.count(inbound) < p.limit(inbound) {
+		return "", nil
+	}
+	worst := ""
+	for _, peer := range p.cache {
+		peerId := peer.Address.ID.String()
+		if peer.Inbound != inbound || peer.Static || p.supers[peerId] {
+			continue
+		}
+		if worst == "" || p.scores[peerId] < p.scores[worst] {
+			worst = peerId
+		}
+	}
+	if worst != "" && (p.supers[id] || p.scores[worst] < p.scores[id]) {
+		return worst, nil
+	}
+	return "", Err_PeerSetFull
+}
+
+// Number of the peers of the direction, static peers are not counted
+func (p *Peers) count(inbound bool) int {
+	count := 0
+	for _, peer := range p.cache {
+		if peer.Inbound == inbound && !peer.Static {
+			count++
+		}
+	}
+	return count
+}
+
+func (p *Peers) limit(inbound bool) int {
+	if inbound {
+		return config.Param.P2pParam.MaxInbound
+	}
+	return config.Param.P2pParam.MaxOutbound
 }
 
 func (p *Peers) RemovePeer(reId string) {
 	p.rwm.Lock()
 	defer p.rwm.Unlock()
 
+	p.removePeer(reId)
+}
+
+func (p *Peers) removePeer(reId string) {
 	for index, id := range p.idList {
 		if id == reId {
 			p.idList = append(p.idList[0:index], p.idList[index+1:]...)
 			if peer, ok := p.cache[reId]; ok {
 				delete(p.cache, reId)
+				p.reqHandler.Disconnect(peer.Conn)
 				p.remove[reId] = peer
 				log.Info("Delete a peer", "id", reId)
 			}
@@ -102,6 +222,129 @@ func (p *Peers) RemovePeer(reId string) {
 	}
 }
 
+func (p *Peers) IsStatic(id string) bool {
+	p.rwm.RLock()
+	defer p.rwm.RUnlock()
+
+	peer, ok := p.cache[id]
+	return ok && peer.Static
+}
+
+func (p *Peers) updateSupers() {
+	if p.superIds == nil {
+		return
+	}
+	supers := make(map[string]bool)
+	for _, id := range p.superIds() {
+		supers[id] = true
+	}
+	p.rwm.Lock()
+	p.supers = supers
+	p.rwm.Unlock()
+}
+
+// Lower the score of the peer, the peer is banned temporarily when
+// its score drops to the minimum. Scores recover over time. Static
+// peers are trusted and not banned by their score.
+func (p *Peers) Penalize(id string, score int) {
+	p.rwm.Lock()
+	p.scores[id] -= score
+	current := p.scores[id]
+	p.rwm.Unlock()
+
+	log.Warn("Penalize a peer", "module", module, "id", id, "score", current)
+	if current <= minScore && !p.IsStatic(id) {
+		p.Ban(id, fmt.Sprintf("score %d", current), scoreBanTime)
+	}
+}
+
+// Ban the peer for the seconds, the ban is permanent if the seconds are 0.
+// Banned peers are removed and their connections are refused.
+func (p *Peers) Ban(id, reason string, seconds uint64) *types.Ban {
+	ban := &types.Ban{PeerId: id, Reason: reason}
+	if seconds != 0 {
+		ban.Until = uint64(utils.NowUnix()) + seconds
+	}
+	p.rwm.Lock()
+	p.bans[id] = ban
+	delete(p.scores, id)
+	p.rwm.Unlock()
+
+	p.banDB.Save(ban)
+	p.RemovePeer(id)
+	log.Warn("Ban a peer", "module", module, "id", id, "reason", reason, "until", ban.Until)
+	return ban
+}
+
+func (p *Peers) Unban(id string) bool {
+	p.rwm.Lock()
+	_, ok := p.bans[id]
+	delete(p.bans, id)
+	p.rwm.Unlock()
+
+	if ok {
+		p.banDB.Delete(id)
+		log.Info("Unban a peer", "module", module, "id", id)
+	}
+	return ok
+}
+
+func (p *Peers) IsBanned(id string) bool {
+	p.rwm.RLock()
+	defer p.rwm.RUnlock()
+
+	ban, ok := p.bans[id]
+	return ok && !ban.IsExpired(uint64(utils.NowUnix()))
+}
+
+func (p *Peers) Bans() []*types.Ban {
+	p.rwm.RLock()
+	defer p.rwm.RUnlock()
+
+	bans := make([]*types.Ban, 0, len(p.bans))
+	for _, ban := range p.bans {
+		bans = append(bans, ban)
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].PeerId < bans[j].PeerId
+	})
+	return bans
+}
+
+func (p *Peers) removeExpiredBans() {
+	now := uint64(utils.NowUnix())
+	p.rwm.Lock()
+	defer p.rwm.Unlock()
+
+	for id, ban := range p.bans {
+		if ban.IsExpired(now) {
+			delete(p.bans, id)
+			p.banDB.Delete(id)
+		}
+	}
+}
+
+func (p *Peers) Score(id string) int {
+	p.rwm.RLock()
+	defer p.rwm.RUnlock()
+
+	return p.scores[id]
+}
+
+// Halve the penalties so that peers can be reconnected later
+func (p *Peers) recoverScores() {
+	p.rwm.Lock()
+	defer p.rwm.Unlock()
+
+	for id, score := range p.scores {
+		if score/2 == 0 {
+			delete(p.scores, id)
+		} else {
+			p.scores[id] = score / 2
+		}
+	}
+}
+
 func (p *Peers) SetSpeed(id string, speed uint64) {
 	p.rwm.Lock()
 	defer p.rwm.Unlock()
@@ -114,23 +357,31 @@ func (p *Peers) SetSpeed(id string, speed uint64) {
 func (p *Peers) monitoring() {
 	t := time.NewTicker(time.Second * monitoringInterval)
 	defer t.Stop()
+	supersT := time.NewTicker(time.Second * supersInterval)
+	defer supersT.Stop()
 	for {
 		select {
+		case _ = <-supersT.C:
+			p.updateSupers()
 		case _ = <-t.C:
-			for id, peer := range p.remove {
-				if id != p.local.Address.ID.String() {
+			// The peers are iterated on copies, the maps are changed
+			// by the peers that are added and removed meanwhile
+			for id, peer := range p.removedMap() {
+				if id != p.local.Address.ID.String() && !p.IsBanned(id) {
 					if p.isAlive(peer) && !p.AddressExist(peer.Address) {
 						p.AddPeer(peer)
 					}
 				}
 			}
-			for id, peer := range p.cache {
+			for id, peer := range p.PeersMap() {
 				if id != p.local.Address.ID.String() {
 					if !p.isAlive(peer) {
 						p.RemovePeer(id)
 					}
 				}
 			}
+			p.recoverScores()
+			p.removeExpiredBans()
 		}
 	}
 }
@@ -162,13 +413,7 @@ func (p *Peers) requestPeerLocal() {
 }
 
 func (p *Peers) isAlive(peer *types.Peer) bool {
-	stream, err := peer.Conn.Create(peer.Address.ID)
-	if err != nil {
-		return false
-	}
-	stream.Reset()
-	stream.Close()
-	return true
+	return p.reqHandler.IsAlive(peer.Conn)
 }
 
 func (p *Peers) RandomPeer() *types.Peer {
@@ -202,6 +447,18 @@ func (p *Peers) PeersMap() map[string]*types.Peer {
 	return re
 }
 
+// The removed peers that are reconnected when they are alive
+func (p *Peers) removedMap() map[string]*types.Peer {
+	p.rwm.RLock()
+	defer p.rwm.RUnlock()
+
+	re := make(map[string]*types.Peer)
+	for key, value := range p.remove {
+		re[key] = value
+	}
+	return re
+}
+
 func (p *Peers) PeersInfo() []*types.Local {
 	p.infoWm.RLock()
 	defer p.infoWm.RUnlock()
@@ -0,0 +1,194 @@
+package peers
+
+import (
+	"fmt"
+	"github.com/aiot-network/aiotchain/chain/db/ban_db"
+	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/common/param"
+	"github.com/aiot-network/aiotchain/service/request"
+	"github.com/aiot-network/aiotchain/tools/utils"
+	"github.com/aiot-network/aiotchain/types"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"testing"
+)
+
+type testRequester struct {
+	request.IRequestHandler
+}
+
+func (r *testRequester) Disconnect(conn *types.Conn) {}
+
+func openBanDB(t *testing.T, path string) *ban_db.BanDB {
+	db, err := ban_db.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newTestPeer(i int, inbound bool) *types.Peer {
+	id := peer.ID(fmt.Sprintf("peer%d", i))
+	return &types.Peer{
+		Address: &peer.AddrInfo{ID: id},
+		Conn:    &types.Conn{PeerId: id},
+		Inbound: inbound,
+	}
+}
+
+func TestPeers_ScoreBan(t *testing.T) {
+	config.Param = param.TestNetParam
+	db := openBanDB(t, t.TempDir())
+	defer db.Close()
+	p := NewPeers(&testRequester{}, db)
+
+	normal := newTestPeer(0, false)
+	static := newTestPeer(1, false)
+	static.Static = true
+	for _, peer := range []*types.Peer{normal, static} {
+		if err := p.AddPeer(peer); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		penalty int
+		score   int
+		banned  bool
+	}{
+		{"first penalty", 50, -50, false},
+		{"above the minimum", 49, -99, false},
+		{"at the minimum", 1, 0, true},
+	}
+	id := normal.Address.ID.String()
+	for _, test := range tests {
+		p.Penalize(id, test.penalty)
+		if score := p.Score(id); score != test.score {
+			t.Fatalf("%s: the score should be %d, got %d", test.name, test.score, score)
+		}
+		if banned := p.IsBanned(id); banned != test.banned {
+			t.Fatalf("%s: banned should be %v", test.name, test.banned)
+		}
+	}
+	if p.Peer(id) != nil {
+		t.Fatalf("the banned peer should be removed")
+	}
+	if err := p.AddPeer(normal); err != Err_Banned {
+		t.Fatalf("the banned peer should be refused, got %v", err)
+	}
+	bans := p.Bans()
+	now := uint64(utils.NowUnix())
+	if len(bans) != 1 || bans[0].Until < now+scoreBanTime-1 || bans[0].Until > now+scoreBanTime {
+		t.Fatalf("the peer should be banned for %d seconds, got %v", scoreBanTime, bans)
+	}
+
+	staticId := static.Address.ID.String()
+	p.Penalize(staticId, -minScore)
+	if p.IsBanned(staticId) || p.Peer(staticId) == nil {
+		t.Fatalf("the static peer should not be banned by its score")
+	}
+}
+
+func TestPeers_BanPersistence(t *testing.T) {
+	config.Param = param.TestNetParam
+	path := t.TempDir()
+	db := openBanDB(t, path)
+	p := NewPeers(&testRequester{}, db)
+	p.Ban("permanent", "test", 0)
+	p.Ban("temporary", "test", scoreBanTime)
+	p.Ban("unbanned", "test", 0)
+	p.Unban("unbanned")
+	db.Close()
+
+	// The bans are restored after a restart
+	db = openBanDB(t, path)
+	p = NewPeers(&testRequester{}, db)
+	for _, id := range []string{"permanent", "temporary"} {
+		if !p.IsBanned(id) {
+			t.Fatalf("the ban of %s should be restored", id)
+		}
+	}
+	if p.IsBanned("unbanned") {
+		t.Fatalf("the removed ban should not be restored")
+	}
+
+	// The temporary ban expires
+	for _, ban := range p.Bans() {
+		if ban.PeerId == "temporary" {
+			ban.Until = uint64(utils.NowUnix()) - 1
+		}
+	}
+	if p.IsBanned("temporary") {
+		t.Fatalf("the expired ban should not be effective")
+	}
+	p.removeExpiredBans()
+	db.Close()
+
+	db = openBanDB(t, path)
+	defer db.Close()
+	bans := db.Read()
+	if len(bans) != 1 || bans[0].PeerId != "permanent" || bans[0].Until != 0 {
+		t.Fatalf("only the permanent ban should be kept, got %v", bans)
+	}
+}
+
+func TestPeers_Admit(t *testing.T) {
+	testParam := *param.TestNetParam
+	p2pParam := *testParam.P2pParam
+	p2pParam.MaxInbound = 2
+	p2pParam.MaxOutbound = 1
+	testParam.P2pParam = &p2pParam
+	config.Param = &testParam
+	defer func() { config.Param = param.TestNetParam }()
+
+	db := openBanDB(t, t.TempDir())
+	defer db.Close()
+	p := NewPeers(&testRequester{}, db)
+	peers := make([]*types.Peer, 9)
+	for i := range peers {
+		peers[i] = newTestPeer(i, i != 2 && i != 3)
+	}
+	peers[7].Static = true
+	id := func(i int) string { return peers[i].Address.ID.String() }
+	p.RegisterSuperIds(func() []string { return []string{id(5)} })
+	p.updateSupers()
+
+	tests := []struct {
+		name    string
+		prepare func()
+		add     int
+		err     error
+		evicted int
+	}{
+		{"first inbound", nil, 0, nil, -1},
+		{"second inbound", nil, 1, nil, -1},
+		{"first outbound", nil, 2, nil, -1},
+		{"outbound over the limit", nil, 3, Err_PeerSetFull, -1},
+		{"inbound with the same score", nil, 4, Err_PeerSetFull, -1},
+		{"inbound with a better score", func() { p.Penalize(id(0), 10) }, 4, nil, 0},
+		{"super of the cycle", func() { p.Penalize(id(1), 5) }, 5, nil, 1},
+		{"supers are not evicted", func() { p.Penalize(id(5), 50); p.Penalize(id(6), -1) }, 6, nil, 4},
+		{"static over the limit", nil, 7, nil, -1},
+		{"statics are not evicted", func() { p.Penalize(id(7), 50); p.Penalize(id(8), -5) }, 8, nil, 6},
+	}
+	for _, test := range tests {
+		if test.prepare != nil {
+			test.prepare()
+		}
+		if err := p.AddPeer(peers[test.add]); err != test.err {
+			t.Fatalf("%s: expect error %v, got %v", test.name, test.err, err)
+		}
+		if test.evicted >= 0 && p.Peer(id(test.evicted)) != nil {
+			t.Fatalf("%s: peer %d should be evicted", test.name, test.evicted)
+		}
+		if test.err == nil && p.Peer(id(test.add)) == nil {
+			t.Fatalf("%s: peer %d should be added", test.name, test.add)
+		}
+	}
+	if p.count(true) != 2 || p.count(false) != 1 {
+		t.Fatalf("the limits should be kept, got %d inbound and %d outbound", p.count(true), p.count(false))
+	}
+	if p.HasRoom(id(3), false) {
+		t.Fatalf("there should be no room for the outbound peer")
+	}
+}
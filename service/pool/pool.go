@@ -1,7 +1,7 @@
 package pool
 
 import (
-	"fmt"
+	chaintypes "github.com/aiot-network/aiotchain/chain/types"
 	"github.com/aiot-network/aiotchain/common/config"
 	"github.com/aiot-network/aiotchain/common/horn"
 	"github.com/aiot-network/aiotchain/common/msglist"
@@ -9,17 +9,46 @@ import (
 	log "github.com/aiot-network/aiotchain/tools/log/log15"
 	"github.com/aiot-network/aiotchain/tools/utils"
 	"github.com/aiot-network/aiotchain/types"
+	"sync"
 	"time"
 )
 
 const module = "pool"
 
+// Errors that an honest peer may also run into, such as relaying a message
+// that is already packaged, they are not counted as failures
+var harmlessErrs = map[chaintypes.ErrCode]bool{
+	chaintypes.ErrInsufficientBalance: true,
+	chaintypes.ErrNonceTooLow:         true,
+	chaintypes.ErrNonceTooHigh:        true,
+	chaintypes.ErrFeeTooLow:           true,
+	chaintypes.ErrMsgExists:           true,
+	chaintypes.ErrPoolFull:            true,
+	chaintypes.ErrAddressLimit:        true,
+	chaintypes.ErrPeerLimit:           true,
+}
+
+type peerQuota struct {
+	count int
+	start int64
+}
+
+type msgFailure struct {
+	count int
+	time  int64
+}
+
 type Pool struct {
 	msgMgt      msglist.IMsgList
 	horn        *horn.Horn
 	broadcastCh chan types.IMessage
 	deleteMsg   chan types.IMessage
 	close       chan bool
+	penalize    func(id string, score int)
+	peerQuotas  map[string]*peerQuota
+	failures    map[string]*msgFailure
+	rejections  map[string]uint64
+	mutex       sync.Mutex
 }
 
 func NewPool(horn *horn.Horn, msgMgt msglist.IMsgList) *Pool {
@@ -29,6 +58,9 @@ func NewPool(horn *horn.Horn, msgMgt msglist.IMsgList) *Pool {
 		broadcastCh: make(chan types.IMessage, 100),
 		deleteMsg:   make(chan types.IMessage, 10000),
 		close:       make(chan bool),
+		peerQuotas:  make(map[string]*peerQuota),
+		failures:    make(map[string]*msgFailure),
+		rejections:  make(map[string]uint64),
 	}
 	return pool
 }
@@ -44,7 +76,7 @@ func (p *Pool) Start() error {
 	}
 	go p.monitorExpired()
 	go p.startChan()
-	log.Info("Pool started successfully", "module", module)
+	log.Info("Pool started successfully", "module", module, "messages", p.msgMgt.Count())
 	return nil
 }
 
@@ -59,16 +91,37 @@ func (p *Pool) Stop() error {
 }
 
 func (p *Pool) Info() map[string]interface{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	rejections := make(map[string]uint64, len(p.rejections))
+	for reason, count := range p.rejections {
+		rejections[reason] = count
+	}
 	return map[string]interface{}{
-		"messages": p.msgMgt.Count(),
+		"messages":   p.msgMgt.Count(),
+		"rejections": rejections,
 	}
 }
 
+// Register the function that lowers the score of the peer
+func (p *Pool) RegisterPeerPenalty(f func(id string, score int)) {
+	p.penalize = f
+}
+
+// The number of messages in the pool and the capacity of the pool
+func (p *Pool) Occupancy() (int, int) {
+	return p.msgMgt.Count(), p.msgMgt.Capacity()
+}
+
 // Verify adding messages to the message pool
 func (p *Pool) Put(msg types.IMessage, isPeer bool) error {
+	if err := p.checkFailures(msg); err != nil {
+		return p.reject(msg, err)
+	}
 	if err := p.msgMgt.Put(msg); err != nil {
 		log.Warn("Received the message", "module", module, "error", err.Error())
-		return utils.Error(fmt.Sprintf("add message failed, %s", err.Error()), module)
+		return p.reject(msg, utils.WrapError(err, "add message failed", module))
 	}
 	log.Info("Received the message", "module", module, "hash", msg.Hash().String())
 	if !isPeer {
@@ -99,8 +152,85 @@ func (p *Pool) GetMessage(hash hasharry.Hash) (types.IMessage, bool) {
 	return p.msgMgt.Get(hash.String())
 }
 
-func (p *Pool) ReceiveMsgFromPeer(msg types.IMessage) error {
-	return p.Put(msg, true)
+// The ready and future messages of the address
+func (p *Pool) GetByAddress(addr hasharry.Address) ([]types.IMessage, []types.IMessage) {
+	return p.msgMgt.GetByAddress(addr.String())
+}
+
+func (p *Pool) ReceiveMsgFromPeer(msg types.IMessage, peerId string) error {
+	if err := p.checkPeerQuota(peerId); err != nil {
+		return p.reject(msg, err)
+	}
+	err := p.Put(msg, true)
+	if err != nil && !isHarmless(err) && p.penalize != nil {
+		log.Warn("The peer relayed an invalid message", "module", module, "peer", peerId,
+			"hash", msg.Hash().String(), "error", err)
+		p.penalize(peerId, config.Param.InvalidMsgPenalty)
+	}
+	return err
+}
+
+// Limit the number of messages accepted from one peer in a time window
+func (p *Pool) checkPeerQuota(peerId string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := utils.NowUnix()
+	quota, ok := p.peerQuotas[peerId]
+	if !ok || now-quota.start >= config.Param.PeerMsgWindow {
+		quota = &peerQuota{start: now}
+		p.peerQuotas[peerId] = quota
+	}
+	if quota.count >= config.Param.MaxPeerMsg {
+		return chaintypes.NewMsgError(chaintypes.ErrPeerLimit,
+			chaintypes.ErrDetails{"peer": peerId, "limit": config.Param.MaxPeerMsg},
+			"peer %s relayed more than %d messages in %d seconds", peerId, config.Param.MaxPeerMsg, config.Param.PeerMsgWindow)
+	}
+	quota.count++
+	return nil
+}
+
+// Messages that have failed verification repeatedly are rejected without verifying
+func (p *Pool) checkFailures(msg types.IMessage) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if failure, ok := p.failures[msg.Hash().String()]; ok && failure.count >= config.Param.MaxMsgFailures {
+		return chaintypes.NewMsgError(chaintypes.ErrMsgRejected,
+			chaintypes.ErrDetails{"hash": msg.Hash().String(), "failures": failure.count},
+			"the message %s failed verification %d times", msg.Hash().String(), failure.count)
+	}
+	return nil
+}
+
+// Count the rejected message by reason, invalid messages are recorded as failures
+func (p *Pool) reject(msg types.IMessage, err error) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	reason := "unknown"
+	if msgErr, ok := chaintypes.AsMsgError(err); ok {
+		reason = msgErr.Code.String()
+	}
+	p.rejections[reason]++
+	if !isHarmless(err) {
+		hash := msg.Hash().String()
+		failure, ok := p.failures[hash]
+		if !ok {
+			failure = &msgFailure{}
+			p.failures[hash] = failure
+		}
+		failure.count++
+		failure.time = utils.NowUnix()
+	}
+	return err
+}
+
+func isHarmless(err error) bool {
+	if msgErr, ok := chaintypes.AsMsgError(err); ok {
+		return harmlessErrs[msgErr.Code]
+	}
+	return false
 }
 
 func (p *Pool) monitorExpired() {
@@ -119,6 +249,21 @@ func (p *Pool) monitorExpired() {
 func (p *Pool) removeExpired() {
 	threshold := utils.NowUnix() - config.Param.MsgExpiredTime
 	p.msgMgt.DeleteExpired(threshold)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for hash, failure := range p.failures {
+		if failure.time <= threshold {
+			delete(p.failures, hash)
+		}
+	}
+	now := utils.NowUnix()
+	for id, quota := range p.peerQuotas {
+		if now-quota.start >= config.Param.PeerMsgWindow {
+			delete(p.peerQuotas, id)
+		}
+	}
 }
 
 func (p *Pool) dealStagnant() {
@@ -130,10 +275,25 @@ func (p *Pool) dealStagnant() {
 	}
 }
 
+// Put the messages of the rolled back blocks back into the pool,
+// all messages of the pool are verified again
+func (p *Pool) Recover(msgs []types.IMessage) {
+	p.msgMgt.Recover(msgs)
+	log.Info("Recover the message pool", "module", module, "rollback", len(msgs), "messages", p.msgMgt.Count())
+}
+
 func (p *Pool) Delete(msg types.IMessage) {
 	p.deleteMsg <- msg
 }
 
+// All messages of the pool, ready and future
+func (p *Pool) Messages() []types.IMessage {
+	ready, future := p.msgMgt.GetAll()
+	msgs := make([]types.IMessage, 0, len(ready)+len(future))
+	msgs = append(msgs, ready...)
+	return append(msgs, future...)
+}
+
 // Get all transactions in the trading pool
 func (p *Pool) All() ([]types.IMessage, []types.IMessage) {
 	prepareTxs, futureTxs := p.msgMgt.GetAll()
@@ -3,13 +3,27 @@ package request
 import (
 	"errors"
 	"github.com/aiot-network/aiotchain/server"
+	"github.com/aiot-network/aiotchain/tools/arry"
 	"github.com/aiot-network/aiotchain/types"
 	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
 )
 
 var (
 	Err_BlockNotFound = errors.New("block not exist")
+	Err_MsgNotFound   = errors.New("message not exist")
 	Err_PeerClosed    = errors.New("peer has closed")
+	Err_Protocol      = errors.New("unsupported protocol version")
+	Err_Network       = errors.New("different network")
+	Err_Genesis       = errors.New("different genesis block")
+	Err_NoHandshake   = errors.New("no handshake")
+)
+
+// Score penalties of peer misbehaviors
+const (
+	PenaltyTimeout      = 5
+	PenaltyProtocol     = 20
+	PenaltyInvalidBlock = 50
 )
 
 type IRequestHandler interface {
@@ -27,11 +41,25 @@ type ISend interface {
 	GetBlock(conn *types.Conn, height uint64) (types.IBlock, error)
 	IsEqual(conn *types.Conn, header types.IHeader) (bool, error)
 	LocalInfo(conn *types.Conn) (*types.Local, error)
+	Handshake(conn *types.Conn) (*types.Handshake, error)
+	IsAlive(conn *types.Conn) bool
+	Disconnect(conn *types.Conn)
+	Announce(conn *types.Conn, invs []*types.Inventory) error
+	GetMessage(conn *types.Conn, hash arry.Hash) (types.IMessage, error)
+	GetBlockByHash(conn *types.Conn, hash arry.Hash) (types.IBlock, error)
+	SendCompactBlock(conn *types.Conn, block types.IBlock) error
+	GetBlockMsgs(conn *types.Conn, hash arry.Hash, indexes []uint32) ([]types.IMessage, error)
+	GetHeaders(conn *types.Conn, height, count uint64) ([]types.IHeader, error)
 }
 
 type IRegister interface {
-	RegisterReceiveBlock(func(types.IBlock) error)
-	RegisterReceiveMessage(func(types.IMessage) error)
+	RegisterReceiveBlock(func(types.IBlock, string) error)
+	RegisterReceiveMessage(func(types.IMessage, string) error)
+	RegisterPeerPenalty(func(id string, score int))
+	RegisterInboundPeer(func(addr *peer.AddrInfo, handshake *types.Handshake) error)
+	RegisterReceiveInventory(func(invs []*types.Inventory, peerId string))
+	RegisterGetMessage(func(hash arry.Hash) (types.IMessage, bool))
+	RegisterReceiveCompactBlock(func(compact types.ICompactBlock, peerId string))
 }
 
 type IResponse interface {
@@ -0,0 +1,405 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	chaintypes "github.com/aiot-network/aiotchain/chain/types"
+	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/service/request"
+	log "github.com/aiot-network/aiotchain/tools/log/log15"
+	"github.com/aiot-network/aiotchain/tools/utils"
+	"github.com/aiot-network/aiotchain/types"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Number of headers requested at a time
+	headerBatch = 1000
+	// Number of blocks downloaded from a peer at a time
+	windowSize = 100
+	// Maximum number of peers downloading blocks at the same time
+	maxDownloaders = 4
+	// Number of times a window is retried on other peers
+	maxWindowTries = 3
+	// Seconds the heights of the peers are reused
+	heightsInterval = 10
+)
+
+var Err_InvalidHeader = errors.New("invalid header")
+
+// A range of blocks downloaded from one peer
+type window struct {
+	headers []types.IHeader
+	blocks  []types.IBlock
+	peer    *types.Peer
+	tries   int
+	err     error
+}
+
+func (w *window) start() uint64 {
+	return w.headers[0].GetHeight()
+}
+
+// Check that the blocks are the blocks of the validated headers, the
+// peer may return fewer blocks than requested
+func (w *window) check(blocks []types.IBlock) error {
+	if len(blocks) == 0 {
+		return errors.New("no blocks returned")
+	}
+	if len(blocks) > len(w.headers) {
+		return fmt.Errorf("%d blocks returned, %d requested", len(blocks), len(w.headers))
+	}
+	for i, block := range blocks {
+		if !block.GetHash().IsEqual(w.headers[i].GetHash()) {
+			return fmt.Errorf("%w: block %d does not match the header", Err_InvalidBlock, block.GetHeight())
+		}
+	}
+	w.blocks = blocks
+	return nil
+}
+
+// Synchronization progress
+type progress struct {
+	target uint64
+	start  time.Time
+	count  uint64
+}
+
+// Synchronize with headers first. The header chain is downloaded from the
+// highest peer and validated, then the blocks are downloaded in windows from
+// several peers and inserted in order.
+func (s *Sync) syncHeadersFirst() error {
+	peers, target := s.syncPeers()
+	if len(peers) == 0 {
+		return errors.New("no peer to synchronize")
+	}
+	s.setTarget(target)
+	best := peers[0]
+	if !supports(best, types.CapHeaders) {
+		s.setCurPeer(best)
+		return s.syncFromConn()
+	}
+	for {
+		select {
+		case _, _ = <-s.stop:
+			return nil
+		default:
+		}
+		localHeight := s.chain.LastHeight()
+		if localHeight >= target {
+			return nil
+		}
+		parent, err := s.chain.GetHeaderHeight(localHeight)
+		if err != nil {
+			return err
+		}
+		headers, err := s.request.GetHeaders(best.Conn, localHeight+1, headerBatch)
+		if err != nil {
+			if err == request.Err_PeerClosed {
+				s.reducePeerSpeed(best)
+			}
+			return err
+		}
+		if len(headers) == 0 {
+			return nil
+		}
+		// The peer is on another fork, the blocks are inserted one by
+		// one so that the local chain can be rolled back
+		if !headers[0].GetPreHash().IsEqual(parent.GetHash()) {
+			s.setCurPeer(best)
+			return s.syncFromConn()
+		}
+		if err := s.checkHeaders(parent, headers); err != nil {
+			log.Warn("Invalid header chain", "module", module, "peer", best.Address.ID.String(), "error", err)
+			s.peers.Penalize(best.Address.ID.String(), request.PenaltyInvalidBlock)
+			return err
+		}
+		if err := s.download(headers, s.downloaders(peers, headers[len(headers)-1].GetHeight())); err != nil {
+			return err
+		}
+	}
+}
+
+// Validate the header chain with the DPoS rules. The signers are checked
+// against the supers of the cycles that have been elected.
+func (s *Sync) checkHeaders(parent types.IHeader, headers []types.IHeader) error {
+	supers := make(map[uint64]map[string]bool)
+	for _, header := range headers {
+		if header.GetHeight() != parent.GetHeight()+1 || !header.GetPreHash().IsEqual(parent.GetHash()) {
+			return fmt.Errorf("%w: header %d is not linked to the previous header", Err_InvalidHeader, header.GetHeight())
+		}
+		if err := s.consensus.CheckHeader(header, parent, s.chain); err != nil {
+			return fmt.Errorf("%w: header %d, %s", Err_InvalidHeader, header.GetHeight(), err.Error())
+		}
+		signature := header.GetSignature()
+		if !chaintypes.Verify(header.GetHash(), signature) ||
+			!chaintypes.VerifySigner(config.Param.Name, header.GetSigner(), signature.PubicKey()) {
+			return fmt.Errorf("%w: header %d has a wrong signature", Err_InvalidHeader, header.GetHeight())
+		}
+		signers, ok := supers[header.GetCycle()]
+		if !ok {
+			signers = s.cycleSigners(header.GetCycle())
+			supers[header.GetCycle()] = signers
+		}
+		if signers != nil && !signers[header.GetSigner().String()] {
+			return fmt.Errorf("%w: header %d is not signed by a super", Err_InvalidHeader, header.GetHeight())
+		}
+		parent = header
+	}
+	return nil
+}
+
+// The signers of the supers of the cycle, nil if the cycle is not elected yet
+func (s *Sync) cycleSigners(cycle uint64) map[string]bool {
+	supers, err := s.dPos.CycleSupers(cycle)
+	if err != nil {
+		return nil
+	}
+	signers := make(map[string]bool)
+	for _, super := range supers.List() {
+		signers[super.GetSinger().String()] = true
+	}
+	return signers
+}
+
+// Download the blocks of the headers from the peers and insert them in order.
+// The windows that fail are retried on the other peers.
+func (s *Sync) download(headers []types.IHeader, peers []*types.Peer) error {
+	if len(peers) == 0 {
+		return errors.New("no peer to download the blocks")
+	}
+	// There are at most as many windows as blocks, so requeuing never blocks
+	pending := make(chan *window, len(headers))
+	results := make(chan *window, len(peers))
+	done := make(chan struct{})
+	defer close(done)
+
+	for start := 0; start < len(headers); start += windowSize {
+		end := start + windowSize
+		if end > len(headers) {
+			end = len(headers)
+		}
+		pending <- &window{headers: headers[start:end]}
+	}
+	for _, peer := range peers {
+		go s.fetchWindows(peer, pending, results, done)
+	}
+
+	workers := len(peers)
+	next := headers[0].GetHeight()
+	last := headers[len(headers)-1].GetHeight()
+	ready := make(map[uint64]*window)
+	for next <= last {
+		var w *window
+		select {
+		case _, _ = <-s.stop:
+			return nil
+		case w = <-results:
+		}
+		if w.err != nil {
+			workers--
+			log.Warn("Failed to download blocks", "module", module, "start", w.start(),
+				"count", len(w.headers), "peer", w.peer.Address.ID.String(), "error", w.err)
+			if errors.Is(w.err, Err_InvalidBlock) {
+				s.peers.Penalize(w.peer.Address.ID.String(), request.PenaltyInvalidBlock)
+			} else if w.err == request.Err_PeerClosed {
+				s.reducePeerSpeed(w.peer)
+			}
+			w.tries++
+			if w.tries >= maxWindowTries {
+				return fmt.Errorf("failed to download blocks from %d, %s", w.start(), w.err.Error())
+			}
+			if workers == 0 {
+				return errors.New("no peer left to download the blocks")
+			}
+			w.err = nil
+			pending <- w
+			continue
+		}
+		if len(w.blocks) < len(w.headers) {
+			pending <- &window{headers: w.headers[len(w.blocks):]}
+			w.headers = w.headers[:len(w.blocks)]
+		}
+		ready[w.start()] = w
+		for {
+			w, ok := ready[next]
+			if !ok {
+				break
+			}
+			delete(ready, next)
+			if err := s.insertWindow(w); err != nil {
+				return err
+			}
+			// The local chain is rolled back or changed by the received blocks
+			if s.chain.LastHeight() != w.headers[len(w.headers)-1].GetHeight() {
+				return nil
+			}
+			next += uint64(len(w.blocks))
+		}
+	}
+	return nil
+}
+
+// Download the windows from the peer until it fails
+func (s *Sync) fetchWindows(peer *types.Peer, pending chan *window, results chan<- *window, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case w := <-pending:
+			blocks, err := s.request.GetBlocks(peer.Conn, w.start(), uint64(len(w.headers)))
+			if err == nil {
+				err = w.check(blocks)
+			}
+			w.peer = peer
+			w.err = err
+			select {
+			case results <- w:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Insert the blocks of the window, blocks inserted by relay in the meantime are skipped
+func (s *Sync) insertWindow(w *window) error {
+	localHeight := s.chain.LastHeight()
+	blocks := w.blocks
+	for len(blocks) > 0 && blocks[0].GetHeight() <= localHeight {
+		blocks = blocks[1:]
+	}
+	if err := s.insert(blocks, w.peer); err != nil {
+		return err
+	}
+	s.addProgress(uint64(len(blocks)))
+	return nil
+}
+
+// The peers sorted by height from high to low and the highest height
+func (s *Sync) syncPeers() ([]*types.Peer, uint64) {
+	heights := s.peerHeights()
+	peers := make([]*types.Peer, 0, len(heights))
+	for id, peer := range s.peers.PeersMap() {
+		if _, ok := heights[id]; ok {
+			peers = append(peers, peer)
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return heights[peers[i].Address.ID.String()] > heights[peers[j].Address.ID.String()]
+	})
+	if len(peers) == 0 {
+		return peers, 0
+	}
+	target := heights[peers[0].Address.ID.String()]
+	// The peer of the majority fork is synchronized from first
+	if forkPeer := s.takeForkPeer(); forkPeer != "" {
+		for i, peer := range peers {
+			if peer.Address.ID.String() == forkPeer {
+				copy(peers[1:i+1], peers[:i])
+				peers[0] = peer
+				break
+			}
+		}
+	}
+	return peers, target
+}
+
+// Query the heights of the peers, the heights are reused for a while
+func (s *Sync) peerHeights() map[string]uint64 {
+	s.mutex.RLock()
+	heights, updated := s.heights, s.heightsTime
+	s.mutex.RUnlock()
+	if heights != nil && utils.NowUnix()-updated < heightsInterval {
+		return heights
+	}
+
+	local := s.peers.Local().Address.ID.String()
+	heights = make(map[string]uint64)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	for id, peer := range s.peers.PeersMap() {
+		if id == local {
+			continue
+		}
+		wg.Add(1)
+		go func(id string, peer *types.Peer) {
+			defer wg.Done()
+			height, err := s.request.LastHeight(peer.Conn)
+			if err == nil {
+				mutex.Lock()
+				heights[id] = height
+				mutex.Unlock()
+			}
+		}(id, peer)
+	}
+	wg.Wait()
+
+	s.mutex.Lock()
+	s.heights, s.heightsTime = heights, utils.NowUnix()
+	s.mutex.Unlock()
+	return heights
+}
+
+// The peers that download the blocks, they must support headers and reach the height
+func (s *Sync) downloaders(peers []*types.Peer, height uint64) []*types.Peer {
+	heights := s.peerHeights()
+	rs := make([]*types.Peer, 0, maxDownloaders)
+	for _, peer := range peers {
+		if len(rs) == maxDownloaders {
+			break
+		}
+		if supports(peer, types.CapHeaders) && heights[peer.Address.ID.String()] >= height {
+			rs = append(rs, peer)
+		}
+	}
+	return rs
+}
+
+func supports(peer *types.Peer, capability uint64) bool {
+	return peer.Handshake != nil && peer.Handshake.Supports(capability)
+}
+
+func (s *Sync) setTarget(target uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.progress.target < target && s.chain.LastHeight() < target && s.progress.start.IsZero() {
+		s.progress.start = time.Now()
+		s.progress.count = 0
+	}
+	s.progress.target = target
+}
+
+func (s *Sync) addProgress(count uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.progress.count += count
+	if s.chain.LastHeight() >= s.progress.target {
+		s.progress.start = time.Time{}
+	}
+}
+
+// The target height, blocks per second and estimated seconds to the target
+func (s *Sync) Progress() (uint64, uint64, uint64) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	height := s.chain.LastHeight()
+	if s.progress.start.IsZero() || height >= s.progress.target {
+		return s.progress.target, 0, 0
+	}
+	elapsed := uint64(time.Since(s.progress.start).Seconds())
+	if elapsed == 0 || s.progress.count == 0 {
+		return s.progress.target, 0, 0
+	}
+	speed := s.progress.count / elapsed
+	eta := (s.progress.target - height) * elapsed / s.progress.count
+	return s.progress.target, speed, eta
+}
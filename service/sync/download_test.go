@@ -0,0 +1,240 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aiot-network/aiotchain/common/blockchain"
+	"github.com/aiot-network/aiotchain/service/peers"
+	"github.com/aiot-network/aiotchain/service/request"
+	"github.com/aiot-network/aiotchain/tools/arry"
+	"github.com/aiot-network/aiotchain/types"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"sync"
+	"testing"
+)
+
+type testHeader struct {
+	types.IHeader
+	height uint64
+}
+
+func (h *testHeader) GetHeight() uint64  { return h.height }
+func (h *testHeader) GetHash() arry.Hash { return testHash(h.height) }
+
+type testBlock struct {
+	types.IBlock
+	height uint64
+	hash   arry.Hash
+}
+
+func (b *testBlock) GetHeight() uint64  { return b.height }
+func (b *testBlock) GetHash() arry.Hash { return b.hash }
+
+func testHash(height uint64) arry.Hash {
+	return arry.BytesToHash([]byte(fmt.Sprintf("block%d", height)))
+}
+
+func testHeaders(start, count uint64) []types.IHeader {
+	headers := make([]types.IHeader, count)
+	for i := range headers {
+		headers[i] = &testHeader{height: start + uint64(i)}
+	}
+	return headers
+}
+
+// A chain that inserts the blocks in height order
+type testChain struct {
+	blockchain.IChain
+	mutex  sync.Mutex
+	height uint64
+}
+
+func (c *testChain) LastHeight() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.height
+}
+
+func (c *testChain) Insert(block types.IBlock) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if block.GetHeight() != c.height+1 {
+		return fmt.Errorf("block %d is not the next block of %d", block.GetHeight(), c.height)
+	}
+	c.height++
+	return nil
+}
+
+type testBanDB struct{}
+
+func (db *testBanDB) Read() []*types.Ban   { return nil }
+func (db *testBanDB) Save(ban *types.Ban)  {}
+func (db *testBanDB) Delete(peerId string) {}
+func (db *testBanDB) Close() error         { return nil }
+
+// A getBlocks request of a peer
+type blocksCall struct {
+	peer   peer.ID
+	height uint64
+	count  uint64
+}
+
+// A peer requester that answers getBlocks with the blocks of the test
+// headers, the answer of a request can be replaced by the answer function
+type testRequester struct {
+	request.IRequestHandler
+	mutex  sync.Mutex
+	calls  []blocksCall
+	answer func(call blocksCall, index int) ([]types.IBlock, error)
+}
+
+func (r *testRequester) GetBlocks(conn *types.Conn, height, count uint64) ([]types.IBlock, error) {
+	call := blocksCall{peer: conn.PeerId, height: height, count: count}
+	r.mutex.Lock()
+	index := len(r.calls)
+	r.calls = append(r.calls, call)
+	r.mutex.Unlock()
+
+	if r.answer != nil {
+		return r.answer(call, index)
+	}
+	return testBlocks(height, count), nil
+}
+
+func (r *testRequester) blocksCalls() []blocksCall {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]blocksCall{}, r.calls...)
+}
+
+func testBlocks(height, count uint64) []types.IBlock {
+	blocks := make([]types.IBlock, count)
+	for i := range blocks {
+		blocks[i] = &testBlock{height: height + uint64(i), hash: testHash(height + uint64(i))}
+	}
+	return blocks
+}
+
+func newTestSync(requester *testRequester, count int) (*Sync, []*types.Peer) {
+	s := NewSync(peers.NewPeers(requester, &testBanDB{}), nil, nil, requester, &testChain{})
+	list := make([]*types.Peer, count)
+	for i := range list {
+		id := peer.ID(fmt.Sprintf("peer%d", i))
+		list[i] = &types.Peer{
+			Address:   &peer.AddrInfo{ID: id},
+			Conn:      &types.Conn{PeerId: id},
+			Handshake: &types.Handshake{Capabilities: types.CapHeaders},
+		}
+	}
+	return s, list
+}
+
+func TestSync_DownloadWindows(t *testing.T) {
+	// Every peer waits until all windows are requested, so each window
+	// must be assigned to a different peer
+	var wg sync.WaitGroup
+	wg.Add(3)
+	requester := &testRequester{}
+	requester.answer = func(call blocksCall, index int) ([]types.IBlock, error) {
+		wg.Done()
+		wg.Wait()
+		return testBlocks(call.height, call.count), nil
+	}
+	s, list := newTestSync(requester, 3)
+
+	if err := s.download(testHeaders(1, 2*windowSize+50), list); err != nil {
+		t.Fatal(err)
+	}
+	if height := s.chain.LastHeight(); height != 2*windowSize+50 {
+		t.Fatalf("the chain should reach %d, got %d", 2*windowSize+50, height)
+	}
+	calls := requester.blocksCalls()
+	if len(calls) != 3 {
+		t.Fatalf("3 windows should be requested, got %d", len(calls))
+	}
+	starts := make(map[uint64]uint64)
+	ids := make(map[peer.ID]bool)
+	for _, call := range calls {
+		starts[call.height] = call.count
+		ids[call.peer] = true
+	}
+	if starts[1] != windowSize || starts[windowSize+1] != windowSize || starts[2*windowSize+1] != 50 {
+		t.Fatalf("wrong windows %v", starts)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("the windows should be requested from 3 peers, got %d", len(ids))
+	}
+}
+
+func TestSync_DownloadTimeout(t *testing.T) {
+	requester := &testRequester{}
+	requester.answer = func(call blocksCall, index int) ([]types.IBlock, error) {
+		if index == 0 {
+			return nil, context.DeadlineExceeded
+		}
+		return testBlocks(call.height, call.count), nil
+	}
+	s, list := newTestSync(requester, 2)
+
+	if err := s.download(testHeaders(1, windowSize), list); err != nil {
+		t.Fatal(err)
+	}
+	if height := s.chain.LastHeight(); height != windowSize {
+		t.Fatalf("the chain should reach %d, got %d", windowSize, height)
+	}
+	calls := requester.blocksCalls()
+	if len(calls) != 2 {
+		t.Fatalf("the window should be requested twice, got %d", len(calls))
+	}
+	if calls[0].peer == calls[1].peer || calls[0].height != calls[1].height {
+		t.Fatalf("the window should be requested again from another peer, got %v", calls)
+	}
+}
+
+func TestSync_DownloadMismatch(t *testing.T) {
+	requester := &testRequester{}
+	requester.answer = func(call blocksCall, index int) ([]types.IBlock, error) {
+		blocks := testBlocks(call.height, call.count)
+		if index == 0 {
+			blocks[1] = &testBlock{height: call.height + 1, hash: arry.BytesToHash([]byte("other"))}
+		}
+		return blocks, nil
+	}
+	s, list := newTestSync(requester, 2)
+
+	if err := s.download(testHeaders(1, windowSize), list); err != nil {
+		t.Fatal(err)
+	}
+	if height := s.chain.LastHeight(); height != windowSize {
+		t.Fatalf("the chain should reach %d, got %d", windowSize, height)
+	}
+	calls := requester.blocksCalls()
+	if len(calls) != 2 || calls[0].peer == calls[1].peer {
+		t.Fatalf("the window should be requested again from another peer, got %v", calls)
+	}
+	if score := s.peers.Score(calls[0].peer.String()); score != -request.PenaltyInvalidBlock {
+		t.Fatalf("the peer returning a wrong block should be penalized, got score %d", score)
+	}
+	if score := s.peers.Score(calls[1].peer.String()); score != 0 {
+		t.Fatalf("the other peer should not be penalized, got score %d", score)
+	}
+}
+
+func TestSync_DownloadTries(t *testing.T) {
+	requester := &testRequester{}
+	requester.answer = func(call blocksCall, index int) ([]types.IBlock, error) {
+		return nil, errors.New("no blocks")
+	}
+	s, list := newTestSync(requester, maxWindowTries+1)
+
+	if err := s.download(testHeaders(1, windowSize), list); err == nil {
+		t.Fatal("the download should fail after the tries of the window")
+	}
+	if calls := requester.blocksCalls(); len(calls) != maxWindowTries {
+		t.Fatalf("the window should be requested %d times, got %d", maxWindowTries, len(calls))
+	}
+}
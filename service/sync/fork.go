@@ -0,0 +1,88 @@
+package sync
+
+import (
+	"errors"
+	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/common/param"
+	log "github.com/aiot-network/aiotchain/tools/log/log15"
+	"github.com/aiot-network/aiotchain/tools/utils"
+	"github.com/aiot-network/aiotchain/types"
+	"sync/atomic"
+)
+
+var Err_ForkBlock = errors.New("the block conflicts with the local chain")
+
+// Whether the block conflicts with the local chain above the confirmed height
+func (s *Sync) isConflicting(block types.IBlock, localHeight uint64) bool {
+	if block.GetHeight() <= s.chain.LastConfirmed() {
+		return false
+	}
+	if block.GetHeight() <= localHeight {
+		local, err := s.chain.GetHeaderHeight(block.GetHeight())
+		return err == nil && !local.GetHash().IsEqual(block.GetHash())
+	}
+	last, err := s.chain.GetHeaderHeight(localHeight)
+	return err == nil && !last.GetHash().IsEqual(block.GetPreHash())
+}
+
+// Check the conflicting header against the supers of the current cycle.
+// If more than the quorum of them have it, the local chain is on a
+// minority fork and rolls back to the confirmed height, then the peer
+// is synchronized from first. Only one check runs at a time.
+func (s *Sync) checkFork(header types.IHeader, peerId string) {
+	if !atomic.CompareAndSwapInt32(&s.forkChecking, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&s.forkChecking, 0)
+
+	atomic.AddUint64(&s.forks, 1)
+	var localHash string
+	if local, err := s.chain.GetHeaderHeight(header.GetHeight()); err == nil {
+		localHash = local.GetHash().String()
+	}
+	votes, supers := s.validation(header, currentCycle(), false)
+	log.Warn("Fork detected", "module", module, "height", header.GetHeight(), "local", localHash,
+		"remote", header.GetHash().String(), "peer", peerId, "votes", votes, "supers", supers,
+		"quorum", config.Param.ForkQuorum)
+	if !hasQuorum(votes, supers) {
+		return
+	}
+	// The header may have been confirmed during the check
+	if header.GetHeight() <= s.chain.LastConfirmed() {
+		return
+	}
+	if err := s.chain.Roll(); err != nil {
+		log.Error("Failed to roll back to the confirmed height", "module", module, "error", err)
+		return
+	}
+	atomic.AddUint64(&s.rollbacks, 1)
+	s.setForkPeer(peerId)
+	log.Warn("Roll back to switch to the majority fork", "module", module,
+		"height", s.chain.LastHeight(), "peer", peerId)
+}
+
+// Whether the votes are more than the quorum percentage of the supers
+func hasQuorum(votes, supers int) bool {
+	return supers > 0 && votes*100 > supers*int(config.Param.ForkQuorum)
+}
+
+func currentCycle() uint64 {
+	return uint64(utils.NowUnix()) / param.CycleInterval
+}
+
+func (s *Sync) setForkPeer(peerId string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.forkPeer = peerId
+}
+
+// The peer of the majority fork that is synchronized from first
+func (s *Sync) takeForkPeer() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	peerId := s.forkPeer
+	s.forkPeer = ""
+	return peerId
+}
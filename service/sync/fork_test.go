@@ -0,0 +1,112 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"github.com/aiot-network/aiotchain/common/config"
+	"github.com/aiot-network/aiotchain/common/dpos"
+	"github.com/aiot-network/aiotchain/common/param"
+	"github.com/aiot-network/aiotchain/service/peers"
+	"github.com/aiot-network/aiotchain/service/request"
+	"github.com/aiot-network/aiotchain/types"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"testing"
+)
+
+type testCandidate struct {
+	types.ICandidate
+	peerId string
+}
+
+func (c *testCandidate) GetPeerId() string { return c.peerId }
+
+type testCandidates struct {
+	types.ICandidates
+	list []types.ICandidate
+}
+
+func (c *testCandidates) Len() int                 { return len(c.list) }
+func (c *testCandidates) List() []types.ICandidate { return c.list }
+
+// The supers of every cycle are the peers of the ids
+type testDPos struct {
+	dpos.IDPosStatus
+	supers []string
+}
+
+func (d *testDPos) CycleSupers(cycle uint64) (types.ICandidates, error) {
+	candidates := &testCandidates{}
+	for _, id := range d.supers {
+		candidates.list = append(candidates.list, &testCandidate{peerId: id})
+	}
+	return candidates, nil
+}
+
+// A chain without confirmed blocks that counts the rollbacks
+type forkChain struct {
+	testChain
+	rolls int
+}
+
+func (c *forkChain) GetHeaderHeight(height uint64) (types.IHeader, error) {
+	return nil, errors.New("not found")
+}
+
+func (c *forkChain) LastConfirmed() uint64 { return 0 }
+
+func (c *forkChain) Roll() error {
+	c.rolls++
+	return nil
+}
+
+// A peer requester that answers whether the peer has the header
+type forkRequester struct {
+	request.IRequestHandler
+	equal map[peer.ID]bool
+}
+
+func (r *forkRequester) IsEqual(conn *types.Conn, header types.IHeader) (bool, error) {
+	return r.equal[conn.PeerId], nil
+}
+
+func TestSync_CheckFork(t *testing.T) {
+	config.Param = param.TestNetParam
+	peerIds := make([]peer.ID, 4)
+	ids := make([]string, len(peerIds))
+	for i := range peerIds {
+		peerIds[i] = peer.ID(fmt.Sprintf("peer%d", i))
+		ids[i] = peerIds[i].String()
+	}
+	tests := []struct {
+		name   string
+		supers []string
+		votes  int
+		roll   bool
+	}{
+		{"below the quorum", ids, 1, false},
+		{"at the quorum", ids, 2, false},
+		{"above the quorum", ids, 3, true},
+		{"duplicate votes of a super", []string{ids[0], ids[0], ids[0], ids[1]}, 1, false},
+	}
+	for _, test := range tests {
+		requester := &forkRequester{equal: make(map[peer.ID]bool)}
+		p := peers.NewPeers(requester, &testBanDB{})
+		p.SetLocal(&types.Peer{Address: &peer.AddrInfo{ID: peer.ID("local")}})
+		for i, id := range peerIds {
+			if err := p.AddPeer(&types.Peer{Address: &peer.AddrInfo{ID: id}, Conn: &types.Conn{PeerId: id}}); err != nil {
+				t.Fatal(err)
+			}
+			requester.equal[id] = i < test.votes
+		}
+		chain := &forkChain{}
+		s := NewSync(p, &testDPos{supers: test.supers}, nil, requester, chain)
+
+		s.checkFork(&testHeader{height: 5}, ids[0])
+		if rolled := chain.rolls == 1; rolled != test.roll {
+			t.Fatalf("%s: the chain should roll back %v", test.name, test.roll)
+		}
+		if forkPeer := s.takeForkPeer(); (forkPeer == ids[0]) != test.roll {
+			t.Fatalf("%s: the fork peer should be synchronized first %v, got %s", test.name, test.roll, forkPeer)
+		}
+	}
+}
@@ -4,40 +4,53 @@ import (
 	"errors"
 	"github.com/aiot-network/aiotchain/common/blockchain"
 	"github.com/aiot-network/aiotchain/common/dpos"
-	"github.com/aiot-network/aiotchain/common/param"
 	"github.com/aiot-network/aiotchain/service/peers"
 	"github.com/aiot-network/aiotchain/service/request"
 	log "github.com/aiot-network/aiotchain/tools/log/log15"
 	"github.com/aiot-network/aiotchain/types"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const module = "sync"
 
 var (
-	Err_RepeatBlock = errors.New("repeat the block")
+	Err_RepeatBlock  = errors.New("repeat the block")
+	Err_InvalidBlock = errors.New("invalid block")
+	Err_NotNextBlock = errors.New("not the next block")
 )
 
 type Sync struct {
-	chain   blockchain.IChain
-	request request.IRequestHandler
-	peers   *peers.Peers
-	curPeer *types.Peer
-	dPos    dpos.IDPosStatus
-	stop    chan bool
-	stopped chan bool
-	mutex sync.RWMutex
+	chain     blockchain.IChain
+	request   request.IRequestHandler
+	peers     *peers.Peers
+	curPeer   *types.Peer
+	dPos      dpos.IDPosStatus
+	consensus dpos.IDPos
+	stop      chan bool
+	stopped   chan bool
+	mutex     sync.RWMutex
+	// Heights of the peers and the time they are queried
+	heights     map[string]uint64
+	heightsTime int64
+	progress    progress
+	// Fork check state and metrics
+	forkChecking int32
+	forkPeer     string
+	forks        uint64
+	rollbacks    uint64
 }
 
-func NewSync(peers *peers.Peers, dPos dpos.IDPosStatus, request request.IRequestHandler, chain blockchain.IChain) *Sync {
+func NewSync(peers *peers.Peers, dPos dpos.IDPosStatus, consensus dpos.IDPos, request request.IRequestHandler, chain blockchain.IChain) *Sync {
 	s := &Sync{
-		chain:   chain,
-		peers:   peers,
-		dPos:    dPos,
-		request: request,
-		stop:    make(chan bool),
-		stopped: make(chan bool),
+		chain:     chain,
+		peers:     peers,
+		dPos:      dPos,
+		consensus: consensus,
+		request:   request,
+		stop:      make(chan bool),
+		stopped:   make(chan bool),
 	}
 	return s
 }
@@ -60,9 +73,15 @@ func (s *Sync) Stop() error {
 }
 
 func (s *Sync) Info() map[string]interface{} {
+	target, speed, eta := s.Progress()
 	return map[string]interface{}{
 		"height":    s.chain.LastHeight(),
 		"confirmed": s.chain.LastConfirmed(),
+		"target":    target,
+		"syncspeed": speed,
+		"eta":       eta,
+		"forks":     atomic.LoadUint64(&s.forks),
+		"rollbacks": atomic.LoadUint64(&s.rollbacks),
 	}
 }
 
@@ -88,47 +107,12 @@ func (s *Sync) syncBlocks() {
 			s.stopped <- true
 			return
 		default:
-			s.createSyncStream()
-			s.syncFromConn()
-
+			s.syncHeadersFirst()
 		}
 		time.Sleep(time.Millisecond * 1000)
 	}
 }
 
-// Create a network channel of the synchronization block, and randomly
-// select a new peer node for synchronization every 1s.
-func (s *Sync) createSyncStream() {
-	for {
-		select {
-		case _, _ = <-s.stop:
-			return
-		default:
-			s.findSyncPeer()
-			return
-		}
-	}
-}
-
-// Replace the new peer node
-func (s *Sync) findSyncPeer() {
-	t := time.NewTicker(time.Microsecond)
-	defer t.Stop()
-
-	for {
-		select {
-		case _, _ = <-s.stop:
-			return
-		case _ = <-t.C:
-			peer := s.peers.RandomPeer()
-			if peer != nil{
-				s.setCurPeer(peer)
-				return
-			}
-		}
-	}
-}
-
 // Synchronize blocks from the stream and verify storage
 func (s *Sync) syncFromConn() error {
 	for {
@@ -183,6 +167,10 @@ func (s *Sync) insert(blocks []types.IBlock, peer *types.Peer) error {
 					"error", err, "height",
 					block.GetHeight(),
 					"signer", block.GetSigner())
+				if errors.Is(err, Err_InvalidBlock) {
+					s.peers.Penalize(peer.Address.ID.String(), request.PenaltyInvalidBlock)
+					return err
+				}
 				if s.NeedValidation(err) {
 					if roll, peerId := s.isRollBack(block.BlockHeader());roll {
 						log.Info("Start roll back")
@@ -221,29 +209,43 @@ func (s *Sync) isRollBack(header types.IHeader) (bool, string) {
 
 }
 
-func (s *Sync) validation(header types.IHeader, localEqual bool) bool {
-	count := 0
-	supers, err := s.dPos.CycleSupers(header.GetCycle())
+// Count the supers of the cycle that have the header, the local node
+// is counted if localEqual. The supers are asked at the same time.
+func (s *Sync) validation(header types.IHeader, cycle uint64, localEqual bool) (int, int) {
+	supers, err := s.dPos.CycleSupers(cycle)
 	if err != nil {
-		return false
+		return 0, 0
 	}
+	var count int32
+	var wg sync.WaitGroup
+	local := s.peers.Local().Address.ID.String()
+	// A peer votes once however many of the supers it is
+	voted := make(map[string]bool)
 	for _, candidate := range supers.List() {
-		if candidate.GetPeerId() != s.peers.Local().Address.ID.String() {
-			peer := s.peers.Peer(candidate.GetPeerId())
-			if peer != nil {
-				rs, err := s.request.IsEqual(peer.Conn, header)
-				if err == nil && rs {
-					count++
-				}
+		if voted[candidate.GetPeerId()] {
+			continue
+		}
+		voted[candidate.GetPeerId()] = true
+		if candidate.GetPeerId() == local {
+			if localEqual {
+				atomic.AddInt32(&count, 1)
 			}
-		} else if localEqual {
-			count++
+			continue
 		}
+		peer := s.peers.Peer(candidate.GetPeerId())
+		if peer == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(conn *types.Conn) {
+			defer wg.Done()
+			if rs, err := s.request.IsEqual(conn, header); err == nil && rs {
+				atomic.AddInt32(&count, 1)
+			}
+		}(peer.Conn)
 	}
-	if count > param.SuperSize/2 {
-		return true
-	}
-	return false
+	wg.Wait()
+	return int(count), supers.Len()
 }
 
 func (s *Sync) isRoll(header types.IHeader, localHeight uint64) (bool, string) {
@@ -275,7 +277,14 @@ func (s *Sync) isRoll(header types.IHeader, localHeight uint64) (bool, string) {
 		if peer != nil{
 			ok, err := s.request.IsEqual(peer.Conn, header)
 			if ok {
-				return true, maxHeightPeer
+				// The highest super alone may be on a minority fork
+				votes, supers := s.validation(header, currentCycle(), false)
+				if hasQuorum(votes, supers) {
+					return true, maxHeightPeer
+				}
+				log.Warn("No quorum to roll back", "module", module, "height", header.GetHeight(),
+					"hash", header.GetHash().String(), "votes", votes, "supers", supers)
+				return false, ""
 			} else if err != nil {
 				log.Error("Failed to validation block hash!", "hash", header.GetHash(), "err", err.Error(), "remote peer", maxHeightPeer)
 				return false, ""
@@ -292,38 +301,30 @@ func (s *Sync) fallBack() {
 	s.chain.Roll()
 }
 
-// Process blocks received from other super nodes.If the height
-// of the block is greater than the local height, the storage is
-// directly verified. If the height is less than the local height,
-// the remote verification is performed, and the verification is
-// passed back to the local block.
-func (s *Sync) ReceivedBlockFromPeer(block types.IBlock) error {
+// Process blocks received from other super nodes. The next block
+// is verified and stored directly. A block that conflicts with the
+// local chain triggers a fork check against the current supers.
+func (s *Sync) ReceivedBlockFromPeer(block types.IBlock, peerId string) error {
 	localHeight := s.chain.LastHeight()
-	if localHeight == block.GetHeight()-1 {
-		if err := s.chain.Insert(block); err != nil {
-			log.Warn("Failed to insert received block", "err", err, "height", block.GetHeight(),  "localHeight", localHeight, "singer", block.GetSigner().String())
-			return err
-		}
-		log.Info("Received block insert success", "module", module, "height", block.GetHeight(), "signer", block.GetSigner())
-	} /*else if block.GetHeight() <= localHeight {
-		if localHeader, err := s.chain.GetBlockHeight(block.GetHeight()); err == nil {
-			if !localHeader.GetHash().IsEqual(block.GetHash()) {
-				if roll, peerId := s.isRollBack(block.BlockHeader());roll {
-					log.Info("Start roll back")
-					s.fallBack()
-					if peerInfo := s.peers.Peer(peerId); peerInfo != nil {
-						s.setCurPeer(peerInfo)
-						return nil
-					}
-				}
-			}
-		} else {
-			if err := s.chain.Insert(block); err != nil {
-				log.Warn("Failed to insert received block", "err", err, "height", block.GetHeight(), "singer", block.GetSigner().String())
-				return err
-			}
+	if block.GetHeight() > localHeight+1 {
+		// Blocks that are not inserted are not relayed
+		return Err_NotNextBlock
+	}
+	if s.isConflicting(block, localHeight) {
+		go s.checkFork(block.BlockHeader(), peerId)
+		return Err_ForkBlock
+	}
+	if block.GetHeight() <= localHeight {
+		return Err_RepeatBlock
+	}
+	if err := s.chain.Insert(block); err != nil {
+		log.Warn("Failed to insert received block", "err", err, "height", block.GetHeight(), "localHeight", localHeight, "singer", block.GetSigner().String())
+		if errors.Is(err, Err_InvalidBlock) {
+			s.peers.Penalize(peerId, request.PenaltyInvalidBlock)
 		}
-	}*/
+		return err
+	}
+	log.Info("Received block insert success", "module", module, "height", block.GetHeight(), "signer", block.GetSigner())
 	return nil
 }
 
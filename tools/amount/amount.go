@@ -90,3 +90,24 @@ func (a Amount) ToUnit(u AmountUnit) float64 {
 func (a Amount) ToCoin() float64 {
 	return a.ToUnit(AmountCoin)
 }
+
+// NewAmountDecimals creates an Amount from a floating point value representing
+// some value of a token whose atomic unit is 1e-decimals of a coin.
+func NewAmountDecimals(f float64, decimals uint8) (uint64, error) {
+	switch {
+	case math.IsNaN(f):
+		fallthrough
+	case math.IsInf(f, 1):
+		fallthrough
+	case math.IsInf(f, -1):
+		return 0, errors.New("invalid coin amount")
+	}
+
+	return round(f * math.Pow10(int(decimals))), nil
+}
+
+// ToDecimals converts a monetary amount counted in atomic units to a floating
+// point value for a token whose atomic unit is 1e-decimals of a coin.
+func (a Amount) ToDecimals(decimals uint8) float64 {
+	return float64(a) / math.Pow10(int(decimals))
+}
@@ -5,3 +5,9 @@ import "fmt"
 func Error(info, module string) error {
 	return fmt.Errorf("%s; module=%s", info, module)
 }
+
+// Wrap the error with the information, the wrapped error can still be
+// found through errors.As
+func WrapError(err error, info, module string) error {
+	return fmt.Errorf("%s, %w; module=%s", info, err, module)
+}
@@ -28,5 +28,8 @@ type IActStatus interface {
 	FromMessage(msg IMessage, height uint64) error
 	WorkMessage(msg IMessage) error
 	ToMessage(msg IMessage, height uint64) error
+	TokenHolders(token arry.Address, offset, limit uint64) ([]IAccount, uint64)
+	RichList(token arry.Address, limit uint64) []IAccount
 	Commit() (arry.Hash, error)
+	Copy() (IActStatus, error)
 }
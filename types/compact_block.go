@@ -0,0 +1,9 @@
+package types
+
+// A block relayed with the short ids of its messages, the receiver rebuilds
+// it from the messages of its pool
+type ICompactBlock interface {
+	BlockHeader() IHeader
+	Fill(pool []IMessage) ([]IMessage, []uint32, error)
+	ToBlock(msgs []IMessage) IBlock
+}
@@ -0,0 +1,55 @@
+package types
+
+import "github.com/aiot-network/aiotchain/tools/arry"
+
+// Capability flags of the peer request protocol
+const (
+	// lastHeight, getBlocks, getBlock and isEqual
+	CapSync uint64 = 1 << iota
+	// sendBlock and sendMsg
+	CapRelay
+	// localInfo
+	CapLocalInfo
+	// Length-prefixed binary frames of requests and responses
+	CapFraming
+	// Concurrent requests multiplexed on a long-lived stream
+	CapSession
+	// announce, getMessage and getBlockHash
+	CapInventory
+	// sendCompact and getBlockMsgs
+	CapCompact
+	// getHeaders
+	CapHeaders
+)
+
+// The chain identity and capabilities exchanged before a peer is added
+type Handshake struct {
+	// Peer request protocol version
+	Protocol uint32 `json:"protocol"`
+	// Node version
+	Version string `json:"version"`
+	// Node network
+	Network string `json:"network"`
+	// Genesis block hash
+	Genesis arry.Hash `json:"genesis"`
+	// Current block height
+	Height uint64 `json:"height"`
+	// Current effective block height
+	Confirmed uint64 `json:"confirmed"`
+	// Supported request methods
+	Capabilities uint64 `json:"capabilities"`
+}
+
+// The handshake of a peer that predates the handshake request, it has
+// no capabilities and is spoken to with legacy frames
+func LegacyHandshake() *Handshake {
+	return &Handshake{}
+}
+
+func (h *Handshake) IsLegacy() bool {
+	return h.Protocol == 0
+}
+
+func (h *Handshake) Supports(capability uint64) bool {
+	return h.Capabilities&capability == capability
+}
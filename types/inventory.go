@@ -0,0 +1,18 @@
+package types
+
+import "github.com/aiot-network/aiotchain/tools/arry"
+
+// Kinds of the announced inventories
+const (
+	InvMsg uint8 = iota
+	InvBlock
+)
+
+// Announcement of a message or block, the receiver requests the
+// content from the announcer if it has not seen the hash
+type Inventory struct {
+	Kind uint8     `json:"kind"`
+	Hash arry.Hash `json:"hash"`
+	// Block height, 0 for messages
+	Height uint64 `json:"height"`
+}
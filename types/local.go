@@ -17,4 +17,14 @@ type Local struct {
 	Height uint64 `json:"height"`
 	// Current effective block height
 	Confirmed uint64 `json:"confirmed"`
+	// Height of the highest peer while synchronizing
+	Target uint64 `json:"target" rlp:"optional"`
+	// Blocks synchronized per second
+	SyncSpeed uint64 `json:"syncspeed" rlp:"optional"`
+	// Estimated seconds to reach the target height
+	ETA uint64 `json:"eta" rlp:"optional"`
+	// Number of the detected forks
+	Forks uint64 `json:"forks" rlp:"optional"`
+	// Number of the rollbacks to a majority fork
+	Rollbacks uint64 `json:"rollbacks" rlp:"optional"`
 }
@@ -9,6 +9,7 @@ type IMessageHeader interface {
 	Nonce() uint64
 	Fee() uint64
 	Time() uint64
+	ValidUntil() uint64
 	IsCoinBase() bool
 	Signature() string
 	PublicKey() string
@@ -11,22 +11,45 @@ type Peer struct {
 	Address *peer.AddrInfo
 	Conn    *Conn
 	Speed   uint64
+	// The handshake result of the peer
+	Handshake *Handshake
+	// The peer connected to the local node
+	Inbound bool
+	// The peer is trusted and not limited or evicted
+	Static bool
 }
 
-func NewPeer(private crypto.PrivateKey, addr *peer.AddrInfo, createF CreateConnF, stream network.Stream) *Peer {
-	return &Peer{Private: private, Address: addr, Conn: &Conn{Stream: stream, PeerId: addr.ID, Create: createF}}
+func NewPeer(private crypto.PrivateKey, addr *peer.AddrInfo, createF CreateConnF) *Peer {
+	return &Peer{Private: private, Address: addr, Conn: &Conn{PeerId: addr.ID, Create: createF}}
 }
 
 type CreateConnF func(peerId peer.ID) (network.Stream, error)
 
 type Conn struct {
-	Stream network.Stream
 	PeerId peer.ID
 	Create CreateConnF
+	// Requests are sent in binary frames, negotiated by the handshake
+	Framed bool
+	// Requests are multiplexed on a long-lived session, negotiated by the handshake
+	Multiplexed bool
 }
 
-func (s *Conn) Close() {
-	s.Stream.Reset()
-	s.Stream.Close()
-	s.Stream = nil
+// Use the request formats supported by the peer
+func (c *Conn) Negotiate(handshake *Handshake) {
+	c.Framed = handshake.Supports(CapFraming)
+	c.Multiplexed = handshake.Supports(CapSession)
+}
+
+// A banned peer, the ban is permanent if Until is 0
+type Ban struct {
+	// Peer p2p id
+	PeerId string `json:"peer"`
+	// Reason of the ban
+	Reason string `json:"reason"`
+	// Unix time when the ban expires
+	Until uint64 `json:"until"`
+}
+
+func (b *Ban) IsExpired(now uint64) bool {
+	return b.Until != 0 && b.Until <= now
 }
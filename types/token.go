@@ -11,5 +11,7 @@ type ITokenStatus interface {
 	CheckMessage(msg IMessage) error
 	UpdateToken(msg IMessage, height uint64) error
 	Token(address arry.Address) (IToken, error)
+	Tokens(offset, limit uint64) ([]IToken, uint64)
 	Commit() (arry.Hash, error)
+	Copy() (ITokenStatus, error)
 }